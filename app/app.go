@@ -6,8 +6,12 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spaceavocado/apidoc/extract"
+	"github.com/spaceavocado/apidoc/loader"
 	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/html"
+	"github.com/spaceavocado/apidoc/output/markdown"
 	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/output/swagger"
 	"github.com/spaceavocado/apidoc/reference"
 	"github.com/spaceavocado/apidoc/token"
 )
@@ -20,10 +24,15 @@ const (
 // App main structure
 type App struct {
 	conf        *Configuration
+	loader      loader.Loader
 	extractor   extract.Extractor
 	tokenParser token.Parser
 	refResolver reference.Resolver
 	generator   output.Generator
+	// Name of the output documentation file, and the
+	// formats of the file, i.e. the generated files
+	file    string
+	formats []string
 }
 
 // Start the application
@@ -60,23 +69,51 @@ func (a *App) Start() {
 	tRes.Endpoints = a.ReduceEndpoints(tRes.Endpoints)
 
 	// Generate
-	output := filepath.Join(a.conf.Output, "openapi.yaml")
-	err = a.generator.Generate(tRes.Main, tRes.Endpoints, output)
+	file := filepath.Join(a.conf.Output, a.file)
+	err = a.generator.Generate(tRes.Main, tRes.Endpoints, file)
 	if err != nil {
 		log.WithError(err).Errorf("an error has occurred during the generation of the output")
 		return
 	}
 
-	log.Infof("%s has been generated!", output)
+	for _, format := range a.formats {
+		log.Infof("%s has been generated!", output.FormatFile(file, format))
+	}
 }
 
 // New application instance
 func New(c Configuration) App {
-	return App{
+	inference := extract.Inference{
+		Endpoints:   c.InferEndpoints,
+		Schemas:     c.InferSchemas,
+		DecodeFuncs: c.DecodeFuncs,
+		EncodeFuncs: c.EncodeFuncs,
+	}
+	if len(c.Formats) == 0 {
+		c.Formats = []string{output.YAML}
+	}
+	l := loader.NewLoader(c.Verbose, c.BuildTags, c.Workers)
+	a := App{
 		conf:        &c,
-		extractor:   extract.NewExtractor(c.Verbose),
+		loader:      l,
+		extractor:   extract.NewExtractor(c.Verbose, l, inference),
 		tokenParser: token.NewParser(c.Verbose),
-		refResolver: reference.NewResolver(c.Verbose),
-		generator:   openapi.NewGenerator(c.Verbose),
+		refResolver: reference.NewResolver(c.Verbose, l, c.KnownTypes, c.NullablePointers, c.Workers),
+		generator:   openapi.NewGenerator(c.Verbose, c.OpenAPIVersion, c.Formats...),
+		file:        "openapi.yaml",
+		formats:     c.Formats,
+	}
+	if c.Generator == HTMLGenerator {
+		a.generator = html.NewGenerator(c.Verbose, c.OpenAPIVersion)
+		a.file = "index.html"
+		a.formats = []string{"html"}
+	} else if c.Generator == MarkdownGenerator {
+		a.generator = markdown.NewGenerator(c.Verbose, c.OpenAPIVersion, c.MarkdownPerTag)
+		a.file = "index.md"
+		a.formats = []string{"md"}
+	} else if c.OpenAPIVersion == swagger.Version {
+		a.generator = swagger.NewGenerator(c.Verbose, c.Formats...)
+		a.file = "swagger.yaml"
 	}
+	return a
 }
@@ -11,6 +11,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spaceavocado/apidoc/extract"
+	"github.com/spaceavocado/apidoc/output/swagger"
 	"github.com/spaceavocado/apidoc/token"
 )
 
@@ -34,7 +35,8 @@ func (g *dataGenerator) Generate(main []token.Token, endpoints [][]token.Token,
 
 func TestStart(t *testing.T) {
 	content := []string{
-		`
+		`package tmp
+
 		// @title Refresh ID Token
 		// @ver 1.0
 		// @desc Use the refresh token
@@ -43,7 +45,8 @@ func TestStart(t *testing.T) {
 		`,
 		"func main(){}",
 		"func main(){}",
-		`
+		`package tmp
+
 		// @router
 
 		// @router
@@ -194,4 +197,81 @@ func TestStart(t *testing.T) {
 	if strings.Contains(o, "has been generated!") == false {
 		t.Errorf("Expected \"%s\" error, got \"%s\"", "has been generated!", o)
 	}
+
+	// Both formats
+	hook.Reset()
+	a = New(Configuration{
+		MainFile: "tmp1",
+		EndsRoot: "tmp/",
+		Output:   "tmp/output",
+		Formats:  []string{"yaml", "json"},
+	})
+	a.generator = &dataGenerator{}
+	a.Start()
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "openapi.json has been generated!") == false {
+		t.Errorf("Expected \"%s\" info, got \"%s\"", "openapi.json has been generated!", o)
+	}
+
+	// HTML
+	hook.Reset()
+	a = New(Configuration{
+		MainFile:  "tmp1",
+		EndsRoot:  "tmp/",
+		Output:    "tmp/output",
+		Formats:   []string{"yaml", "json"},
+		Generator: HTMLGenerator,
+	})
+	a.generator = &dataGenerator{}
+	a.Start()
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[0].String()
+	if strings.Contains(o, "index.html has been generated!") == false {
+		t.Errorf("Expected \"%s\" info, got \"%s\"", "index.html has been generated!", o)
+	}
+
+	// Markdown
+	hook.Reset()
+	a = New(Configuration{
+		MainFile:  "tmp1",
+		EndsRoot:  "tmp/",
+		Output:    "tmp/output",
+		Generator: MarkdownGenerator,
+	})
+	a.generator = &dataGenerator{}
+	a.Start()
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[0].String()
+	if strings.Contains(o, "index.md has been generated!") == false {
+		t.Errorf("Expected \"%s\" info, got \"%s\"", "index.md has been generated!", o)
+	}
+
+	// Swagger
+	hook.Reset()
+	a = New(Configuration{
+		MainFile:       "tmp1",
+		EndsRoot:       "tmp/",
+		Output:         "tmp/output",
+		OpenAPIVersion: swagger.Version,
+	})
+	a.generator = &dataGenerator{}
+	a.Start()
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[0].String()
+	if strings.Contains(o, "swagger.yaml has been generated!") == false {
+		t.Errorf("Expected \"%s\" info, got \"%s\"", "swagger.yaml has been generated!", o)
+	}
 }
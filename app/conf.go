@@ -1,5 +1,18 @@
 package app
 
+// Generators of the documentation
+const (
+	// OpenAPIGenerator of the OpenAPI, or the Swagger document
+	OpenAPIGenerator = "openapi"
+	// HTMLGenerator of the static HTML site
+	HTMLGenerator = "html"
+	// MarkdownGenerator of the Markdown files
+	MarkdownGenerator = "markdown"
+)
+
+// Generators supported by the app
+var Generators = []string{OpenAPIGenerator, HTMLGenerator, MarkdownGenerator}
+
 // Configuration of the app
 type Configuration struct {
 	// Main documentation file
@@ -8,6 +21,36 @@ type Configuration struct {
 	EndsRoot string
 	// Output documentation folder
 	Output string
+	// Formats of the output documentation, i.e. yaml, json,
+	// the yaml format is used if not set
+	Formats []string
+	// Generator of the documentation, i.e. openapi, html or
+	// markdown, the openapi generator is used if not set
+	Generator string
+	// One Markdown file per tag, otherwise one file for everything
+	MarkdownPerTag bool
+	// Version of the OpenAPI specification, i.e. 3.0.2 or 3.1.0,
+	// or 2.0 for the Swagger document, 3.0.2 is used if not set
+	OpenAPIVersion string
 	// Verbose mode, i.e. show warnings
 	Verbose bool
+	// Additional known types mapped to OpenAPI type and format,
+	// i.e. "pkg/path.Type" -> "type[:format]"
+	KnownTypes map[string]string
+	// Pointer fields are described as nullable
+	NullablePointers bool
+	// Endpoints are inferred from the routes without the annotation
+	InferEndpoints bool
+	// Request and response schemas are inferred from the handler functions
+	InferSchemas bool
+	// Names of the project decode helpers, e.g. request.ParseJSONBody
+	DecodeFuncs []string
+	// Names of the project encode helpers, optionally
+	// with the status code, e.g. response.Error=500
+	EncodeFuncs []string
+	// Additional build tags satisfied by the loaded packages
+	BuildTags []string
+	// Parallel workers of the parsing, and of the extracting,
+	// the number of CPUs is used if not set
+	Workers int
 }
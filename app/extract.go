@@ -4,9 +4,9 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spaceavocado/apidoc/extract"
+	"github.com/spaceavocado/apidoc/misc"
 )
 
 // ExtractResult of the documentation extraction
@@ -37,24 +37,47 @@ func (a *App) Extract() (ExtractResult, error) {
 		r.Endpoints = append(r.Endpoints, blocks[1:]...)
 	}
 
-	// Endpoint files
-	err = filepath.Walk(a.conf.EndsRoot, func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() || strings.HasSuffix(path, ".go") == false {
+	// Endpoint files, the packages are parsed once by the
+	// loader, the root file is not extracted again. The files
+	// are extracted in parallel, the blocks are collected
+	// in the order of the files.
+	files, err := a.loader.Walk(a.conf.EndsRoot)
+	if err != nil {
+		return r, err
+	}
+	extracted := make([][]extract.Block, len(files))
+	err = misc.Parallel(len(files), a.conf.Workers, func(i int) error {
+		if sameFile(files[i].Path, a.conf.MainFile) {
 			return nil
 		}
-
-		blocks, err = a.extractor.Extract(path)
-		if err != nil {
-			return err
-		}
-		if len(blocks) != 0 {
-			r.Endpoints = append(r.Endpoints, blocks...)
-		}
-		return nil
+		blocks, err := a.extractor.Extract(files[i].Path)
+		extracted[i] = blocks
+		return err
 	})
-
 	if err != nil {
 		return r, err
 	}
+	for _, blocks := range extracted {
+		r.Endpoints = append(r.Endpoints, blocks...)
+	}
+
+	// Endpoints of the routes without the annotation,
+	// and the schemas of the handler functions
+	if a.conf.InferEndpoints || a.conf.InferSchemas {
+		r.Endpoints = a.extractor.Infer(r.Endpoints)
+	}
 	return r, nil
 }
+
+// sameFile checks if the paths locate the same file, i.e.
+// regardless of the relative, or the absolute, paths
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA == nil && errB == nil && absA == absB {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spaceavocado/apidoc/extract"
@@ -22,9 +23,14 @@ func (e *mockExtractor) Extract(file string) ([]extract.Block, error) {
 	return []extract.Block{}, errors.New("simulated error")
 }
 
+func (e *mockExtractor) Infer(blocks []extract.Block) []extract.Block {
+	return blocks
+}
+
 func TestExtract(t *testing.T) {
 	content := []string{
-		`
+		`package tmp
+
 		// @summary Refresh ID Token
 		// @desc Use the refresh token
 		// to receive a new ID token.
@@ -32,14 +38,17 @@ func TestExtract(t *testing.T) {
 		`,
 		"func main(){}",
 		"func main(){}",
-		`
+		`package tmp
+
 		// @summary Refresh ID Token
 		// @desc Use the refresh token
 		// to receive a new ID token.
 		// It must be in a valid format.
 
-		code := 3
-		// @summary Endpoint 
+		func main() {
+			code := 3
+			// @summary Endpoint 
+		}
 		`,
 	}
 	files := []string{
@@ -131,3 +140,47 @@ func TestExtract(t *testing.T) {
 		return
 	}
 }
+
+func TestExtractMainFile(t *testing.T) {
+	content := `package tmpmain
+
+	// @title Main
+
+	// @summary Endpoint
+	// @router /endpoint [get]
+	func Endpoint() {}
+	`
+	err := os.MkdirAll("tmpmain", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpmain")
+	err = ioutil.WriteFile("tmpmain/main.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	abs, err := filepath.Abs("tmpmain/main.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	// The main file is extracted once, regardless
+	// of the relative, or the absolute, paths
+	for _, main := range []string{"tmpmain/main.go", "./tmpmain/../tmpmain/main.go", abs} {
+		a := New(Configuration{
+			MainFile: main,
+			EndsRoot: "tmpmain/",
+		})
+		res, err := a.Extract()
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		if len(res.Endpoints) != 1 {
+			t.Errorf("Expected %d endpoints, got %d", 1, len(res.Endpoints))
+		}
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spaceavocado/apidoc/misc"
 	"github.com/spaceavocado/apidoc/token"
 )
 
 // RequiredMainTokens to be present in the main block
 var requiredMainTokens = []string{"title", "ver"}
 
-// RequiredEndpointTokens to be present in the endpoint block
-var requiredEndpointTokens = []string{"router", "produce", "success"}
+// RequiredEndpointTokens to be present in the endpoint block,
+// i.e. any of the alternatives, e.g. the router or the webhook
+var requiredEndpointTokens = [][]string{{"router", "webhook"}, {"produce"}, {"success"}}
 
 // TokenizationResult produced by token parser
 type TokenizationResult struct {
@@ -69,7 +71,7 @@ func (a *App) ReduceEndpoints(endpoints [][]token.Token) [][]token.Token {
 		for _, rt := range requiredEndpointTokens {
 			valid = false
 			for _, t := range e {
-				if t.Key == rt {
+				if misc.StringInSlice(t.Key, rt) {
 					valid = true
 					break
 				}
@@ -110,9 +110,20 @@ func TestReduceEndpoints(t *testing.T) {
 			{Key: "router"},
 			{Key: "summary"},
 		},
+		// Valid webhook
+		{
+			{Key: "success"},
+			{Key: "produce"},
+			{Key: "webhook"},
+		},
+		// Invalid, neither router nor webhook
+		{
+			{Key: "success"},
+			{Key: "produce"},
+		},
 	})
-	if len(res) != 1 {
-		t.Errorf("Expected %d log entries, got %d", 1, len(res))
+	if len(res) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(res))
 	}
 
 	// Verbose
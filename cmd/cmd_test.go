@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
@@ -28,11 +29,119 @@ func TestRootCmd(t *testing.T) {
 	c.PersistentFlags().StringP("main", "m", "not-existing-file", "")
 	c.PersistentFlags().StringP("endpoints", "e", "./", "")
 	c.PersistentFlags().StringP("output", "o", "docs/api", "")
+	c.PersistentFlags().String("generator", "openapi", "")
+	c.PersistentFlags().String("format", "yaml", "")
+	c.PersistentFlags().String("openapi", "3.0", "")
+	c.PersistentFlags().Bool("markdown-per-tag", false, "")
 	c.PersistentFlags().BoolP("verbose", "v", false, "")
+	c.PersistentFlags().StringToStringP("known-type", "t", map[string]string{}, "")
+	c.PersistentFlags().Bool("nullable-pointers", false, "")
+	c.PersistentFlags().Bool("infer-endpoints", false, "")
+	c.PersistentFlags().Bool("infer-schemas", false, "")
+	c.PersistentFlags().StringSlice("decode-func", []string{}, "")
+	c.PersistentFlags().StringSlice("encode-func", []string{}, "")
+	c.PersistentFlags().StringSlice("tags", []string{}, "")
+	c.PersistentFlags().Int("workers", 0, "")
 
 	cmd = RootCmd()
 	cmd.Run(&c, []string{""})
 	if len(hook.Entries) != 2 {
 		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
 	}
+
+	// Invalid format
+	hook.Reset()
+	c.PersistentFlags().Set("format", "xml")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, err := hook.Entries[1].String()
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if strings.Contains(o, "Invalid output format") == false {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "Invalid output format", o)
+	}
+
+	// Invalid generator
+	hook.Reset()
+	c.PersistentFlags().Set("generator", "pdf")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "Invalid generator") == false {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "Invalid generator", o)
+	}
+	c.PersistentFlags().Set("generator", "openapi")
+
+	// Invalid OpenAPI version
+	hook.Reset()
+	c.PersistentFlags().Set("format", "yaml")
+	c.PersistentFlags().Set("openapi", "1.2")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "Invalid OpenAPI version") == false {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "Invalid OpenAPI version", o)
+	}
+
+	// Swagger version of the HTML generator
+	hook.Reset()
+	c.PersistentFlags().Set("generator", "html")
+	c.PersistentFlags().Set("openapi", "2.0")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "the html generator uses 3.0 or 3.1") == false {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "the html generator uses 3.0 or 3.1", o)
+	}
+
+	// Output format of the HTML generator
+	hook.Reset()
+	c.PersistentFlags().Set("openapi", "3.1")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) < 2 {
+		t.Errorf("Expected at least %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	if hook.Entries[1].Level != log.WarnLevel {
+		t.Errorf("Expected \"%s\" level, got \"%s\"", log.WarnLevel, hook.Entries[1].Level)
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "The output format is not used by the html generator") == false {
+		t.Errorf("Expected \"%s\" warning, got \"%s\"", "The output format is not used by the html generator", o)
+	}
+
+	// Swagger version of the Markdown generator
+	hook.Reset()
+	c.PersistentFlags().Set("generator", "markdown")
+	c.PersistentFlags().Set("openapi", "2.0")
+	cmd = RootCmd()
+	cmd.Run(&c, []string{""})
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+		return
+	}
+	o, _ = hook.Entries[1].String()
+	if strings.Contains(o, "the markdown generator uses 3.0 or 3.1") == false {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "the markdown generator uses 3.0 or 3.1", o)
+	}
+	c.PersistentFlags().Set("generator", "openapi")
 }
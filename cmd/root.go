@@ -5,6 +5,10 @@ package cmd
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spaceavocado/apidoc/app"
+	"github.com/spaceavocado/apidoc/misc"
+	out "github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/output/swagger"
 	"github.com/spf13/cobra"
 )
 
@@ -18,17 +22,66 @@ func RootCmd() *cobra.Command {
 			mainFile, err := c.PersistentFlags().GetString("main")
 			endsRoot, err := c.PersistentFlags().GetString("endpoints")
 			output, err := c.PersistentFlags().GetString("output")
+			generator, err := c.PersistentFlags().GetString("generator")
+			format, err := c.PersistentFlags().GetString("format")
+			openAPIVersion, err := c.PersistentFlags().GetString("openapi")
+			markdownPerTag, err := c.PersistentFlags().GetBool("markdown-per-tag")
 			verbose, err := c.PersistentFlags().GetBool("verbose")
+			knownTypes, err := c.PersistentFlags().GetStringToString("known-type")
+			nullablePointers, err := c.PersistentFlags().GetBool("nullable-pointers")
+			inferEndpoints, err := c.PersistentFlags().GetBool("infer-endpoints")
+			inferSchemas, err := c.PersistentFlags().GetBool("infer-schemas")
+			decodeFuncs, err := c.PersistentFlags().GetStringSlice("decode-func")
+			encodeFuncs, err := c.PersistentFlags().GetStringSlice("encode-func")
+			buildTags, err := c.PersistentFlags().GetStringSlice("tags")
+			workers, err := c.PersistentFlags().GetInt("workers")
 			if err != nil {
 				log.Errorf("Invalid CLI flags, please use the -h flag to see all available options: %+v", err)
 				return
 			}
+			if misc.StringInSlice(generator, app.Generators) == false {
+				log.Errorf("Invalid generator, please use openapi, html or markdown: %s", generator)
+				return
+			}
+			formats, err := out.ParseFormat(format)
+			if err != nil {
+				log.Errorf("Invalid output format, please use yaml, json or both: %+v", err)
+				return
+			}
+			if openAPIVersion != swagger.Version {
+				openAPIVersion, err = openapi.ParseVersion(openAPIVersion)
+				if err != nil {
+					log.Errorf("Invalid OpenAPI version, please use 2.0, 3.0 or 3.1: %+v", err)
+					return
+				}
+			}
+			if generator != app.OpenAPIGenerator {
+				if openAPIVersion == swagger.Version {
+					log.Errorf("Invalid OpenAPI version, the %s generator uses 3.0 or 3.1: %s", generator, openAPIVersion)
+					return
+				}
+				if c.PersistentFlags().Changed("format") {
+					log.Warnf("The output format is not used by the %s generator: %s", generator, format)
+				}
+			}
 
 			app := app.New(app.Configuration{
-				MainFile: mainFile,
-				EndsRoot: endsRoot,
-				Output:   output,
-				Verbose:  verbose,
+				MainFile:         mainFile,
+				EndsRoot:         endsRoot,
+				Output:           output,
+				Generator:        generator,
+				Formats:          formats,
+				OpenAPIVersion:   openAPIVersion,
+				MarkdownPerTag:   markdownPerTag,
+				Verbose:          verbose,
+				KnownTypes:       knownTypes,
+				NullablePointers: nullablePointers,
+				InferEndpoints:   inferEndpoints,
+				InferSchemas:     inferSchemas,
+				DecodeFuncs:      decodeFuncs,
+				EncodeFuncs:      encodeFuncs,
+				BuildTags:        buildTags,
+				Workers:          workers,
 			})
 			app.Start()
 		},
@@ -38,7 +91,19 @@ func RootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().StringP("main", "m", "main.go", "Main API documentation file")
 	rootCmd.PersistentFlags().StringP("endpoints", "e", "./", "Root endpoints folder")
 	rootCmd.PersistentFlags().StringP("output", "o", "docs/api", "Documentation output folder")
+	rootCmd.PersistentFlags().String("generator", app.OpenAPIGenerator, "Documentation generator, i.e. openapi, html for the static site, or markdown")
+	rootCmd.PersistentFlags().String("format", out.YAML, "Documentation output format, i.e. yaml, json or both")
+	rootCmd.PersistentFlags().String("openapi", "3.0", "OpenAPI version of the documentation, i.e. 2.0 (Swagger), 3.0 or 3.1")
+	rootCmd.PersistentFlags().Bool("markdown-per-tag", false, "One Markdown file per tag, otherwise one file for everything")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Show generation warnings")
+	rootCmd.PersistentFlags().StringToStringP("known-type", "t", map[string]string{}, "Map a type to OpenAPI type and format, e.g. github.com/shopspring/decimal.Decimal=string:decimal")
+	rootCmd.PersistentFlags().Bool("nullable-pointers", false, "Describe pointer fields as nullable")
+	rootCmd.PersistentFlags().Bool("infer-endpoints", false, "Infer endpoints from the routes without the annotation")
+	rootCmd.PersistentFlags().Bool("infer-schemas", false, "Infer request and response schemas from the handler functions")
+	rootCmd.PersistentFlags().StringSlice("decode-func", []string{}, "Request body decode helper, e.g. request.ParseJSONBody")
+	rootCmd.PersistentFlags().StringSlice("encode-func", []string{}, "Response encode helper, optionally with the status code, e.g. response.Error=500")
+	rootCmd.PersistentFlags().StringSlice("tags", []string{}, "Build tags satisfied by the parsed packages, e.g. integration,pro")
+	rootCmd.PersistentFlags().Int("workers", 0, "Parallel workers of the parsing and the extracting, defaults to the number of CPUs")
 
 	// Other commands
 	rootCmd.AddCommand(versionCmd)
@@ -5,28 +5,38 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	// Common respose, structs referenced in the API documentation
+	"github.com/spaceavocado/apidoc/example/common"
 	"github.com/spaceavocado/apidoc/example/request"
 	"github.com/spaceavocado/apidoc/example/response"
-	// Common respose, structs referenced in the API documentation
-	_ "github.com/spaceavocado/apidoc/example/common"
 )
 
 // Person response model
 type Person struct {
-	Name string `json:"fullname" required:"true"`
+	Name string `json:"fullname" required:"true" example:"Peter Williams"`
 	// User's Profile
 	Detail Detail `json:"profile"`
+	// Addresses by the kind, e.g. home, work
+	Addresses map[string]common.Address `json:"addresses"`
+	// Custom labels
+	Labels map[string]string `json:"labels"`
 }
 
 // Detail of the user
 type Detail struct {
-	Age    int64  `json:"age"`
-	Status Status `json:"status" apitype:"int"`
+	Age    int64  `json:"age" validate:"gte=0,lte=150"`
+	Status Status `json:"status"`
 }
 
 // Status of the person
 type Status int
 
+// Person statuses
+const (
+	StatusActive Status = iota
+	StatusInactive
+)
+
 // Handlers for this resource / API section
 func Handlers(r *mux.Router) {
 	// GetPerson handler
@@ -49,7 +59,20 @@ func Handlers(r *mux.Router) {
 	// @failure 500 {string} Internal Server Error
 	r.HandleFunc("/person/{id:[0-9]+}", GetPerson).Methods("GET")
 
+	// ListPeople handler
+	// @summary People
+	// @desc List people.
+	// @id people
+	// @tag Person
+	// @produce json
+	// @success 200 {object} response.Page[Person] OK
+	// @failure 500 {string} Internal Server Error
+	r.HandleFunc("/person", ListPeople).Methods("GET")
+
 	r.HandleFunc("/person", CreatePerson).Methods("PUT")
+
+	// Inferred endpoint, see the --infer-endpoints flag
+	r.HandleFunc("/person/{id:[0-9]+}", DeletePerson).Methods(http.MethodDelete)
 }
 
 // GetAddress request
@@ -70,6 +93,13 @@ func GetPerson(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListPeople request
+func ListPeople(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, 200, response.Page[Person]{
+		Items: []Person{},
+	})
+}
+
 // CreatePerson handler
 // @summary Create
 // @desc Create a new Person
@@ -95,3 +125,13 @@ func CreatePerson(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Printf("Create a new person '%s'", model.Name)
 }
+
+// DeletePerson handler
+// @summary Delete
+// @desc Delete person by ID.
+// @id delete-person
+// @tag Person
+// @success 204 {string} No Content
+func DeletePerson(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -25,6 +25,12 @@ type APIError struct {
 	Message string `json:"message"`
 }
 
+// Page of the listed items
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
 // APIResponseError in normalized format
 func APIResponseError(w http.ResponseWriter, err APIError) {
 	JSON(w, 500, Error{
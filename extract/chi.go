@@ -0,0 +1,63 @@
+package extract
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// go-chi/chi router
+type chiRouter struct{}
+
+// Imports checks if the import path is the router package
+func (r *chiRouter) Imports(path string) bool {
+	return path == "github.com/go-chi/chi" || strings.HasPrefix(path, "github.com/go-chi/chi/v")
+}
+
+// Route registered by r.Get("/x", h), r.Method("GET", "/x", h),
+// or by r.HandleFunc("/x", h) for any method
+func (r *chiRouter) Route(calls map[string]*ast.CallExpr) (Route, bool) {
+	route, ok := verbRoute(calls, func(m string) string {
+		return m[:1] + strings.ToLower(m[1:])
+	}, false)
+	if ok {
+		return route, true
+	}
+
+	for _, name := range []string{"Method", "MethodFunc"} {
+		if c, ok := calls[name]; ok {
+			m, mok := methodArg(c, 0)
+			path, pok := stringArg(c, 1)
+			if mok && pok {
+				return Route{Path: path, Methods: []string{m}, Router: receiver(c), Handler: handlerArg(c, 2, false)}, true
+			}
+		}
+	}
+
+	for _, name := range []string{"HandleFunc", "Handle"} {
+		if c, ok := calls[name]; ok {
+			if path, ok := stringArg(c, 0); ok && strings.HasPrefix(path, "/") {
+				return Route{Path: path, Router: receiver(c), Handler: handlerArg(c, 1, false)}, true
+			}
+		}
+	}
+	return Route{}, false
+}
+
+// Group created by r.Route("/x", func(r chi.Router) {...}),
+// r.Mount("/x", h), or r.Group(func(r chi.Router) {...})
+// without the prefix
+func (r *chiRouter) Group(calls map[string]*ast.CallExpr) (Group, bool) {
+	for _, name := range []string{"Route", "Mount"} {
+		if c, ok := calls[name]; ok && len(c.Args) == 2 {
+			if prefix, ok := stringArg(c, 0); ok {
+				return Group{Prefix: prefix, Router: receiver(c), Target: c.Args[1]}, true
+			}
+		}
+	}
+	if c, ok := calls["Group"]; ok && len(c.Args) == 1 {
+		if _, ok := stringArg(c, 0); ok == false {
+			return Group{Router: receiver(c), Target: c.Args[0]}, true
+		}
+	}
+	return Group{}, false
+}
@@ -0,0 +1,86 @@
+package extract
+
+import (
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// Path params of echo and gin, i.e. :name, or *name
+var colonParamRx = regexp.MustCompile("/[:*]([^/]+)")
+
+// labstack/echo router
+type echoRouter struct{}
+
+// Imports checks if the import path is the router package
+func (r *echoRouter) Imports(path string) bool {
+	return path == "github.com/labstack/echo" || strings.HasPrefix(path, "github.com/labstack/echo/v")
+}
+
+// Route registered by e.GET("/x", h), e.Add("GET", "/x", h),
+// e.Match([]string{"GET"}, "/x", h), or by e.Any("/x", h)
+func (r *echoRouter) Route(calls map[string]*ast.CallExpr) (Route, bool) {
+	route, ok := verbRoute(calls, func(m string) string {
+		return m
+	}, false)
+	if ok == false {
+		route, ok = colonRoute(calls, "Add", false)
+	}
+	if ok == false {
+		return Route{}, false
+	}
+	route.Path = colonParams(route.Path)
+	return route, true
+}
+
+// Group created by g := e.Group("/x")
+func (r *echoRouter) Group(calls map[string]*ast.CallExpr) (Group, bool) {
+	return colonGroup(calls)
+}
+
+// colonRoute registered by the method given in the first
+// argument, e.g. r.Handle("GET", "/x", h), the methods given
+// as a slice, e.g. r.Match([]string{"GET"}, "/x", h),
+// or by r.Any("/x", h). The handler is the last argument
+// if last is set.
+func colonRoute(calls map[string]*ast.CallExpr, name string, last bool) (Route, bool) {
+	if c, ok := calls[name]; ok {
+		m, mok := methodArg(c, 0)
+		path, pok := stringArg(c, 1)
+		if mok && pok {
+			return Route{Path: path, Methods: []string{m}, Router: receiver(c), Handler: handlerArg(c, 2, last)}, true
+		}
+	}
+	if c, ok := calls["Match"]; ok {
+		methods, mok := methodsArg(c, 0)
+		path, pok := stringArg(c, 1)
+		if mok && pok {
+			return Route{Path: path, Methods: methods, Router: receiver(c), Handler: handlerArg(c, 2, last)}, true
+		}
+	}
+	if c, ok := calls["Any"]; ok {
+		if path, ok := stringArg(c, 0); ok {
+			return Route{Path: path, Router: receiver(c), Handler: handlerArg(c, 1, last)}, true
+		}
+	}
+	return Route{}, false
+}
+
+// colonGroup created by g := r.Group("/x")
+func colonGroup(calls map[string]*ast.CallExpr) (Group, bool) {
+	c, ok := calls["Group"]
+	if ok == false {
+		return Group{}, false
+	}
+	prefix, ok := stringArg(c, 0)
+	if ok == false {
+		return Group{}, false
+	}
+	return Group{Prefix: colonParams(prefix), Router: receiver(c)}, true
+}
+
+// colonParams of the path converted into
+// the {name} format, e.g. /:id into /{id}
+func colonParams(path string) string {
+	return colonParamRx.ReplaceAllString(path, "/{$1}")
+}
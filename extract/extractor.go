@@ -7,15 +7,19 @@
 package extract
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
-	"os"
+	"io/ioutil"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spaceavocado/apidoc/loader"
 )
 
 // Block extracted raw documentation lines
@@ -24,126 +28,345 @@ type Block struct {
 	File string
 	// Lines extracted from the file
 	Lines []string
+	// Function documented by the block, i.e. "dir.Name" by the
+	// folder of the package, empty if the block is not placed
+	// above a function
+	Func string
+	// Handler function of the route, i.e. "dir.Name" by the
+	// folder of the package, empty if the block is not
+	// placed above a route
+	Handler string
+	// Import name of the handler package in the file of the
+	// route, empty if the handler is declared in the package
+	// of the route
+	HandlerImport string
+	// Route registered without the annotation, nil for
+	// the annotation blocks, see the Extractor Infer
+	Route *InferredRoute
+	// Lines proposed by the function body analysis,
+	// see the Extractor Infer
+	Proposed []string
+}
+
+// InferredRoute of a handler registration without the annotation
+type InferredRoute struct {
+	// Path of the route, prefixed by the subrouters
+	Path string
+	// Methods of the route, comma separated
+	Methods string
+}
+
+// Inference options of the extractor
+type Inference struct {
+	// Endpoints of the routes without the annotation
+	Endpoints bool
+	// Request and response schemas of the handler functions
+	Schemas bool
+	// Names of the project decode helpers, e.g. request.ParseJSONBody
+	DecodeFuncs []string
+	// Names of the project encode helpers, optionally with
+	// the status code, e.g. response.JSON, or response.Error=500
+	EncodeFuncs []string
 }
 
 // Extractor interface
 type Extractor interface {
 	// Extract the documentation from the file
 	Extract(path string) ([]Block, error)
+	// Infer the endpoints of the routes without the annotation,
+	// and the schemas of the handler functions
+	Infer(blocks []Block) []Block
 }
 
 type extractor struct {
-	verbose               bool
-	commentRx             *regexp.Regexp
-	apiDocRx              *regexp.Regexp
-	gorillaMuxHandlerRx   *regexp.Regexp
-	gorillaMuxSubrouterRx *regexp.Regexp
-	pathCleanRx           *regexp.Regexp
-	pathParamRx           *regexp.Regexp
+	verbose     bool
+	loader      loader.Loader
+	infer       bool
+	schemas     *schemas
+	routers     []Router
+	apiDocRx    *regexp.Regexp
+	pathCleanRx *regexp.Regexp
+	pathParamRx *regexp.Regexp
+	// Go modules cache location
+	modcache string
+	// Resolved go.mod modules by folder,
+	// nil if the folder is not in a module
+	modules map[string]*loader.Module
+	mu      sync.Mutex
 }
 
 // Extract the documentation from the file.
 // A Block is defined an uniterupted comments section
 // in which there is at least one comment with "@" prefix.
 func (e *extractor) Extract(file string) ([]Block, error) {
-	fp, err := os.Open(file)
+	f, err := e.loader.File(file)
 	if err != nil {
 		return []Block{}, err
 	}
-	defer fp.Close()
-	return e.parse(bufio.NewReader(fp), file)
+	return e.extract(e.loader.FileSet(), f), nil
 }
 
-// Parse file content
-func (e *extractor) parse(r *bufio.Reader, file string) ([]Block, error) {
+// Parse file content, outside of the loader
+func (e *extractor) parse(r io.Reader, file string) ([]Block, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return []Block{}, err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if f == nil {
+		return []Block{}, err
+	}
+	return e.extract(fset, &loader.File{Path: file, Src: src, AST: f, Err: err}), nil
+}
+
+// extract the blocks of the parsed file. Each comment group
+// is attached to the declaration, or the statement,
+// starting on the line following the group.
+func (e *extractor) extract(fset *token.FileSet, lf *loader.File) []Block {
 	blocks := []Block{}
-	isComment := false
-	commentBuffer := ""
-	block := Block{
-		File: file,
-	}
-
-	for {
-		var err error
-		var buffer bytes.Buffer
-		var b []byte
-		var isPrefix bool
-		for {
-			b, isPrefix, err = r.ReadLine()
-			buffer.Write(b)
-			if isPrefix == false || err != nil {
-				break
+	f, src, file := lf.AST, lf.Src, lf.Path
+	// Partially parsed file is still usable
+	if lf.Err != nil && e.verbose {
+		log.Warnf("extracting: the file \"%s\" contains errors: %v", file, lf.Err)
+	}
+
+	nodes := e.lineNodes(fset, f)
+	fields := e.fieldComments(f)
+	rt := e.routing(file, f)
+	attached := make(map[ast.Node]bool, 0)
+	for _, cg := range f.Comments {
+		// Trailing comments, and the comments of the
+		// struct fields, are not endpoint documentation
+		if fields[cg] || e.isTrailing(fset, src, cg) {
+			continue
+		}
+		block := Block{
+			File:  file,
+			Lines: e.commentLines(cg),
+		}
+		if len(block.Lines) == 0 {
+			continue
+		}
+		if n, ok := nodes[fset.Position(cg.End()).Line+1]; ok {
+			if fd, ok := n.node.(*ast.FuncDecl); ok {
+				block.Func = declKey(rt.dir, fd.Name.Name)
+				if e.schemas != nil {
+					block.Proposed = e.moduleTypes(file, e.schemas.Propose(fd), rt)
+				}
 			}
+			block = e.attach(block, n, rt)
+			attached[n.node] = true
 		}
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return blocks, err
+		blocks = append(blocks, block)
+	}
+
+	// Handler functions without the annotation
+	if e.schemas != nil {
+		blocks = append(blocks, e.funcBlocks(file, f, rt, attached)...)
+	}
+
+	// Routes without the annotation
+	if e.infer && rt.detected {
+		blocks = append(blocks, e.routeBlocks(file, f, rt, attached)...)
+	}
+
+	return blocks
+}
+
+// Declaration, or statement, starting on a line
+type lineNode struct {
+	node ast.Node
+	// Name of the function declaring the node
+	fn string
+}
+
+// lineNodes maps the lines to the outermost declaration,
+// or statement, starting on the line
+func (e *extractor) lineNodes(fset *token.FileSet, f *ast.File) map[int]lineNode {
+	nodes := make(map[int]lineNode, 0)
+	for _, d := range f.Decls {
+		fn := ""
+		if fd, ok := d.(*ast.FuncDecl); ok {
+			fn = fd.Name.Name
 		}
-		line := buffer.String()
+		ast.Inspect(d, func(n ast.Node) bool {
+			switch n.(type) {
+			case ast.Decl, ast.Stmt:
+				line := fset.Position(n.Pos()).Line
+				if _, ok := nodes[line]; ok == false {
+					nodes[line] = lineNode{node: n, fn: fn}
+				}
+			}
+			return true
+		})
+	}
+	return nodes
+}
 
-		// Comment line
-		if cm := e.commentRx.FindStringSubmatch(line); len(cm) > 0 {
-			if !isComment {
-				isComment = true
-				if len(block.Lines) > 0 {
-					block = Block{
-						File: file,
+// fieldComments of the fields of the declared types, i.e. the
+// field annotations, e.g. @example, resolved by the references
+func (e *extractor) fieldComments(f *ast.File) map[*ast.CommentGroup]bool {
+	comments := make(map[*ast.CommentGroup]bool, 0)
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if ok == false {
+			return true
+		}
+		ast.Inspect(ts.Type, func(n ast.Node) bool {
+			if field, ok := n.(*ast.Field); ok {
+				for _, cg := range []*ast.CommentGroup{field.Doc, field.Comment} {
+					if cg != nil {
+						comments[cg] = true
 					}
 				}
 			}
+			return true
+		})
+		return false
+	})
+	return comments
+}
 
-			// API comment
-			if m := e.apiDocRx.FindStringSubmatch(cm[1]); len(m) > 0 {
-				block.Lines = append(block.Lines, m[1])
-				if len(block.Lines) > 2 && len(commentBuffer) > 0 {
-					block.Lines[len(block.Lines)-2] += commentBuffer
-				}
-				commentBuffer = ""
-				// Multiline comment
-			} else if comment := strings.TrimSpace(cm[1]); comment != "" {
-				commentBuffer += fmt.Sprintf(" %s", comment)
+// isTrailing checks if the comment group
+// follows a code on the same line
+func (e *extractor) isTrailing(fset *token.FileSet, src []byte, cg *ast.CommentGroup) bool {
+	pos := fset.Position(cg.Pos())
+	lineStart := pos.Offset - (pos.Column - 1)
+	if lineStart < 0 || pos.Offset > len(src) {
+		return false
+	}
+	return strings.TrimSpace(string(src[lineStart:pos.Offset])) != ""
+}
+
+// commentLines extracts the API documentation lines from the
+// comment group, i.e. the lines with "@" prefix. Following
+// lines without the prefix continue the previous line.
+// Both, the line comments and the block comments, are supported.
+func (e *extractor) commentLines(cg *ast.CommentGroup) []string {
+	raw := make([]string, 0)
+	for _, c := range cg.List {
+		if strings.HasPrefix(c.Text, "//") {
+			raw = append(raw, c.Text[2:])
+			continue
+		}
+		text := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+		for _, l := range strings.Split(text, "\n") {
+			// Decorated block comment, i.e. " * @summary"
+			l = strings.TrimPrefix(strings.TrimSpace(l), "*")
+			raw = append(raw, l)
+		}
+	}
+
+	lines := make([]string, 0)
+	for _, l := range raw {
+		l = strings.TrimLeft(l, " \t")
+		// API comment
+		if m := e.apiDocRx.FindStringSubmatch(l); len(m) > 0 {
+			lines = append(lines, m[1])
+			// Multiline comment
+		} else if comment := strings.TrimSpace(l); comment != "" && len(lines) > 0 {
+			lines[len(lines)-1] += fmt.Sprintf(" %s", comment)
+		}
+	}
+	return lines
+}
+
+// attach the block to the statement following it,
+// i.e. inject the router information found in the
+// handler registration, or in the subrouter
+func (e *extractor) attach(b Block, n lineNode, rt *routing) Block {
+	if _, ok := n.node.(ast.Stmt); ok == false {
+		return b
+	}
+	calls := e.chainCalls(n.node)
+
+	// Subrouter
+	for _, r := range rt.routers {
+		if g, ok := r.Group(calls); ok {
+			if g.Prefix != "" {
+				url := e.pathCleanRx.ReplaceAllString(g.Prefix, "$1}")
+				b.Lines = append(b.Lines, fmt.Sprintf("routerurl %s", url))
 			}
+			return b
+		}
+	}
 
-			// Break the block
-		} else if isComment {
-			isComment = false
-			if len(block.Lines) > 0 {
-				if len(commentBuffer) > 0 {
-					block.Lines[len(block.Lines)-1] += commentBuffer
-				}
-				commentBuffer = ""
-				blocks = append(blocks, block)
-
-				// Gorilla mux router
-				if m := e.gorillaMuxHandlerRx.FindStringSubmatch(line); len(m) > 0 {
-					url := ""
-					methods := ""
-					if m[3] != "" {
-						url = m[3]
-					} else {
-						url = m[1]
-						methods = m[2]
-					}
-					blocks[len(blocks)-1] = e.gorillaMuxHandler(blocks[len(blocks)-1], url, methods)
-					// Gorilla mux subrouter
-				} else if m := e.gorillaMuxSubrouterRx.FindStringSubmatch(line); len(m) > 0 {
-					url := m[1]
-					url = e.pathCleanRx.ReplaceAllString(url, "")
-					blocks[len(blocks)-1].Lines = append(blocks[len(blocks)-1].Lines, fmt.Sprintf("routerurl %s", url))
+	// Handler, prefixed by the subrouters, unless
+	// the subrouter is defined in the annotation
+	for _, r := range rt.routers {
+		if route, ok := r.Route(calls); ok {
+			if hasLine(b, "subrouter ") == false {
+				route.Path = joinPath(rt.Prefix(route.Router, n.fn, 0), route.Path)
+			}
+			b.Handler = rt.HandlerKey(route.Handler)
+			b.HandlerImport = rt.HandlerImport(route.Handler)
+			return e.routeLines(b, route.Path, strings.Join(route.Methods, ", "))
+		}
+	}
+	return b
+}
+
+// hasLine checks if the block contains a line with the prefix
+func hasLine(b Block, prefix string) bool {
+	for _, l := range b.Lines {
+		if strings.HasPrefix(l, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// chainCalls of the statement by the method name, e.g.
+// r.HandleFunc("/x", h).Methods("GET").Name("x").
+// The first call of the name wins, function
+// literals, i.e. inline handlers, are skipped.
+func (e *extractor) chainCalls(n ast.Node) map[string]*ast.CallExpr {
+	calls := make(map[string]*ast.CallExpr, 0)
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if sel, ok := t.Fun.(*ast.SelectorExpr); ok {
+				if _, ok := calls[sel.Sel.Name]; ok == false {
+					calls[sel.Sel.Name] = t
 				}
 			}
 		}
+		return true
+	})
+	return calls
+}
+
+// stringArg of the call, at the index,
+// given as a string literal
+func stringArg(c *ast.CallExpr, i int) (string, bool) {
+	if i >= len(c.Args) {
+		return "", false
+	}
+	lit, ok := c.Args[i].(*ast.BasicLit)
+	if ok == false || lit.Kind != token.STRING {
+		return "", false
 	}
+	return unquote(lit.Value)
+}
 
-	return blocks, nil
+// unquote the string literal value
+func unquote(v string) (string, bool) {
+	v, err := strconv.Unquote(v)
+	if err != nil {
+		return "", false
+	}
+	return v, true
 }
 
-// GorillaMuxHandler parsing resolver
+// RouteLines of the handler registration
 // It tries to inject the information form the handler function signature
 // into the block. Current supported inputs are router path, params from
 // the path, and the methods
-func (e *extractor) gorillaMuxHandler(b Block, url, methods string) Block {
+func (e *extractor) routeLines(b Block, url, methods string) Block {
 	// If the block already contains a router annotation
 	// skip this processing, since it gas higher priority
 	for _, l := range b.Lines {
@@ -161,7 +384,7 @@ func (e *extractor) gorillaMuxHandler(b Block, url, methods string) Block {
 		methods = strings.Replace(methods, "\"", "", -1)
 		methods = strings.ToLower(methods)
 	}
-	url = e.pathCleanRx.ReplaceAllString(url, "")
+	url = e.pathCleanRx.ReplaceAllString(url, "$1}")
 	b.Lines = append(b.Lines, fmt.Sprintf("router %s [%s]", url, methods))
 
 	params := make([]string, 0)
@@ -191,15 +414,30 @@ func (e *extractor) gorillaMuxHandler(b Block, url, methods string) Block {
 	return b
 }
 
-// NewExtractor instance
-func NewExtractor(verbose bool) Extractor {
+// NewExtractor instance, the loader defaults to a new
+// loader, and the routers default to the DefaultRouters,
+// if not provided
+func NewExtractor(verbose bool, l loader.Loader, inference Inference, routers ...Router) Extractor {
+	if l == nil {
+		l = loader.NewLoader(verbose, nil, 0)
+	}
+	if len(routers) == 0 {
+		routers = DefaultRouters()
+	}
+	var s *schemas
+	if inference.Schemas {
+		s = newSchemas(inference.DecodeFuncs, inference.EncodeFuncs)
+	}
 	return &extractor{
-		verbose:               verbose,
-		commentRx:             regexp.MustCompile("^\\s*\\/\\/\\s*(.*)"),
-		apiDocRx:              regexp.MustCompile("^@([^\\s].*)"),
-		gorillaMuxHandlerRx:   regexp.MustCompile("(?:HandleFunc|Handle)\\(\"([^\"]+)\".*\\.Methods\\(([^\\)]+)\\)|(?:HandleFunc|Handle)\\(\"([^\"]+)\""),
-		gorillaMuxSubrouterRx: regexp.MustCompile("PathPrefix\\(\"([^\"]+)\\\"\\)\\.Subrouter\\(\\)"),
-		pathCleanRx:           regexp.MustCompile(":[^}]+"),
-		pathParamRx:           regexp.MustCompile("{([^}]+)}"),
+		verbose:     verbose,
+		loader:      l,
+		infer:       inference.Endpoints,
+		schemas:     s,
+		routers:     routers,
+		apiDocRx:    regexp.MustCompile("^@([^\\s].*)"),
+		pathCleanRx: regexp.MustCompile("({[^}:]+):[^}]+}"),
+		pathParamRx: regexp.MustCompile("{([^}]+)}"),
+		modcache:    loader.ModCacheDir(),
+		modules:     make(map[string]*loader.Module, 0),
 	}
 }
@@ -15,7 +15,8 @@ import (
 
 func TestExtract(t *testing.T) {
 	content :=
-		`
+		`package tmp
+
 	// @summary Refresh ID Token
 	// @desc Use the refresh token
 	// to receive a new ID token.
@@ -32,7 +33,7 @@ func TestExtract(t *testing.T) {
 		os.Remove(file)
 	}()
 
-	e := NewExtractor(false).(*extractor)
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
 	blocks, err := e.Extract(file)
 	if err != nil {
 		t.Errorf("Unexpected error %v", err)
@@ -54,7 +55,8 @@ func TestExtract(t *testing.T) {
 func TestParse(t *testing.T) {
 	// Various indentation for teting
 	test := []string{
-		`
+		`package test
+
 		// ValidateToken request
 			// @summary Validate ID Token
 		//   @desc Perform a validation of the ID Token
@@ -91,25 +93,31 @@ func TestParse(t *testing.T) {
 		`,
 
 		// Gorilla mux handler with methods
-		`
+		`package test
+		func Handlers() {
 		// @summary Refresh ID Token
 		// @desc Use the refresh token
 		// to receive a new ID token.
 		// It must be in a valid format.
 		r.HandleFunc("/person/{id:[0-9]+}", GetPerson).Methods("GET")
+		}
 		`,
 
 		// Gorilla mux handler without methods
-		`
+		`package test
+		func Handlers() {
 		// @summary Refresh ID Token
 		// @desc Use the refresh token.
 		r.Handle("/person/{id:[0-9]+}", GetPerson)
+		}
 		`,
 
 		// Gorilla mux subrouter
-		`
+		`package test
+		func Handlers() {
 		// @router products
-		r.PathPrefix("/products").Subrouter()
+		sr := r.PathPrefix("/products").Subrouter()
+		}
 		`,
 	}
 
@@ -127,7 +135,7 @@ func TestParse(t *testing.T) {
 		"router /validate [post]",
 	}
 
-	e := NewExtractor(false).(*extractor)
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
 	r := bufio.NewReaderSize(strings.NewReader(test[0]), 0)
 
 	blocks, err := e.parse(r, "test.go")
@@ -228,8 +236,8 @@ func TestParse(t *testing.T) {
 	}
 }
 
-func TestGorillaMuxHandler(t *testing.T) {
-	e := NewExtractor(false).(*extractor)
+func TestRouteLines(t *testing.T) {
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
 	testBlocks := []Block{
 		// A block without router param
 		{
@@ -263,7 +271,7 @@ func TestGorillaMuxHandler(t *testing.T) {
 	}
 
 	// Expected injection
-	res := e.gorillaMuxHandler(testBlocks[0], testCaptures[0][0], testCaptures[0][1])
+	res := e.routeLines(testBlocks[0], testCaptures[0][0], testCaptures[0][1])
 	if len(res.Lines) != 4 {
 		t.Errorf("Unexpected count of entries, has %d, expected %d", len(res.Lines), 4)
 		return
@@ -278,14 +286,14 @@ func TestGorillaMuxHandler(t *testing.T) {
 		t.Errorf("Has %s, expected %s", res.Lines[3], "param username path {string} true")
 	}
 
-	res = e.gorillaMuxHandler(testBlocks[0], testCaptures[1][0], testCaptures[1][1])
+	res = e.routeLines(testBlocks[0], testCaptures[1][0], testCaptures[1][1])
 	if len(res.Lines) != 2 {
 		t.Errorf("Unexpected count of entries, has %d, expected %d", len(res.Lines), 2)
 		return
 	}
 
 	// Skip, router is already defined
-	res = e.gorillaMuxHandler(testBlocks[1], testCaptures[0][0], testCaptures[0][1])
+	res = e.routeLines(testBlocks[1], testCaptures[0][0], testCaptures[0][1])
 	if len(res.Lines) != 1 {
 		t.Errorf("Unexpected count of entries, has %d, expected %d", len(res.Lines), 1)
 		return
@@ -295,7 +303,7 @@ func TestGorillaMuxHandler(t *testing.T) {
 	}
 
 	// Skip defined param
-	res = e.gorillaMuxHandler(testBlocks[2], testCaptures[0][0], testCaptures[0][1])
+	res = e.routeLines(testBlocks[2], testCaptures[0][0], testCaptures[0][1])
 	if len(res.Lines) != 3 {
 		t.Errorf("Unexpected count of entries, has %d, expected %d", len(res.Lines), 3)
 		return
@@ -313,19 +321,23 @@ func TestVerbose(t *testing.T) {
 	log.SetOutput(b)
 	hook := test.NewGlobal()
 
-	e := NewExtractor(true).(*extractor)
+	e := NewExtractor(true, nil, Inference{}).(*extractor)
 	tests := []string{
-		`
+		`package test
+		func Handlers() {
 		// @summary Refresh ID Token
 		// @param id path {int} true User ID
 		// @desc Use the refresh token.
 		r.Handle("/person/{id:[0-9]+}", GetPerson)
+		}
 		`,
-		`
+		`package test
+		func Handlers() {
 		// @summary Refresh ID Token
 		// @param id path {int} true User ID
 		// @router /person/{id} get 
 		r.Handle("/person/{id:[0-9]+}", GetPerson)
+		}
 		`,
 	}
 
@@ -360,6 +372,48 @@ func TestVerbose(t *testing.T) {
 	}
 }
 
+func TestFieldAnnotations(t *testing.T) {
+	b := &bytes.Buffer{}
+	log.SetOutput(b)
+	hook := test.NewGlobal()
+
+	content := `package test
+
+	// Person model
+	type Person struct {
+		// Name of the person
+		// @example Peter
+		Name string
+		Age  int // @default 18
+		Home struct {
+			// @example Prague
+			City string
+		}
+	}
+
+	// @summary Get person
+	// @router /person [get]
+	func GetPerson() {}
+	`
+	e := NewExtractor(true, nil, Inference{}).(*extractor)
+	blocks, err := e.parse(strings.NewReader(content), "test.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if len(blocks) != 1 {
+		t.Errorf("Expected %d blocks, got %d: %v", 1, len(blocks), blocks)
+		return
+	}
+	expected := "summary Get person|router /person [get]"
+	if res := strings.Join(blocks[0].Lines, "|"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+	if len(hook.Entries) != 0 {
+		t.Errorf("Expected %d log entries, got %d", 0, len(hook.Entries))
+	}
+}
+
 type errorReader struct{}
 
 func (r *errorReader) Read(p []byte) (n int, err error) {
@@ -367,7 +421,7 @@ func (r *errorReader) Read(p []byte) (n int, err error) {
 }
 
 func TestReader(t *testing.T) {
-	e := NewExtractor(true).(*extractor)
+	e := NewExtractor(true, nil, Inference{}).(*extractor)
 	r := bufio.NewReaderSize(&errorReader{}, 0)
 	blocks, err := e.parse(r, "test.go")
 	if err == nil {
@@ -378,3 +432,81 @@ func TestReader(t *testing.T) {
 		t.Errorf("Unexpected parsing")
 	}
 }
+
+func TestParseSource(t *testing.T) {
+	content := `package test
+
+	/*
+	 * @summary Person
+	 * @desc Get person
+	 * by ID.
+	 */
+	func GetPerson() {}
+
+	func Handlers() {
+		/* @summary List */
+		r.HandleFunc(
+			"/person/{id:[0-9]+}/list",
+			List,
+		).
+			Methods("GET", "POST").
+			Name("list").
+			Schemes("https")
+
+		// @summary Inline
+		r.HandleFunc("/inline", func(w http.ResponseWriter, r *http.Request) {
+			sr.HandleFunc("/ignored", Ignored)
+		})
+
+		x := 1 // @summary Trailing
+	}
+	`
+
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
+	blocks, err := e.parse(strings.NewReader(content), "test.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	expected := [][]string{
+		{
+			"summary Person",
+			"desc Get person by ID.",
+		},
+		{
+			"summary List",
+			"router /person/{id}/list [get, post]",
+			"param id path {string} true",
+		},
+		{
+			"summary Inline",
+			"router /inline [get]",
+		},
+	}
+	if len(blocks) != len(expected) {
+		t.Errorf("Expected %d blocks, got %d: %v", len(expected), len(blocks), blocks)
+		return
+	}
+	for i, b := range blocks {
+		if len(b.Lines) != len(expected[i]) {
+			t.Errorf("Expected %d lines, got %d: %v", len(expected[i]), len(b.Lines), b.Lines)
+			continue
+		}
+		for j, l := range b.Lines {
+			if l != expected[i][j] {
+				t.Errorf("Expected \"%s\", got \"%s\"", expected[i][j], l)
+			}
+		}
+	}
+
+	// Not a Go source
+	blocks, err = e.parse(strings.NewReader("// @summary Person"), "test.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if len(blocks) != 0 {
+		t.Errorf("Expected no blocks, got %v", blocks)
+	}
+}
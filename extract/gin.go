@@ -0,0 +1,34 @@
+package extract
+
+import (
+	"go/ast"
+)
+
+// gin-gonic/gin router
+type ginRouter struct{}
+
+// Imports checks if the import path is the router package
+func (r *ginRouter) Imports(path string) bool {
+	return path == "github.com/gin-gonic/gin"
+}
+
+// Route registered by r.GET("/x", h), r.Handle("GET", "/x", h),
+// r.Match([]string{"GET"}, "/x", h), or by r.Any("/x", h)
+func (r *ginRouter) Route(calls map[string]*ast.CallExpr) (Route, bool) {
+	route, ok := verbRoute(calls, func(m string) string {
+		return m
+	}, true)
+	if ok == false {
+		route, ok = colonRoute(calls, "Handle", true)
+	}
+	if ok == false {
+		return Route{}, false
+	}
+	route.Path = colonParams(route.Path)
+	return route, true
+}
+
+// Group created by g := r.Group("/x")
+func (r *ginRouter) Group(calls map[string]*ast.CallExpr) (Group, bool) {
+	return colonGroup(calls)
+}
@@ -0,0 +1,64 @@
+package extract
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// gorilla/mux router
+type gorillaMux struct{}
+
+// Imports checks if the import path is the router package
+func (r *gorillaMux) Imports(path string) bool {
+	return path == "github.com/gorilla/mux"
+}
+
+// Route registered by r.HandleFunc("/x", h).Methods("GET"),
+// or by r.Path("/x").HandlerFunc(h).Methods("GET")
+func (r *gorillaMux) Route(calls map[string]*ast.CallExpr) (Route, bool) {
+	c, ok := calls["HandleFunc"]
+	if ok == false {
+		c, ok = calls["Handle"]
+	}
+	var handler ast.Expr
+	if ok {
+		handler = handlerArg(c, 1, false)
+	} else {
+		c, ok = calls["Path"]
+		h, hok := calls["HandlerFunc"]
+		if hok == false {
+			h, hok = calls["Handler"]
+		}
+		if ok == false || hok == false {
+			return Route{}, false
+		}
+		handler = handlerArg(h, 0, false)
+	}
+	path, ok := stringArg(c, 0)
+	if ok == false || strings.HasPrefix(path, "/") == false {
+		return Route{}, false
+	}
+
+	methods := make([]string, 0)
+	if m, ok := calls["Methods"]; ok {
+		for i := range m.Args {
+			if v, ok := methodArg(m, i); ok {
+				methods = append(methods, v)
+			}
+		}
+	}
+	return Route{Path: path, Methods: methods, Router: receiver(c), Handler: handler}, true
+}
+
+// Group created by r.PathPrefix("/x").Subrouter()
+func (r *gorillaMux) Group(calls map[string]*ast.CallExpr) (Group, bool) {
+	c, ok := calls["PathPrefix"]
+	if _, sub := calls["Subrouter"]; ok == false || sub == false {
+		return Group{}, false
+	}
+	prefix, ok := stringArg(c, 0)
+	if ok == false {
+		return Group{}, false
+	}
+	return Group{Prefix: prefix, Router: receiver(c)}, true
+}
@@ -0,0 +1,255 @@
+package extract
+
+import (
+	"go/ast"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Type names of the proposed type, e.g. Page[model.Person]
+var typeNameRx = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*`)
+
+// Lines of the required tags, added into
+// the inferred endpoints if missing
+var inferDefaults = []string{
+	"produce json",
+	"success 200 {string} OK",
+}
+
+// routeBlocks of the routes registered without the
+// annotation, i.e. the statements not attached to a block
+func (e *extractor) routeBlocks(file string, f *ast.File, rt *routing, attached map[ast.Node]bool) []Block {
+	blocks := make([]Block, 0)
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if ok == false || fd.Body == nil {
+			continue
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			stmt, ok := n.(*ast.ExprStmt)
+			if ok == false || attached[stmt] {
+				return true
+			}
+			if route, ok := e.route(stmt, rt); ok {
+				blocks = append(blocks, Block{
+					File:    file,
+					Lines:   []string{},
+					Handler: rt.HandlerKey(route.Handler),
+					Route: &InferredRoute{
+						Path:    joinPath(rt.Prefix(route.Router, fd.Name.Name, 0), route.Path),
+						Methods: strings.Join(route.Methods, ", "),
+					},
+				})
+			}
+			return true
+		})
+	}
+	return blocks
+}
+
+// route registered by the statement, groups are not routes
+func (e *extractor) route(stmt ast.Node, rt *routing) (Route, bool) {
+	calls := e.chainCalls(stmt)
+	for _, r := range rt.routers {
+		if _, ok := r.Group(calls); ok {
+			return Route{}, false
+		}
+	}
+	for _, r := range rt.routers {
+		if route, ok := r.Route(calls); ok {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// funcBlocks of the functions without the annotation,
+// holding the lines proposed by the body analysis
+func (e *extractor) funcBlocks(file string, f *ast.File, rt *routing, attached map[ast.Node]bool) []Block {
+	blocks := make([]Block, 0)
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if ok == false || attached[fd] {
+			continue
+		}
+		if proposed := e.moduleTypes(file, e.schemas.Propose(fd), rt); len(proposed) > 0 {
+			blocks = append(blocks, Block{
+				File:     file,
+				Lines:    []string{},
+				Func:     declKey(rt.dir, fd.Name.Name),
+				Proposed: proposed,
+			})
+		}
+	}
+	return blocks
+}
+
+// Infer the endpoints of the routes without the annotation.
+// A route is documented by the annotation of the handler
+// function, or it is described by the route alone. The handler
+// annotations are consumed by the routes, except the annotations
+// with the router tag, which describe the endpoint on their own.
+// The lines proposed by the handler function body analysis are
+// added into the endpoints if missing.
+func (e *extractor) Infer(blocks []Block) []Block {
+	funcs := make(map[string]Block, 0)
+	for _, b := range blocks {
+		if _, ok := funcs[b.Func]; b.Func != "" && ok == false {
+			funcs[b.Func] = b
+		}
+	}
+
+	used := make(map[string]bool, 0)
+	routes := make(map[string]bool, 0)
+	inferred := make([]Block, 0)
+	for _, b := range blocks {
+		if b.Route == nil {
+			continue
+		}
+		// The same route extracted more than once
+		key := strings.ToLower(b.Route.Methods) + " " + b.Route.Path
+		if routes[key] {
+			continue
+		}
+		routes[key] = true
+
+		doc := Block{File: b.File, Lines: []string{}, Handler: b.Handler}
+		if h, ok := funcs[b.Handler]; ok {
+			if hasLine(h, "router ") {
+				continue
+			}
+			doc.File = h.File
+			doc.Lines = append(doc.Lines, h.Lines...)
+			used[h.Func] = true
+		}
+		if len(doc.Lines) == 0 && e.verbose {
+			log.Warnf("extracting: the handler \"%s\" of the route \"%s\" has no annotation, the endpoint is inferred from the route.", b.Handler, b.Route.Path)
+		}
+		inferred = append(inferred, e.routeLines(doc, b.Route.Path, b.Route.Methods))
+	}
+
+	// Reduce by the routes, and by the consumed, or
+	// not annotated, handler functions
+	reduced := make([]Block, 0, len(blocks)+len(inferred))
+	for _, b := range blocks {
+		if b.Route != nil || len(b.Lines) == 0 || (used[b.Func] && hasLine(b, "router ") == false) {
+			continue
+		}
+		reduced = append(reduced, b)
+	}
+	size := len(reduced)
+	reduced = append(reduced, inferred...)
+
+	for i := range reduced {
+		reduced[i] = e.propose(reduced[i], funcs)
+		// Required tags of the inferred endpoints
+		if i < size {
+			continue
+		}
+		for _, l := range inferDefaults {
+			if hasLine(reduced[i], strings.Fields(l)[0]+" ") == false {
+				reduced[i].Lines = append(reduced[i].Lines, l)
+			}
+		}
+	}
+	return reduced
+}
+
+// propose the missing lines of the handler function body analysis,
+// i.e. the body and the responses by the status code. The proposed
+// types are relative to the handler package, i.e. they are qualified
+// by the handler import if the handler is declared in another
+// package than the route.
+func (e *extractor) propose(b Block, funcs map[string]Block) Block {
+	proposed := b.Proposed
+	if b.Handler != "" {
+		h, ok := funcs[b.Handler]
+		if ok == false || len(h.Proposed) == 0 {
+			return b
+		}
+		proposed = h.Proposed
+		if absDir(h.File) != absDir(b.File) {
+			proposed = qualify(proposed, b.HandlerImport)
+		}
+	}
+
+	for _, l := range proposed {
+		chunks := strings.Fields(l)
+		prefix := chunks[0] + " "
+		if chunks[0] != "body" {
+			prefix += chunks[1] + " "
+		}
+		if hasLine(b, prefix) {
+			continue
+		}
+		b.Lines = append(b.Lines, l)
+		if chunks[0] == "body" && hasLine(b, "accept ") == false {
+			b.Lines = append(b.Lines, "accept json")
+		} else if chunks[0] != "body" && hasLine(b, "produce ") == false {
+			b.Lines = append(b.Lines, "produce json")
+		}
+	}
+	return b
+}
+
+// qualify the types of the proposed lines by the package
+// import name, the qualified, and the predeclared types
+// are kept, e.g. Page[Person] -> h.Page[h.Person]
+func qualify(proposed []string, pkg string) []string {
+	if pkg == "" || pkg == "." {
+		return proposed
+	}
+	qualified := make([]string, 0, len(proposed))
+	for _, l := range proposed {
+		chunks := strings.Fields(l)
+		if i := typeIndex(chunks); i < len(chunks) {
+			chunks[i] = typeNameRx.ReplaceAllStringFunc(chunks[i], func(name string) string {
+				if strings.Contains(name, ".") || predeclaredTypes[name] {
+					return name
+				}
+				return pkg + "." + name
+			})
+		}
+		qualified = append(qualified, strings.Join(chunks, " "))
+	}
+	return qualified
+}
+
+// moduleTypes of the proposed lines, i.e. the lines of the types
+// declared in the module: the types of the same package, or of the
+// packages imported under the module path. The other types, e.g.
+// url.Values, cannot be resolved, the lines are skipped.
+func (e *extractor) moduleTypes(file string, proposed []string, rt *routing) []string {
+	kept := make([]string, 0, len(proposed))
+	for _, l := range proposed {
+		chunks := strings.Fields(l)
+		skipped := ""
+		if i := typeIndex(chunks); i < len(chunks) {
+			for _, name := range typeNameRx.FindAllString(chunks[i], -1) {
+				if dot := strings.Index(name, "."); dot != -1 && rt.modulePkgs[name[:dot]] == false {
+					skipped = name
+					break
+				}
+			}
+		}
+		if skipped != "" {
+			if e.verbose {
+				log.Warnf("extracting: the type \"%s\" proposed in the file \"%s\" is not declared in the module, skipped", skipped, file)
+			}
+			continue
+		}
+		kept = append(kept, l)
+	}
+	return kept
+}
+
+// typeIndex of the type in the chunks of the proposed
+// line, e.g. body T, or success 200 {object} T OK
+func typeIndex(chunks []string) int {
+	if len(chunks) > 0 && chunks[0] == "body" {
+		return 1
+	}
+	return 3
+}
@@ -0,0 +1,304 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeModule of the test files, i.e. the go.mod file
+// locating the imported packages of the files
+func writeModule(t *testing.T, dir, path string) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+path+"\n"), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	writeModule(t, "tmp-infer", "example.com/app")
+	defer os.RemoveAll("tmp-infer")
+
+	content := []string{
+		`package main
+
+		import (
+			"net/http"
+
+			"github.com/gorilla/mux"
+			h "example.com/app/handlers"
+		)
+
+		func Handlers(r *mux.Router) {
+			sr := r.PathPrefix("/api").Subrouter()
+
+			// @summary Annotated
+			// @produce json
+			// @success 200 {string} OK
+			sr.HandleFunc("/annotated", Annotated)
+
+			sr.HandleFunc("/items/{id}", h.GetItem).Methods(http.MethodGet)
+			sr.HandleFunc("/items", http.HandlerFunc(CreateItem)).Methods("POST")
+			sr.HandleFunc("/items", CreateItem).Methods("POST")
+			sr.HandleFunc("/documented", Documented).Methods("PUT")
+			r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+		}
+
+		// CreateItem handler
+		// @summary Create
+		// @body Item
+		// @success 201 {string} Created
+		func CreateItem(w http.ResponseWriter, r *http.Request) {}
+
+		// Documented handler
+		// @summary Documented
+		// @produce json
+		// @success 200 {string} OK
+		// @router /documented [put]
+		func Documented(w http.ResponseWriter, r *http.Request) {}
+		`,
+		`package handlers
+
+		// GetItem handler
+		// @summary Item
+		// @param id path {int} true Item ID
+		// @produce json
+		// @success 200 {object} Item OK
+		func GetItem(w http.ResponseWriter, r *http.Request) {}
+		`,
+		// Routers not detected
+		`package other
+
+		func Handlers() {
+			r.HandleFunc("/other", Other)
+		}
+		`,
+	}
+
+	e := NewExtractor(false, nil, Inference{Endpoints: true}).(*extractor)
+	blocks := make([]Block, 0)
+	for i, c := range content {
+		b, err := e.parse(strings.NewReader(c), []string{"tmp-infer/main.go", "tmp-infer/handlers/handlers.go", "tmp-infer/other.go"}[i])
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		blocks = append(blocks, b...)
+	}
+
+	expected := []Block{
+		{
+			File:  "tmp-infer/main.go",
+			Lines: []string{"summary Annotated", "produce json", "success 200 {string} OK", "router /api/annotated [get]"},
+		},
+		{
+			File:  "tmp-infer/main.go",
+			Lines: []string{"summary Documented", "produce json", "success 200 {string} OK", "router /documented [put]"},
+		},
+		{
+			File:  "tmp-infer/handlers/handlers.go",
+			Lines: []string{"summary Item", "param id path {int} true Item ID", "produce json", "success 200 {object} Item OK", "router /api/items/{id} [get]"},
+		},
+		{
+			File:  "tmp-infer/main.go",
+			Lines: []string{"summary Create", "body Item", "success 201 {string} Created", "router /api/items [post]", "produce json"},
+		},
+		{
+			File:  "tmp-infer/main.go",
+			Lines: []string{"router /health [get]", "produce json", "success 200 {string} OK"},
+		},
+	}
+
+	inferred := e.Infer(blocks)
+	if len(inferred) != len(expected) {
+		t.Errorf("Expected %d blocks, got %d: %v", len(expected), len(inferred), inferred)
+		return
+	}
+	for i, b := range inferred {
+		if b.File != expected[i].File {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected[i].File, b.File)
+		}
+		if strings.Join(b.Lines, "\n") != strings.Join(expected[i].Lines, "\n") {
+			t.Errorf("Expected \"%v\", got \"%v\"", expected[i].Lines, b.Lines)
+		}
+	}
+}
+
+func TestInferSamePackageNames(t *testing.T) {
+	writeModule(t, "tmp-infer", "example.com/app")
+	defer os.RemoveAll("tmp-infer")
+
+	files := map[string]string{
+		"tmp-infer/main.go": `package main
+
+		import (
+			"github.com/gorilla/mux"
+			orders "example.com/app/orders/handler"
+			users "example.com/app/users/handler"
+		)
+
+		func Handlers(r *mux.Router) {
+			r.HandleFunc("/users", users.List).Methods("GET")
+			r.HandleFunc("/orders", orders.List).Methods("GET")
+		}
+		`,
+		"tmp-infer/users/handler/list.go": `package handler
+
+		// List users
+		// @summary List users
+		func List(w http.ResponseWriter, r *http.Request) {}
+		`,
+		"tmp-infer/orders/handler/list.go": `package handler
+
+		// List orders
+		// @summary List orders
+		func List(w http.ResponseWriter, r *http.Request) {}
+		`,
+	}
+
+	e := NewExtractor(false, nil, Inference{Endpoints: true}).(*extractor)
+	blocks := make([]Block, 0)
+	for _, file := range []string{"tmp-infer/main.go", "tmp-infer/users/handler/list.go", "tmp-infer/orders/handler/list.go"} {
+		b, err := e.parse(strings.NewReader(files[file]), file)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		blocks = append(blocks, b...)
+	}
+
+	expected := []Block{
+		{
+			File:  "tmp-infer/users/handler/list.go",
+			Lines: []string{"summary List users", "router /users [get]", "produce json", "success 200 {string} OK"},
+		},
+		{
+			File:  "tmp-infer/orders/handler/list.go",
+			Lines: []string{"summary List orders", "router /orders [get]", "produce json", "success 200 {string} OK"},
+		},
+	}
+
+	inferred := e.Infer(blocks)
+	if len(inferred) != len(expected) {
+		t.Errorf("Expected %d blocks, got %d: %v", len(expected), len(inferred), inferred)
+		return
+	}
+	for i, b := range inferred {
+		if b.File != expected[i].File {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected[i].File, b.File)
+		}
+		if strings.Join(b.Lines, "\n") != strings.Join(expected[i].Lines, "\n") {
+			t.Errorf("Expected \"%v\", got \"%v\"", expected[i].Lines, b.Lines)
+		}
+	}
+}
+
+func TestInferReplacedModule(t *testing.T) {
+	writeModule(t, "tmp-infer/lib", "example.com/go-lib")
+	defer os.RemoveAll("tmp-infer")
+	err := os.MkdirAll("tmp-infer/app", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	mod := "module example.com/app\n\nrequire example.com/go-lib v1.0.0\n\nreplace example.com/go-lib => ../lib\n"
+	err = ioutil.WriteFile("tmp-infer/app/go.mod", []byte(mod), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	// The handler of the replaced module,
+	// imported by the guessed package name
+	files := map[string]string{
+		"tmp-infer/app/main.go": `package main
+
+		import (
+			"github.com/gorilla/mux"
+			"example.com/go-lib"
+		)
+
+		func Handlers(r *mux.Router) {
+			r.HandleFunc("/users", lib.List).Methods("GET")
+		}
+		`,
+		"tmp-infer/lib/list.go": `package lib
+
+		// List users
+		// @summary List users
+		func List(w http.ResponseWriter, r *http.Request) {}
+		`,
+	}
+
+	e := NewExtractor(false, nil, Inference{Endpoints: true}).(*extractor)
+	blocks := make([]Block, 0)
+	for _, file := range []string{"tmp-infer/app/main.go", "tmp-infer/lib/list.go"} {
+		b, err := e.parse(strings.NewReader(files[file]), file)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		blocks = append(blocks, b...)
+	}
+
+	expected := []string{"summary List users", "router /users [get]", "produce json", "success 200 {string} OK"}
+	inferred := e.Infer(blocks)
+	if len(inferred) != 1 {
+		t.Errorf("Expected %d blocks, got %d: %v", 1, len(inferred), inferred)
+		return
+	}
+	if strings.Join(inferred[0].Lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected \"%v\", got \"%v\"", expected, inferred[0].Lines)
+	}
+}
+
+func TestHandlerKey(t *testing.T) {
+	content := `package main
+
+	import (
+		"net/http"
+
+		"github.com/labstack/echo/v4"
+		p "github.com/example/person"
+	)
+
+	func Handlers(e *echo.Echo, s *Server) {
+		e.GET("/a", List)
+		e.GET("/b", p.Get)
+		e.GET("/c", s.Delete)
+		e.GET("/d", NewHandler(db))
+		e.GET("/e", echo.WrapHandler(http.HandlerFunc(Wrapped)))
+		e.GET("/f", func(c echo.Context) error { return nil })
+	}
+	`
+
+	e := NewExtractor(false, nil, Inference{Endpoints: true}).(*extractor)
+	blocks, err := e.parse(strings.NewReader(content), "main.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	dir := absDir("main.go")
+	expected := []string{dir + ".List", "github.com/example/person.Get", dir + ".Delete", dir + ".NewHandler", dir + ".Wrapped", ""}
+	if len(blocks) != len(expected) {
+		t.Errorf("Expected %d blocks, got %d: %v", len(expected), len(blocks), blocks)
+		return
+	}
+	for i, b := range blocks {
+		if b.Route == nil {
+			t.Errorf("Expected the inferred route, got %v", b)
+			continue
+		}
+		if b.Handler != expected[i] {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], b.Handler)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package extract
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spaceavocado/apidoc/loader"
+)
+
+// pkgDir of the imported package, resolved through the go.mod
+// of the module containing the folder, i.e. the packages of the
+// module, the vendored, the replaced, or the cached modules.
+// False if the package cannot be located.
+func (e *extractor) pkgDir(pkg, dir string) (string, bool) {
+	m := e.module(dir)
+	if m == nil {
+		return "", false
+	}
+	return m.PkgDir(pkg, e.modcache)
+}
+
+// inModule checks if the imported package is declared in the
+// module containing the folder. Outside of a module, the packages
+// of the standard library, i.e. without a dot in the first path
+// element, are not declared in the project.
+func (e *extractor) inModule(pkg, dir string) bool {
+	if m := e.module(dir); m != nil {
+		return m.Contains(pkg)
+	}
+	return strings.Contains(strings.Split(pkg, "/")[0], ".")
+}
+
+// module containing the folder, nil if not found
+func (e *extractor) module(dir string) *loader.Module {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, err := loader.FindModule(dir, e.modules)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// absDir of the file, i.e. the folder keying
+// the functions declared in the file
+func absDir(file string) string {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return filepath.Dir(file)
+	}
+	return dir
+}
@@ -0,0 +1,377 @@
+package extract
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/spaceavocado/apidoc/loader"
+)
+
+// Route registered on a router
+type Route struct {
+	// Path of the route, the path params in the {name} format
+	Path string
+	// Methods of the route, empty for any method
+	Methods []string
+	// Router the route is registered on
+	Router ast.Expr
+	// Handler of the route, nil if not found
+	Handler ast.Expr
+}
+
+// Group of routes sharing the path prefix, i.e. a subrouter
+type Group struct {
+	// Prefix of the group routes, the path params in the {name} format
+	Prefix string
+	// Router the group is created from
+	Router ast.Expr
+	// Target router of the group, e.g. the function literal
+	// of the chi Route. Nil if the group is assigned into
+	// a variable, e.g. g := e.Group("/admin")
+	Target ast.Expr
+}
+
+// Router dialect, i.e. the routing API of a router package
+type Router interface {
+	// Imports checks if the import path is the router package
+	Imports(path string) bool
+	// Route registered by the statement calls, by the method name
+	Route(calls map[string]*ast.CallExpr) (Route, bool)
+	// Group created by the statement calls, by the method name
+	Group(calls map[string]*ast.CallExpr) (Group, bool)
+}
+
+// DefaultRouters supported by the extractor,
+// i.e. gorilla/mux, chi, echo, gin and net/http ServeMux
+func DefaultRouters() []Router {
+	return []Router{
+		&gorillaMux{},
+		&chiRouter{},
+		&echoRouter{},
+		&ginRouter{},
+		&serveMux{},
+	}
+}
+
+// Handler adapters of the routers, e.g. http.HandlerFunc(h)
+var handlerAdapters = map[string]bool{
+	"HandlerFunc":     true,
+	"WrapHandler":     true,
+	"WrapHandlerFunc": true,
+	"WrapH":           true,
+	"WrapF":           true,
+}
+
+// HTTP methods
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
+// Function key of the groups mounted by a function,
+// e.g. r.Mount("/admin", adminRouter())
+type funcKey string
+
+// Group found in the function
+type group struct {
+	Group
+	fn string
+}
+
+// Routing of a file, i.e. the routers
+// used in the file and the route groups
+// by the target router
+type routing struct {
+	routers []Router
+	// Routers detected by the file imports
+	detected bool
+	groups   map[interface{}]group
+	// Folder of the file, keying the functions of the package
+	dir string
+	// Folders of the imported packages, by the import name,
+	// the import path if the folder is not resolved
+	imports map[string]string
+	// Import names of the packages declared in the module
+	modulePkgs map[string]bool
+}
+
+// Prefix of the router, resolved recursively through the parent
+// groups. A router which is not a group takes the prefix of the
+// group mounting the function. The cycling is soft-locked on 10
+// inner jumps.
+func (r *routing) Prefix(router ast.Expr, fn string, depth int) string {
+	if depth >= 10 {
+		return ""
+	}
+	g, ok := group{}, false
+	if id := rootIdent(router); id != nil && id.Obj != nil {
+		g, ok = r.groups[id.Obj]
+	}
+	if ok == false {
+		g, ok = r.groups[funcKey(fn)]
+	}
+	if ok == false {
+		return ""
+	}
+	return joinPath(r.Prefix(g.Router, g.fn, depth+1), g.Prefix)
+}
+
+// routing of the file. The routers are detected
+// by the file imports, all routers are used if
+// none of them is imported. The imported packages
+// are located within the module of the file.
+func (e *extractor) routing(file string, f *ast.File) *routing {
+	rt := &routing{
+		routers:    make([]Router, 0),
+		groups:     make(map[interface{}]group, 0),
+		dir:        absDir(file),
+		imports:    make(map[string]string, 0),
+		modulePkgs: make(map[string]bool, 0),
+	}
+	for _, imp := range f.Imports {
+		path, ok := unquote(imp.Path.Value)
+		if ok == false {
+			continue
+		}
+		dir, ok := e.pkgDir(path, rt.dir)
+		if ok == false {
+			dir = path
+		}
+		name := loader.ImportName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		rt.imports[name] = dir
+		rt.modulePkgs[name] = e.inModule(path, rt.dir)
+	}
+	for _, r := range e.routers {
+		for _, imp := range f.Imports {
+			if path, ok := unquote(imp.Path.Value); ok && r.Imports(path) {
+				rt.routers = append(rt.routers, r)
+				break
+			}
+		}
+	}
+	rt.detected = len(rt.routers) > 0
+	if rt.detected == false {
+		rt.routers = e.routers
+	}
+
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if ok == false || fd.Body == nil {
+			continue
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.AssignStmt, *ast.ExprStmt:
+			default:
+				return true
+			}
+			calls := e.chainCalls(n)
+			for _, r := range rt.routers {
+				if g, ok := r.Group(calls); ok {
+					if key := groupKey(g.Target, n); key != nil {
+						rt.groups[key] = group{Group: g, fn: fd.Name.Name}
+					}
+					break
+				}
+			}
+			return true
+		})
+	}
+	return rt
+}
+
+// HandlerKey of the handler function, i.e. "dir.Name" by the folder
+// of the package declaring it. Method values are looked up in the
+// package of the file, handler adapters and factories are followed
+// to the function, e.g. http.HandlerFunc(h), echo.WrapHandler(h),
+// or NewHandler(db).
+func (r *routing) HandlerKey(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return declKey(r.dir, t.Name)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Obj == nil {
+			if dir, ok := r.imports[x.Name]; ok {
+				return declKey(dir, t.Sel.Name)
+			}
+		}
+		return declKey(r.dir, t.Sel.Name)
+	case *ast.CallExpr:
+		if sel, ok := t.Fun.(*ast.SelectorExpr); ok && handlerAdapters[sel.Sel.Name] && len(t.Args) == 1 {
+			return r.HandlerKey(t.Args[0])
+		}
+		return r.HandlerKey(t.Fun)
+	case *ast.ParenExpr:
+		return r.HandlerKey(t.X)
+	}
+	return ""
+}
+
+// HandlerImport name of the package declaring the handler
+// function, empty if the handler is declared in the package
+// of the file, see the HandlerKey
+func (r *routing) HandlerImport(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Obj == nil {
+			if _, ok := r.imports[x.Name]; ok {
+				return x.Name
+			}
+		}
+	case *ast.CallExpr:
+		if sel, ok := t.Fun.(*ast.SelectorExpr); ok && handlerAdapters[sel.Sel.Name] && len(t.Args) == 1 {
+			return r.HandlerImport(t.Args[0])
+		}
+		return r.HandlerImport(t.Fun)
+	case *ast.ParenExpr:
+		return r.HandlerImport(t.X)
+	}
+	return ""
+}
+
+// declKey of the function declared in the package
+// of the folder, i.e. "dir.Name"
+func declKey(dir, name string) string {
+	return dir + "." + name
+}
+
+// groupKey of the group target router, i.e. the
+// variable object, or the function mounting it
+func groupKey(target ast.Expr, stmt ast.Node) interface{} {
+	if target == nil {
+		if a, ok := stmt.(*ast.AssignStmt); ok && len(a.Lhs) == 1 {
+			target = a.Lhs[0]
+		}
+	}
+	switch t := target.(type) {
+	case *ast.Ident:
+		if t.Obj != nil && t.Obj.Kind != ast.Fun {
+			return t.Obj
+		}
+		return funcKey(t.Name)
+	case *ast.FuncLit:
+		params := t.Type.Params.List
+		if len(params) > 0 && len(params[0].Names) > 0 && params[0].Names[0].Obj != nil {
+			return params[0].Names[0].Obj
+		}
+	case *ast.CallExpr:
+		switch fun := t.Fun.(type) {
+		case *ast.Ident:
+			return funcKey(fun.Name)
+		case *ast.SelectorExpr:
+			return funcKey(fun.Sel.Name)
+		}
+	}
+	return nil
+}
+
+// rootIdent of the router expression,
+// e.g. r of r.With(mw).Route
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			return t
+		case *ast.SelectorExpr:
+			expr = t.X
+		case *ast.CallExpr:
+			expr = t.Fun
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.StarExpr:
+			expr = t.X
+		default:
+			return nil
+		}
+	}
+}
+
+// receiver of the method call
+func receiver(c *ast.CallExpr) ast.Expr {
+	if sel, ok := c.Fun.(*ast.SelectorExpr); ok {
+		return sel.X
+	}
+	return nil
+}
+
+// joinPath of the group prefix and the route path
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// verbRoute registered by the method named by the HTTP
+// method, e.g. r.Get("/x", h), the name by the dialect.
+// The handler is the last argument if last is set.
+func verbRoute(calls map[string]*ast.CallExpr, name func(method string) string, last bool) (Route, bool) {
+	for _, m := range httpMethods {
+		if c, ok := calls[name(m)]; ok {
+			if path, ok := stringArg(c, 0); ok {
+				return Route{Path: path, Methods: []string{m}, Router: receiver(c), Handler: handlerArg(c, 1, last)}, true
+			}
+		}
+	}
+	return Route{}, false
+}
+
+// handlerArg of the call, at the index,
+// or the last one if last is set
+func handlerArg(c *ast.CallExpr, i int, last bool) ast.Expr {
+	if i >= len(c.Args) {
+		return nil
+	}
+	if last {
+		return c.Args[len(c.Args)-1]
+	}
+	return c.Args[i]
+}
+
+// methodArg of the call, at the index, given as a string
+// literal, or as a method constant, e.g. http.MethodGet
+func methodArg(c *ast.CallExpr, i int) (string, bool) {
+	if i >= len(c.Args) {
+		return "", false
+	}
+	return method(c.Args[i])
+}
+
+// method given as a string literal, or as
+// a method constant, e.g. http.MethodGet
+func method(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.BasicLit:
+		return unquote(t.Value)
+	case *ast.SelectorExpr:
+		name := strings.ToUpper(strings.TrimPrefix(t.Sel.Name, "Method"))
+		for _, m := range httpMethods {
+			if m == name {
+				return m, true
+			}
+		}
+	}
+	return "", false
+}
+
+// methodsArg of the call, at the index,
+// given as a slice literal, e.g. []string{"GET"}
+func methodsArg(c *ast.CallExpr, i int) ([]string, bool) {
+	if i >= len(c.Args) {
+		return nil, false
+	}
+	lit, ok := c.Args[i].(*ast.CompositeLit)
+	if ok == false {
+		return nil, false
+	}
+	methods := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		if m, ok := method(elt); ok {
+			methods = append(methods, m)
+		}
+	}
+	return methods, true
+}
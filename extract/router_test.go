@@ -0,0 +1,233 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouters(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected [][]string
+	}{
+		{
+			name: "gorilla/mux",
+			content: `package test
+
+			import (
+				"net/http"
+
+				"github.com/gorilla/mux"
+			)
+
+			func Handlers(r *mux.Router) {
+				sa := r.PathPrefix("/animal").Subrouter()
+				sc := sa.PathPrefix("/cat").Subrouter()
+
+				// @summary List
+				sc.HandleFunc("/list/{id:[0-9]+}", List).Methods(http.MethodGet, "POST")
+
+				// @summary Path
+				sa.Path("/dog").HandlerFunc(Dog)
+
+				// @summary Manual
+				// @subrouter cats
+				sc.HandleFunc("/manual", Manual)
+			}
+			`,
+			expected: [][]string{
+				{"summary List", "router /animal/cat/list/{id} [get, post]", "param id path {string} true"},
+				{"summary Path", "router /animal/dog [get]"},
+				{"summary Manual", "subrouter cats", "router /manual [get]"},
+			},
+		},
+		{
+			name: "chi",
+			content: `package test
+
+			import "github.com/go-chi/chi/v5"
+
+			func Handlers(r chi.Router) {
+				r.Route("/users", func(r chi.Router) {
+					// @summary User
+					r.With(Auth).Get("/{id}", GetUser)
+
+					r.Group(func(r chi.Router) {
+						// @summary Delete
+						r.Method(http.MethodDelete, "/{id}", DeleteUser)
+					})
+				})
+				r.Mount("/admin", adminRouter())
+
+				// @summary Any
+				r.HandleFunc("/any", Any)
+			}
+
+			func adminRouter() http.Handler {
+				r := chi.NewRouter()
+				// @summary Stats
+				r.Post("/stats", Stats)
+				return r
+			}
+			`,
+			expected: [][]string{
+				{"summary User", "router /users/{id} [get]", "param id path {string} true"},
+				{"summary Delete", "router /users/{id} [delete]", "param id path {string} true"},
+				{"summary Any", "router /any [get]"},
+				{"summary Stats", "router /admin/stats [post]"},
+			},
+		},
+		{
+			name: "echo",
+			content: `package test
+
+			import "github.com/labstack/echo/v4"
+
+			func Handlers(e *echo.Echo) {
+				g := e.Group("/admin")
+				// @summary User
+				g.GET("/users/:id", GetUser)
+
+				// @summary Match
+				e.Match([]string{"PUT", http.MethodPatch}, "/items/:id", UpdateItem)
+
+				// @router admin
+				a := e.Group("/admin")
+			}
+			`,
+			expected: [][]string{
+				{"summary User", "router /admin/users/{id} [get]", "param id path {string} true"},
+				{"summary Match", "router /items/{id} [put, patch]", "param id path {string} true"},
+				{"router admin", "routerurl /admin"},
+			},
+		},
+		{
+			name: "gin",
+			content: `package test
+
+			import "github.com/gin-gonic/gin"
+
+			func Handlers(r *gin.Engine) {
+				v1 := r.Group("/v1")
+				{
+					// @summary Create
+					v1.POST("/users", CreateUser)
+
+					// @summary Files
+					v1.Handle("GET", "/files/*path", Files)
+				}
+			}
+			`,
+			expected: [][]string{
+				{"summary Create", "router /v1/users [post]"},
+				{"summary Files", "router /v1/files/{path} [get]", "param path path {string} true"},
+			},
+		},
+		{
+			name: "net/http ServeMux",
+			content: `package test
+
+			import "net/http"
+
+			func Handlers() {
+				api := http.NewServeMux()
+				mux := http.NewServeMux()
+				mux.Handle("/api/", http.StripPrefix("/api", api))
+
+				// @summary Item
+				api.HandleFunc("GET example.com/items/{id}", GetItem)
+
+				// @summary Files
+				http.Handle("/files/{path...}", Files)
+			}
+			`,
+			expected: [][]string{
+				{"summary Item", "router /api/items/{id} [get]", "param id path {string} true"},
+				{"summary Files", "router /files/{path} [get]", "param path path {string} true"},
+			},
+		},
+	}
+
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
+	for _, test := range tests {
+		blocks, err := e.parse(strings.NewReader(test.content), "test.go")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if len(blocks) != len(test.expected) {
+			t.Errorf("%s: expected %d blocks, got %d: %v", test.name, len(test.expected), len(blocks), blocks)
+			continue
+		}
+		for i, b := range blocks {
+			if strings.Join(b.Lines, "\n") != strings.Join(test.expected[i], "\n") {
+				t.Errorf("%s: expected \"%v\", got \"%v\"", test.name, test.expected[i], b.Lines)
+			}
+		}
+	}
+}
+
+func TestRouterDetection(t *testing.T) {
+	content := `package test
+
+	import "github.com/labstack/echo/v4"
+
+	func Handlers(e *echo.Echo) {
+		// @summary Ignored
+		e.HandleFunc("/ignored", Ignored)
+	}
+	`
+
+	e := NewExtractor(false, nil, Inference{}).(*extractor)
+	blocks, err := e.parse(strings.NewReader(content), "test.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if len(blocks) != 1 || len(blocks[0].Lines) != 1 {
+		t.Errorf("Expected the route to be ignored, got %v", blocks)
+	}
+
+	// Custom routers
+	e = NewExtractor(false, nil, Inference{}, &serveMux{}).(*extractor)
+	if len(e.routers) != 1 {
+		t.Errorf("Expected %d routers, got %d", 1, len(e.routers))
+	}
+}
+
+func TestServeMuxPattern(t *testing.T) {
+	tests := [][]string{
+		{"/items", "", "/items"},
+		{"GET /items/{id}", "GET", "/items/{id}"},
+		{"POST\texample.com/items/{$}", "POST", "/items/"},
+		{"/files/{path...}", "", "/files/{path}"},
+		{"example.com", "", ""},
+	}
+
+	r := &serveMux{}
+	for _, test := range tests {
+		m, path := r.Pattern(test[0])
+		if m != test[1] {
+			t.Errorf("Expected \"%s\", got \"%s\"", test[1], m)
+		}
+		if path != test[2] {
+			t.Errorf("Expected \"%s\", got \"%s\"", test[2], path)
+		}
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := [][]string{
+		{"", "/x", "/x"},
+		{"/admin", "", "/admin"},
+		{"/admin", "/x", "/admin/x"},
+		{"/admin/", "/x", "/admin/x"},
+		{"/admin", "x", "/admin/x"},
+	}
+	for _, test := range tests {
+		if path := joinPath(test[0], test[1]); path != test[2] {
+			t.Errorf("Expected \"%s\", got \"%s\"", test[2], path)
+		}
+	}
+}
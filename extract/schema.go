@@ -0,0 +1,360 @@
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Default decode helpers, i.e. the request body binding of gin and echo,
+// the json.NewDecoder(r.Body).Decode(&v) is supported out of the box
+var defaultDecodeFuncs = []string{"Bind", "BindJSON", "ShouldBind", "ShouldBindJSON", "json.Unmarshal"}
+
+// Default encode helpers, i.e. the JSON response of gin and echo,
+// the json.NewEncoder(w).Encode(v) is supported out of the box
+var defaultEncodeFuncs = []string{"JSON", "IndentedJSON"}
+
+// Status code constants of the net/http package, by the name
+var statusCodes = func() map[string]int {
+	codes := map[string]int{
+		"StatusNonAuthoritativeInfo": http.StatusNonAuthoritativeInfo,
+		"StatusTeapot":               http.StatusTeapot,
+	}
+	clean := strings.NewReplacer(" ", "", "-", "", "'", "")
+	for c := 100; c < 600; c++ {
+		if text := http.StatusText(c); text != "" {
+			codes["Status"+clean.Replace(text)] = c
+		}
+	}
+	return codes
+}()
+
+// Predeclared types, not proposed as the schemas
+var predeclaredTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "rune": true, "string": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true,
+}
+
+// Encode helper, optionally with the status code, e.g. response.Error=500
+type encodeFunc struct {
+	name string
+	code int
+}
+
+// Schema analysis of the handler functions
+type schemas struct {
+	decode []string
+	encode []encodeFunc
+}
+
+// newSchemas of the default helpers and the project helpers
+func newSchemas(decode, encode []string) *schemas {
+	s := &schemas{
+		decode: append(append([]string{}, defaultDecodeFuncs...), decode...),
+		encode: make([]encodeFunc, 0),
+	}
+	for _, name := range append(append([]string{}, defaultEncodeFuncs...), encode...) {
+		f := encodeFunc{name: name}
+		if i := strings.Index(name, "="); i != -1 {
+			if code, err := strconv.Atoi(name[i+1:]); err == nil {
+				f = encodeFunc{name: name[:i], code: code}
+			}
+		}
+		s.encode = append(s.encode, f)
+	}
+	return s
+}
+
+// Propose the body and the response lines of the handler function,
+// i.e. the decoded request body and the encoded responses found in
+// the function body. Only the types resolved statically from the
+// function, e.g. the composite literals and the declared variables,
+// are proposed.
+func (s *schemas) Propose(fd *ast.FuncDecl) []string {
+	lines := make([]string, 0)
+	if fd.Body == nil {
+		return lines
+	}
+	locals := s.Locals(fd)
+	body := ""
+	codes := make(map[int]bool, 0)
+	header := 0
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		c, ok := n.(*ast.CallExpr)
+		if ok == false {
+			return true
+		}
+		name := callName(c)
+
+		// w.WriteHeader(code) followed by the encoder
+		if strings.HasSuffix(name, "WriteHeader") && len(c.Args) == 1 {
+			if code, ok := statusArg(c.Args[0]); ok {
+				header = code
+			}
+			return true
+		}
+
+		// Request body
+		if target, ok := s.Decoded(c, name); ok && body == "" {
+			body = s.TypeOf(target, locals, 0)
+			if body != "" {
+				lines = append(lines, fmt.Sprintf("body %s", body))
+			}
+			return true
+		}
+
+		// Response
+		value, code, ok := s.Encoded(c, name)
+		if ok == false {
+			return true
+		}
+		if code == 0 {
+			code = header
+		}
+		if code == 0 {
+			code = http.StatusOK
+		}
+		t := s.TypeOf(value, locals, 0)
+		if t == "" || codes[code] {
+			return true
+		}
+		codes[code] = true
+		kind := "success"
+		if code >= 400 {
+			kind = "failure"
+		}
+		lines = append(lines, fmt.Sprintf("%s %d {object} %s %s", kind, code, t, http.StatusText(code)))
+		return true
+	})
+	return lines
+}
+
+// Decoded value of the decode call, i.e. the target of
+// json.NewDecoder(r.Body).Decode(&v), or of a decode
+// helper, given as the first address argument
+func (s *schemas) Decoded(c *ast.CallExpr, name string) (ast.Expr, bool) {
+	if strings.HasSuffix(name, "Decode") && strings.HasSuffix(callName(receiverCall(c)), "NewDecoder") {
+		return handlerArg(c, 0, false), len(c.Args) == 1
+	}
+	if matchHelper(name, s.decode) == false || len(c.Args) == 0 {
+		return nil, false
+	}
+	for _, a := range c.Args {
+		if u, ok := a.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			return u, true
+		}
+	}
+	return c.Args[len(c.Args)-1], true
+}
+
+// Encoded value, and the status code, of the encode call,
+// i.e. json.NewEncoder(w).Encode(v), or of an encode helper.
+// The value is the last argument of the helper, the status
+// code is the first status argument, or the code of the helper.
+func (s *schemas) Encoded(c *ast.CallExpr, name string) (ast.Expr, int, bool) {
+	if strings.HasSuffix(name, "Encode") && strings.HasSuffix(callName(receiverCall(c)), "NewEncoder") {
+		return handlerArg(c, 0, false), 0, len(c.Args) == 1
+	}
+	for _, f := range s.encode {
+		if matchHelper(name, []string{f.name}) == false || len(c.Args) == 0 {
+			continue
+		}
+		code := f.code
+		for _, a := range c.Args[:len(c.Args)-1] {
+			if v, ok := statusArg(a); ok {
+				code = v
+				break
+			}
+		}
+		return c.Args[len(c.Args)-1], code, true
+	}
+	return nil, 0, false
+}
+
+// Local variable of the handler function, declared
+// by the type, e.g. var v T, or by the value, e.g. v := T{}
+type local struct {
+	expr   ast.Expr
+	isType bool
+}
+
+// Locals of the function, i.e. the params
+// and the variables declared in the function
+func (s *schemas) Locals(fd *ast.FuncDecl) map[string]local {
+	locals := make(map[string]local, 0)
+	if fd.Type.Params != nil {
+		for _, p := range fd.Type.Params.List {
+			for _, n := range p.Names {
+				locals[n.Name] = local{expr: p.Type, isType: true}
+			}
+		}
+	}
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range t.Names {
+				if t.Type != nil {
+					locals[name.Name] = local{expr: t.Type, isType: true}
+				} else if i < len(t.Values) {
+					locals[name.Name] = local{expr: t.Values[i]}
+				}
+			}
+		case *ast.AssignStmt:
+			if t.Tok != token.DEFINE || len(t.Lhs) != len(t.Rhs) {
+				return true
+			}
+			for i, l := range t.Lhs {
+				if id, ok := l.(*ast.Ident); ok {
+					locals[id.Name] = local{expr: t.Rhs[i]}
+				}
+			}
+		}
+		return true
+	})
+	return locals
+}
+
+// TypeOf the value expression, resolved through the locals.
+// Empty if the type cannot be resolved statically, or if
+// it is a predeclared type. The resolving is soft-locked
+// on 10 inner jumps.
+func (s *schemas) TypeOf(expr ast.Expr, locals map[string]local, depth int) string {
+	if depth >= 10 {
+		return ""
+	}
+	switch t := expr.(type) {
+	case *ast.UnaryExpr:
+		return s.TypeOf(t.X, locals, depth+1)
+	case *ast.StarExpr:
+		return s.TypeOf(t.X, locals, depth+1)
+	case *ast.ParenExpr:
+		return s.TypeOf(t.X, locals, depth+1)
+	case *ast.CompositeLit:
+		return schemaType(t.Type)
+	case *ast.CallExpr:
+		if id, ok := t.Fun.(*ast.Ident); ok && id.Name == "new" && len(t.Args) == 1 {
+			return schemaType(t.Args[0])
+		}
+	case *ast.Ident:
+		if l, ok := locals[t.Name]; ok {
+			if l.isType {
+				return schemaType(l.expr)
+			}
+			return s.TypeOf(l.expr, locals, depth+1)
+		}
+	}
+	return ""
+}
+
+// schemaType of the type expression, empty
+// if not supported, or if it is predeclared
+func schemaType(expr ast.Expr) string {
+	name := typeString(expr)
+	if predeclaredTypes[strings.TrimLeft(name, "[]")] {
+		return ""
+	}
+	return name
+}
+
+// typeString of the type expression, empty if not supported,
+// e.g. map types, or the anonymous structs
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return x.Name + "." + t.Sel.Name
+		}
+	case *ast.StarExpr:
+		return typeString(t.X)
+	case *ast.ArrayType:
+		if elem := typeString(t.Elt); elem != "" && t.Len == nil {
+			return "[]" + elem
+		}
+	case *ast.IndexExpr:
+		base, arg := typeString(t.X), typeString(t.Index)
+		if base != "" && arg != "" {
+			return base + "[" + arg + "]"
+		}
+	case *ast.IndexListExpr:
+		args := make([]string, 0, len(t.Indices))
+		for _, i := range t.Indices {
+			arg := typeString(i)
+			if arg == "" {
+				return ""
+			}
+			args = append(args, arg)
+		}
+		if base := typeString(t.X); base != "" {
+			return base + "[" + strings.Join(args, ",") + "]"
+		}
+	}
+	return ""
+}
+
+// callName of the called function as written,
+// e.g. response.JSON, c.JSON, or JSON
+func callName(c *ast.CallExpr) string {
+	if c == nil {
+		return ""
+	}
+	switch fun := c.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return x.Name + "." + fun.Sel.Name
+		}
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// receiverCall of the method call, e.g.
+// json.NewDecoder(r.Body) of the Decode call
+func receiverCall(c *ast.CallExpr) *ast.CallExpr {
+	if r, ok := receiver(c).(*ast.CallExpr); ok {
+		return r
+	}
+	return nil
+}
+
+// matchHelper checks if the called function matches any of the
+// helpers. A helper without the package, e.g. JSON, matches
+// the function, or the method, of the name.
+func matchHelper(name string, helpers []string) bool {
+	short := name
+	if i := strings.LastIndex(name, "."); i != -1 {
+		short = name[i+1:]
+	}
+	for _, h := range helpers {
+		if h == name || (strings.Contains(h, ".") == false && h == short) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusArg of the status code given as an integer
+// literal, or as a status constant, e.g. http.StatusOK
+func statusArg(expr ast.Expr) (int, bool) {
+	switch t := expr.(type) {
+	case *ast.BasicLit:
+		if t.Kind != token.INT {
+			return 0, false
+		}
+		code, err := strconv.Atoi(t.Value)
+		return code, err == nil && code >= 100 && code < 600
+	case *ast.SelectorExpr:
+		code, ok := statusCodes[t.Sel.Name]
+		return code, ok
+	}
+	return 0, false
+}
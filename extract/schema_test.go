@@ -0,0 +1,185 @@
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPropose(t *testing.T) {
+	content := `package test
+
+	func Create(w http.ResponseWriter, r *http.Request) {
+		var req request.Create
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, APIError{Message: err.Error()})
+			return
+		}
+		p := &Person{Name: req.Name}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	}
+
+	func List(c *gin.Context) {
+		items := new([]Item)
+		c.ShouldBindJSON(&Filter{})
+		c.JSON(http.StatusOK, response.Page[Item]{})
+		c.JSON(200, items)
+		c.JSON(http.StatusNotFound, "not found")
+	}
+
+	func Update(w http.ResponseWriter, r *http.Request) {
+		var model Person
+		request.ParseJSONBody(r.Body, &model)
+		response.JSON(w, 202, map[string]string{})
+		response.JSON(w, 200, model)
+	}
+
+	func Unknown(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, 200, load())
+	}
+	`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", content, 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	expected := [][]string{
+		{
+			"body request.Create",
+			"failure 500 {object} APIError Internal Server Error",
+			"success 201 {object} Person Created",
+		},
+		{
+			"body Filter",
+			"success 200 {object} response.Page[Item] OK",
+		},
+		{
+			"body Person",
+			"success 200 {object} Person OK",
+		},
+		{},
+	}
+
+	s := newSchemas([]string{"request.ParseJSONBody"}, []string{"response.JSON", "response.Error=500"})
+	for i, d := range f.Decls {
+		lines := s.Propose(d.(*ast.FuncDecl))
+		if strings.Join(lines, "\n") != strings.Join(expected[i], "\n") {
+			t.Errorf("Expected \"%v\", got \"%v\"", expected[i], lines)
+		}
+	}
+}
+
+func TestStatusArg(t *testing.T) {
+	tests := map[string]int{
+		"200":                             200,
+		"http.StatusOK":                   200,
+		"http.StatusNonAuthoritativeInfo": 203,
+		"http.StatusTeapot":               418,
+		"http.StatusRequestURITooLong":    414,
+		"http.StatusNotFound":             404,
+	}
+	for src, code := range tests {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if c, ok := statusArg(expr); ok == false || c != code {
+			t.Errorf("Expected %d, got %d", code, c)
+		}
+	}
+
+	for _, src := range []string{"99", "x", "http.Status", "\"200\""} {
+		expr, _ := parser.ParseExpr(src)
+		if _, ok := statusArg(expr); ok {
+			t.Errorf("Expected the invalid status code \"%s\"", src)
+		}
+	}
+}
+
+func TestInferSchemas(t *testing.T) {
+	writeModule(t, "tmp-schemas", "example.com/app")
+	defer os.RemoveAll("tmp-schemas")
+
+	content := []string{
+		`package person
+
+		import (
+			"net/url"
+
+			"github.com/gorilla/mux"
+			"example.com/app/other"
+		)
+
+		func Handlers(r *mux.Router) {
+			// @summary Person
+			// @success 404 {string} Not Found
+			r.HandleFunc("/person", GetPerson).Methods("GET")
+
+			// @summary Other
+			r.HandleFunc("/other", other.Handler).Methods("GET")
+		}
+
+		func GetPerson(w http.ResponseWriter, r *http.Request) {
+			response.JSON(w, 200, Person{})
+		}
+
+		// @summary Update
+		// @accept form
+		// @router /person [put]
+		func UpdatePerson(w http.ResponseWriter, r *http.Request) {
+			var p Person
+			json.NewDecoder(r.Body).Decode(&p)
+		}
+
+		// The types of the standard library are not proposed
+		// @summary Values
+		// @router /values [get]
+		func Values(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(url.Values{})
+		}
+		`,
+		`package other
+
+		func Handler(w http.ResponseWriter, r *http.Request) {
+			response.JSON(w, 200, Page[Other]{})
+		}
+		`,
+	}
+
+	e := NewExtractor(false, nil, Inference{Schemas: true, EncodeFuncs: []string{"response.JSON"}}).(*extractor)
+	blocks := make([]Block, 0)
+	for i, c := range content {
+		b, err := e.parse(strings.NewReader(c), []string{"tmp-schemas/person/person.go", "tmp-schemas/other/other.go"}[i])
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		blocks = append(blocks, b...)
+	}
+
+	expected := [][]string{
+		{"summary Person", "success 404 {string} Not Found", "router /person [get]", "success 200 {object} Person OK", "produce json"},
+		{"summary Other", "router /other [get]", "success 200 {object} other.Page[other.Other] OK", "produce json"},
+		{"summary Update", "accept form", "router /person [put]", "body Person"},
+		{"summary Values", "router /values [get]"},
+	}
+
+	inferred := e.Infer(blocks)
+	if len(inferred) != len(expected) {
+		t.Errorf("Expected %d blocks, got %d: %v", len(expected), len(inferred), inferred)
+		return
+	}
+	for i, b := range inferred {
+		if strings.Join(b.Lines, "\n") != strings.Join(expected[i], "\n") {
+			t.Errorf("Expected \"%v\", got \"%v\"", expected[i], b.Lines)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package extract
+
+import (
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// Wildcard path params of the ServeMux patterns, i.e. {name...}
+var serveMuxWildcardRx = regexp.MustCompile("{([^}]+)\\.\\.\\.}")
+
+// net/http ServeMux router
+type serveMux struct{}
+
+// Imports checks if the import path is the router package
+func (r *serveMux) Imports(path string) bool {
+	return path == "net/http"
+}
+
+// Route registered by mux.HandleFunc("GET /x/{id}", h),
+// or by http.Handle("/x", h) for any method
+func (r *serveMux) Route(calls map[string]*ast.CallExpr) (Route, bool) {
+	for _, name := range []string{"HandleFunc", "Handle"} {
+		c, ok := calls[name]
+		if ok == false {
+			continue
+		}
+		pattern, ok := stringArg(c, 0)
+		if ok == false {
+			continue
+		}
+		m, path := r.Pattern(pattern)
+		if path == "" {
+			continue
+		}
+		route := Route{Path: path, Router: receiver(c), Handler: handlerArg(c, 1, false)}
+		if m != "" {
+			route.Methods = []string{m}
+		}
+		return route, true
+	}
+	return Route{}, false
+}
+
+// Group created by mux.Handle("/x/", http.StripPrefix("/x", h))
+func (r *serveMux) Group(calls map[string]*ast.CallExpr) (Group, bool) {
+	c, ok := calls["Handle"]
+	if ok == false || len(c.Args) != 2 {
+		return Group{}, false
+	}
+	strip, ok := c.Args[1].(*ast.CallExpr)
+	if ok == false || len(strip.Args) != 2 {
+		return Group{}, false
+	}
+	if sel, ok := strip.Fun.(*ast.SelectorExpr); ok == false || sel.Sel.Name != "StripPrefix" {
+		return Group{}, false
+	}
+	prefix, ok := stringArg(strip, 0)
+	if ok == false {
+		return Group{}, false
+	}
+	return Group{Prefix: prefix, Router: receiver(c), Target: strip.Args[1]}, true
+}
+
+// Pattern of the route split into the method and the path,
+// i.e. [METHOD ][HOST]/[PATH]. The wildcard params are
+// converted into the {name} format, {$} is removed.
+func (r *serveMux) Pattern(pattern string) (string, string) {
+	m := ""
+	if i := strings.IndexAny(pattern, " \t"); i != -1 {
+		m, pattern = pattern[:i], strings.TrimLeft(pattern[i:], " \t")
+	}
+	i := strings.Index(pattern, "/")
+	if i == -1 {
+		return m, ""
+	}
+	path := strings.Replace(pattern[i:], "{$}", "", -1)
+	return m, serveMuxWildcardRx.ReplaceAllString(path, "{$1}")
+}
@@ -0,0 +1,256 @@
+// Package loader parses the Go packages of the project once, and
+// shares the parsed files between the extracting and the reference
+// resolving procedures.
+package loader
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spaceavocado/apidoc/misc"
+)
+
+// File parsed by the loader
+type File struct {
+	// Path of the file
+	Path string
+	// Source of the file
+	Src []byte
+	// AST of the file, possibly partial if the file contains errors
+	AST *ast.File
+	// Err of the parsing, the partially parsed file is still usable
+	Err error
+}
+
+// Loader interface
+type Loader interface {
+	// FileSet shared by all parsed files
+	FileSet() *token.FileSet
+	// File parsed once, regardless of the build constraints
+	File(path string) (*File, error)
+	// Package files of the folder, matching the build constraints
+	Package(dir string) ([]*File, error)
+	// Walk the root folder, and load all packages found in it
+	Walk(root string) ([]*File, error)
+}
+
+// Loading of a file, done once regardless of the callers
+type fileEntry struct {
+	once sync.Once
+	file *File
+	err  error
+}
+
+// Loading of a package, done once regardless of the callers
+type pkgEntry struct {
+	once  sync.Once
+	files []*File
+	err   error
+}
+
+type loader struct {
+	verbose  bool
+	workers  int
+	ctx      build.Context
+	fset     *token.FileSet
+	files    map[string]*fileEntry
+	packages map[string]*pkgEntry
+	mu       sync.Mutex
+}
+
+// FileSet shared by all parsed files
+func (l *loader) FileSet() *token.FileSet {
+	return l.fset
+}
+
+// File parsed once, and stored into the cache.
+// The build constraints are not checked, i.e.
+// an explicitly requested file is always loaded.
+// Concurrent callers of the same file wait
+// for the single parsing, a failed loading
+// is not cached.
+func (l *loader) File(path string) (*File, error) {
+	path = filepath.Clean(path)
+	l.mu.Lock()
+	e, ok := l.files[path]
+	if ok == false {
+		e = &fileEntry{}
+		l.files[path] = e
+	}
+	l.mu.Unlock()
+
+	e.once.Do(func() {
+		e.file, e.err = l.parse(path)
+	})
+	if e.err != nil {
+		l.mu.Lock()
+		if l.files[path] == e {
+			delete(l.files, path)
+		}
+		l.mu.Unlock()
+	}
+	return e.file, e.err
+}
+
+// parse the file
+func (l *loader) parse(path string) (*File, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	af, err := parser.ParseFile(l.fset, path, src, parser.ParseComments)
+	if af == nil {
+		return nil, err
+	}
+	return &File{
+		Path: path,
+		Src:  src,
+		AST:  af,
+		Err:  err,
+	}, nil
+}
+
+// Package files of the folder, parsed once, sorted by the name.
+// The test files, and the files excluded by the build
+// constraints, e.g. the build tags, or the GOOS suffix,
+// are skipped. The files are parsed in parallel,
+// a failed loading is not cached.
+func (l *loader) Package(dir string) ([]*File, error) {
+	dir = filepath.Clean(dir)
+	l.mu.Lock()
+	e, ok := l.packages[dir]
+	if ok == false {
+		e = &pkgEntry{}
+		l.packages[dir] = e
+	}
+	l.mu.Unlock()
+
+	e.once.Do(func() {
+		paths, err := l.Sources(dir)
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.files, e.err = l.parseAll(paths)
+	})
+	if e.err != nil {
+		l.mu.Lock()
+		if l.packages[dir] == e {
+			delete(l.packages, dir)
+		}
+		l.mu.Unlock()
+	}
+	return e.files, e.err
+}
+
+// Sources of the package in the folder, sorted by the name
+func (l *loader) Sources(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	for _, e := range entries {
+		if e.IsDir() == false && l.IsSource(dir, e.Name()) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// IsSource checks if the file is a package source file,
+// i.e. not a test file, and matching the build constraints
+func (l *loader) IsSource(dir, name string) bool {
+	if strings.HasSuffix(name, ".go") == false || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+	ok, err := l.ctx.MatchFile(dir, name)
+	if err != nil && l.verbose {
+		log.Warnf("loading: the build constraints of the file \"%s\" cannot be matched: %v", filepath.Join(dir, name), err)
+	}
+	return err == nil && ok
+}
+
+// parseAll files on the pool of workers,
+// the result is in the order of the paths
+func (l *loader) parseAll(paths []string) ([]*File, error) {
+	files := make([]*File, len(paths))
+	err := misc.Parallel(len(paths), l.workers, func(i int) error {
+		f, err := l.File(paths[i])
+		files[i] = f
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Walk the root folder, and load all packages found in it.
+// The testdata, and the vendor folders are skipped, as well as
+// the folders ignored by the go tool, i.e. prefixed by "." or "_".
+// The files of all packages are parsed in parallel, the result
+// is in the walking order.
+func (l *loader) Walk(root string) ([]*File, error) {
+	dirs := make([]string, 0)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() == false {
+			return nil
+		}
+		if path != root && IsIgnored(info.Name()) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+	for _, d := range dirs {
+		sources, err := l.Sources(d)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, sources...)
+	}
+	return l.parseAll(paths)
+}
+
+// IsIgnored checks if the folder is ignored
+// when walking the packages
+func IsIgnored(name string) bool {
+	return name == "testdata" || name == "vendor" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// NewLoader instance.
+// Tags are the additional build tags satisfied by the loaded
+// packages. Workers bound the parallel parsing, the number
+// of CPUs is used if not set.
+func NewLoader(verbose bool, tags []string, workers int) Loader {
+	ctx := build.Default
+	ctx.BuildTags = append(append([]string{}, ctx.BuildTags...), tags...)
+	return &loader{
+		verbose:  verbose,
+		workers:  workers,
+		ctx:      ctx,
+		fset:     token.NewFileSet(),
+		files:    make(map[string]*fileEntry, 0),
+		packages: make(map[string]*pkgEntry, 0),
+	}
+}
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	files := map[string]string{
+		"tmp/main.go":           "package main",
+		"tmp/main_test.go":      "package main",
+		"tmp/readme.md":         "# tmp",
+		"tmp/tagged.go":         "//go:build custom\n\npackage main",
+		"tmp/broken.go":         "package main\n\nfunc broken( {",
+		"tmp/sub/sub.go":        "package sub",
+		"tmp/testdata/data.go":  "package data",
+		"tmp/vendor/dep.go":     "package dep",
+		"tmp/.hidden/hidden.go": "package hidden",
+		"tmp/_ignored/ign.go":   "package ignored",
+	}
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+	}
+	defer os.RemoveAll("tmp")
+
+	paths := func(files []*File) string {
+		p := make([]string, 0, len(files))
+		for _, f := range files {
+			p = append(p, filepath.ToSlash(f.Path))
+		}
+		return strings.Join(p, ",")
+	}
+
+	// Walk
+	l := NewLoader(false, nil, 0)
+	walked, err := l.Walk("tmp/")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := "tmp/broken.go,tmp/main.go,tmp/sub/sub.go"
+	if paths(walked) != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, paths(walked))
+	}
+	if walked[0].Err == nil || walked[0].AST == nil {
+		t.Errorf("Expected the partially parsed file")
+	}
+
+	// Build tags
+	l = NewLoader(false, []string{"custom"}, 0)
+	pkg, err := l.Package("tmp")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected = "tmp/broken.go,tmp/main.go,tmp/tagged.go"
+	if paths(pkg) != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, paths(pkg))
+	}
+
+	// Parsed once
+	f, err := l.File("./tmp/main.go")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if f != pkg[1] {
+		t.Errorf("Expected the cached file")
+	}
+	if l.FileSet().File(f.AST.Pos()) == nil {
+		t.Errorf("Expected the file in the shared file set")
+	}
+
+	// Concurrent callers
+	l = NewLoader(false, nil, 4)
+	parsed := make([]*File, 8)
+	done := make(chan bool)
+	for i := range parsed {
+		go func(i int) {
+			parsed[i], _ = l.File("tmp/main.go")
+			done <- true
+		}(i)
+	}
+	for range parsed {
+		<-done
+	}
+	for _, p := range parsed {
+		if p == nil || p != parsed[0] {
+			t.Errorf("Expected the single parsed file")
+		}
+	}
+
+	// Explicit file, regardless of the build constraints
+	f, err = NewLoader(false, nil, 0).File("tmp/tagged.go")
+	if err != nil || f.AST.Name.Name != "main" {
+		t.Errorf("Expected the tagged file, got %v", err)
+	}
+
+	// Missing
+	if _, err = l.File("tmp/missing.go"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if _, err = l.Package("tmp/missing"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if _, err = l.Walk("tmp/missing"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
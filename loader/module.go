@@ -0,0 +1,295 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Module resolved from the go.mod file
+type Module struct {
+	// Root folder of the module, i.e. location of the go.mod file
+	Root string
+	// Path of the module
+	Path string
+	// Required modules, module path -> version
+	require map[string]string
+	// Replace directives
+	replace []replacement
+}
+
+// replacement of a module declared
+// by the replace directive
+type replacement struct {
+	oldPath    string
+	oldVersion string
+	newPath    string
+	newVersion string
+}
+
+// PkgDir resolves the location of the imported package.
+// The package is searched within the module itself,
+// the vendor folder, replaced modules and the module cache.
+func (m *Module) PkgDir(pkg, modcache string) (string, bool) {
+	// Package of this module
+	if rel, ok := subPath(pkg, m.Path); ok {
+		return filepath.Join(m.Root, filepath.FromSlash(rel)), true
+	}
+
+	// Vendored package
+	vendor := filepath.Join(m.Root, "vendor", filepath.FromSlash(pkg))
+	if info, err := os.Stat(vendor); err == nil && info.IsDir() {
+		return vendor, true
+	}
+
+	// Find the module providing the package,
+	// i.e. the longest matching module path
+	modPath := ""
+	for p := range m.require {
+		if _, ok := subPath(pkg, p); ok && len(p) > len(modPath) {
+			modPath = p
+		}
+	}
+	for _, rep := range m.replace {
+		if _, ok := subPath(pkg, rep.oldPath); ok && len(rep.oldPath) > len(modPath) {
+			modPath = rep.oldPath
+		}
+	}
+	if modPath == "" {
+		return "", false
+	}
+	rel, _ := subPath(pkg, modPath)
+	version := m.require[modPath]
+
+	// Replaced module
+	for _, rep := range m.replace {
+		if rep.oldPath != modPath || (rep.oldVersion != "" && rep.oldVersion != version) {
+			continue
+		}
+		// Local folder
+		if isLocalPath(rep.newPath) {
+			dir := filepath.FromSlash(rep.newPath)
+			if filepath.IsAbs(dir) == false {
+				dir = filepath.Join(m.Root, dir)
+			}
+			return filepath.Join(dir, filepath.FromSlash(rel)), true
+		}
+		modPath = rep.newPath
+		version = rep.newVersion
+		break
+	}
+	if version == "" || modcache == "" {
+		return "", false
+	}
+
+	// Module cache
+	dir := filepath.Join(modcache, filepath.FromSlash(escapePath(modPath)+"@"+escapePath(version)))
+	return filepath.Join(dir, filepath.FromSlash(rel)), true
+}
+
+// Contains checks if the package is declared
+// in the module, i.e. the import path is under
+// the module path
+func (m *Module) Contains(pkg string) bool {
+	_, ok := subPath(pkg, m.Path)
+	return ok
+}
+
+// FindModule walks up from the folder until
+// the go.mod file is found and parses it.
+// The visited folders are cached.
+func FindModule(dir string, cache map[string]*Module) (*Module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := make([]string, 0)
+	var found *Module
+	for {
+		if m, ok := cache[dir]; ok {
+			found = m
+			break
+		}
+		visited = append(visited, dir)
+		content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			found = parseModFile(string(content))
+			found.Root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for _, v := range visited {
+		cache[v] = found
+	}
+	return found, nil
+}
+
+// parseModFile content. It captures only the directives
+// required to locate the imported packages, i.e.
+// module, require and replace.
+func parseModFile(content string) *Module {
+	m := &Module{
+		require: make(map[string]string, 0),
+		replace: make([]replacement, 0),
+	}
+
+	block := ""
+	for _, l := range strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n") {
+		if i := strings.Index(l, "//"); i > -1 {
+			l = l[:i]
+		}
+		fields := strings.Fields(l)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Block of directives
+		if fields[len(fields)-1] == "(" && len(fields) == 2 {
+			block = fields[0]
+			continue
+		}
+		if fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		directive := block
+		if directive == "" {
+			directive = fields[0]
+			fields = fields[1:]
+		}
+		for i, f := range fields {
+			if uq, err := strconv.Unquote(f); err == nil {
+				fields[i] = uq
+			}
+		}
+
+		switch directive {
+		case "module":
+			if len(fields) > 0 {
+				m.Path = fields[0]
+			}
+		case "require":
+			if len(fields) > 1 {
+				m.require[fields[0]] = fields[1]
+			}
+		case "replace":
+			// Expected format: old [version] => new [version]
+			arrow := -1
+			for i, f := range fields {
+				if f == "=>" {
+					arrow = i
+					break
+				}
+			}
+			if arrow < 1 || arrow == len(fields)-1 {
+				continue
+			}
+			rep := replacement{
+				oldPath: fields[0],
+				newPath: fields[arrow+1],
+			}
+			if arrow > 1 {
+				rep.oldVersion = fields[1]
+			}
+			if len(fields) > arrow+2 {
+				rep.newVersion = fields[arrow+2]
+			}
+			m.replace = append(m.replace, rep)
+		}
+	}
+
+	return m
+}
+
+// subPath of the package within the module path,
+// the path must match the whole path elements
+func subPath(pkg, modPath string) (string, bool) {
+	if pkg == modPath {
+		return "", true
+	}
+	if strings.HasPrefix(pkg, modPath+"/") {
+		return strings.TrimPrefix(pkg, modPath+"/"), true
+	}
+	return "", false
+}
+
+// ImportName guesses the package name from the import path,
+// i.e. the last path element without the major version suffix,
+// and "go" prefix or suffix, e.g. github.com/org/go-lib/v2 -> lib
+func ImportName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	// gopkg.in/yaml.v2
+	if i := strings.LastIndex(name, "."); i > -1 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimPrefix(name, "go.")
+	name = strings.TrimSuffix(name, "-go")
+	name = strings.TrimSuffix(name, ".go")
+	return strings.Replace(name, "-", "_", -1)
+}
+
+// isMajorVersion path element, e.g. v2
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// isLocalPath checks if the replacement
+// path points to a local folder
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "./") ||
+		strings.HasPrefix(path, "../") ||
+		path == "." || path == ".." ||
+		filepath.IsAbs(path)
+}
+
+// escapePath into the module cache format, i.e. upper
+// case letters are replaced by "!" and the lower case letter
+func escapePath(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		if unicode.IsUpper(c) {
+			b.WriteRune('!')
+			b.WriteRune(unicode.ToLower(c))
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// ModCacheDir resolved from the environment,
+// falls back to the GO default location
+func ModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
@@ -0,0 +1,200 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseModFile(t *testing.T) {
+	content :=
+		`
+		module github.com/org/service // comment
+
+		go 1.21
+
+		require github.com/org/single v1.0.0
+		require (
+			github.com/org/lib v1.2.3
+			github.com/Org/Upper v0.1.0 // indirect
+		)
+
+		replace github.com/org/lib => ../lib
+		replace (
+			github.com/org/single v1.0.0 => github.com/fork/single v1.0.1
+			"github.com/org/quoted" => ./quoted
+		)
+	`
+	m := parseModFile(content)
+	if m.Path != "github.com/org/service" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "github.com/org/service", m.Path)
+	}
+	if len(m.require) != 3 {
+		t.Errorf("Expected %d required modules, got %d", 3, len(m.require))
+	}
+	if m.require["github.com/Org/Upper"] != "v0.1.0" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "v0.1.0", m.require["github.com/Org/Upper"])
+	}
+	if len(m.replace) != 3 {
+		t.Errorf("Expected %d replacements, got %d", 3, len(m.replace))
+		return
+	}
+	expected := []replacement{
+		{"github.com/org/lib", "", "../lib", ""},
+		{"github.com/org/single", "v1.0.0", "github.com/fork/single", "v1.0.1"},
+		{"github.com/org/quoted", "", "./quoted", ""},
+	}
+	for i, e := range expected {
+		if m.replace[i] != e {
+			t.Errorf("Expected %+v, got %+v", e, m.replace[i])
+		}
+	}
+}
+
+func TestModulePkgDir(t *testing.T) {
+	err := os.MkdirAll("tmpmod/vendor/github.com/org/vendored/pkg", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpmod")
+
+	root, _ := filepath.Abs("tmpmod")
+	m := &Module{
+		Root: root,
+		Path: "github.com/org/service",
+		require: map[string]string{
+			"github.com/org/lib":         "v1.2.3",
+			"github.com/org/lib/v2":      "v2.0.0",
+			"github.com/org/single":      "v1.0.0",
+			"github.com/Org/Upper":       "v0.1.0",
+			"github.com/org/local":       "v0.0.0",
+			"github.com/org/unversioned": "",
+		},
+		replace: []replacement{
+			{"github.com/org/local", "", "../local", ""},
+			{"github.com/org/single", "v1.0.0", "github.com/fork/single", "v1.0.1"},
+			{"github.com/org/lib", "v0.0.1", "../never", ""},
+		},
+	}
+	modcache := filepath.FromSlash("/cache")
+
+	tests := []struct {
+		pkg      string
+		expected string
+		ok       bool
+	}{
+		{"github.com/org/service", root, true},
+		{"github.com/org/service/model", filepath.Join(root, "model"), true},
+		{"github.com/org/vendored/pkg", filepath.Join(root, "vendor", "github.com", "org", "vendored", "pkg"), true},
+		{"github.com/org/lib/model", filepath.Join(modcache, "github.com", "org", "lib@v1.2.3", "model"), true},
+		{"github.com/org/lib/v2/model", filepath.Join(modcache, "github.com", "org", "lib", "v2@v2.0.0", "model"), true},
+		{"github.com/org/single", filepath.Join(modcache, "github.com", "fork", "single@v1.0.1"), true},
+		{"github.com/Org/Upper/x", filepath.Join(modcache, "github.com", "!org", "!upper@v0.1.0", "x"), true},
+		{"github.com/org/local/x", filepath.Join(filepath.Dir(root), "local", "x"), true},
+		{"github.com/org/unversioned", "", false},
+		{"github.com/org/unknown", "", false},
+		{"github.com/org/services", "", false},
+	}
+	for _, c := range tests {
+		dir, ok := m.PkgDir(c.pkg, modcache)
+		if ok != c.ok {
+			t.Errorf("Expected %v for \"%s\", got %v", c.ok, c.pkg, ok)
+			continue
+		}
+		if dir != c.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", c.expected, dir)
+		}
+	}
+}
+
+func TestFindModule(t *testing.T) {
+	err := os.MkdirAll("tmpmod/pkg/sub", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpmod")
+	err = ioutil.WriteFile("tmpmod/go.mod", []byte("module github.com/org/service\n"), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	cache := make(map[string]*Module, 0)
+	m, err := FindModule("tmpmod/pkg/sub", cache)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if m == nil {
+		t.Errorf("Expected module, got nil")
+		return
+	}
+	if m.Path != "github.com/org/service" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "github.com/org/service", m.Path)
+	}
+	root, _ := filepath.Abs("tmpmod")
+	if m.Root != root {
+		t.Errorf("Expected \"%s\", got \"%s\"", root, m.Root)
+	}
+
+	// Cached folders
+	sub, _ := filepath.Abs("tmpmod/pkg")
+	if cache[sub] != m {
+		t.Errorf("Expected cached module for \"%s\"", sub)
+	}
+	other, err := FindModule("tmpmod/pkg", cache)
+	if err != nil || other != m {
+		t.Errorf("Expected the cached module, got %v %v", other, err)
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	res := escapePath("github.com/BurntSushi/toml")
+	if res != "github.com/!burnt!sushi/toml" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "github.com/!burnt!sushi/toml", res)
+	}
+}
+
+func TestModCacheDir(t *testing.T) {
+	modcache := os.Getenv("GOMODCACHE")
+	gopath := os.Getenv("GOPATH")
+	defer func() {
+		os.Setenv("GOMODCACHE", modcache)
+		os.Setenv("GOPATH", gopath)
+	}()
+
+	os.Setenv("GOMODCACHE", "/cache")
+	if res := ModCacheDir(); res != "/cache" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "/cache", res)
+	}
+
+	os.Setenv("GOMODCACHE", "")
+	os.Setenv("GOPATH", filepath.FromSlash("/gopath"))
+	expected := filepath.Join(filepath.FromSlash("/gopath"), "pkg", "mod")
+	if res := ModCacheDir(); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"time":                           "time",
+		"net/http":                       "http",
+		"github.com/org/lib":             "lib",
+		"github.com/org/lib/v2":          "lib",
+		"gopkg.in/yaml.v2":               "yaml",
+		"github.com/satori/go.uuid":      "uuid",
+		"github.com/org/go-lib":          "lib",
+		"github.com/org/lib-go":          "lib",
+		"github.com/org/multi-word":      "multi_word",
+		"github.com/spaceavocado/apidoc": "apidoc",
+	}
+	for path, expected := range tests {
+		if res := ImportName(path); res != expected {
+			t.Errorf("Expected \"%s\" for \"%s\", got \"%s\"", expected, path, res)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package misc
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallel calls the fn for each index of [0, n) on a bounded
+// pool of workers, the workers default to the number of CPUs.
+// It returns the error of the lowest index, regardless
+// of the scheduling.
+func Parallel(n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
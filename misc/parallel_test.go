@@ -0,0 +1,55 @@
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	var running, peak int32
+	results := make([]int, 100)
+	err := Parallel(len(results), 4, func(i int) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		results[i] = i * 2
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if peak > 4 {
+		t.Errorf("Expected at most %d workers, got %d", 4, peak)
+	}
+	for i, r := range results {
+		if r != i*2 {
+			t.Errorf("Expected %d, got %d", i*2, r)
+		}
+	}
+
+	// Error of the lowest index
+	err = Parallel(10, 0, func(i int) error {
+		if i == 3 || i == 7 {
+			return fmt.Errorf("error %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "error 3" {
+		t.Errorf("Expected \"%s\", got \"%v\"", "error 3", err)
+	}
+
+	// Nothing to process
+	err = Parallel(0, 4, func(i int) error {
+		return errors.New("unexpected call")
+	})
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the output documentation
+const (
+	YAML = "yaml"
+	JSON = "json"
+)
+
+// ParseFormat of the output, i.e. yaml, json, or both
+func ParseFormat(format string) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case YAML:
+		return []string{YAML}, nil
+	case JSON:
+		return []string{JSON}, nil
+	case "both":
+		return []string{YAML, JSON}, nil
+	}
+	return nil, fmt.Errorf("unknown output format \"%s\"", format)
+}
+
+// FormatFile of the output file, i.e. the extension
+// of the file is replaced by the format
+func FormatFile(file, format string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + "." + format
+}
+
+// Encode the document in the format, YAML by default
+func Encode(doc interface{}, format string) ([]byte, error) {
+	if format == JSON {
+		b, err := MarshalJSON(doc, "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	return MarshalYAML(doc)
+}
+
+// WriteFiles of the document in each format, i.e. the
+// extension of the file is replaced by the format
+func WriteFiles(doc interface{}, file string, formats []string) error {
+	// Output folder
+	err := os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	// Save the output files
+	for _, format := range formats {
+		b, err := Encode(doc, format)
+		if err != nil {
+			return err
+		}
+		fp, err := os.Create(FormatFile(file, format))
+		if err != nil {
+			return err
+		}
+		_, err = fp.Write(b)
+		if cerr := fp.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
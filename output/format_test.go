@@ -0,0 +1,106 @@
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]string{
+		"yaml":   "yaml",
+		"json":   "json",
+		" Both ": "yaml,json",
+	}
+	for format, expected := range tests {
+		formats, err := ParseFormat(format)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if strings.Join(formats, ",") != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, strings.Join(formats, ","))
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestFormatFile(t *testing.T) {
+	tests := [][]string{
+		{"docs/openapi.yaml", "json", "docs/openapi.json"},
+		{"docs/openapi.yaml", "yaml", "docs/openapi.yaml"},
+		{"docs/openapi", "json", "docs/openapi.json"},
+	}
+	for _, test := range tests {
+		if res := FormatFile(test[0], test[1]); res != test[2] {
+			t.Errorf("Expected \"%s\", got \"%s\"", test[2], res)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	doc := map[string]string{"a": "b"}
+	tests := map[string]string{
+		YAML: "a: b\n",
+		JSON: "{\n  \"a\": \"b\"\n}\n",
+	}
+	for format, expected := range tests {
+		b, err := Encode(doc, format)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		if string(b) != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, string(b))
+		}
+	}
+	if _, err := Encode(func() {}, JSON); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := "tmp-write"
+	defer os.RemoveAll(dir)
+
+	doc := map[string]string{"a": "b"}
+	err := WriteFiles(doc, filepath.Join(dir, "openapi.yaml"), []string{YAML, JSON})
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	for _, format := range []string{YAML, JSON} {
+		expected, _ := Encode(doc, format)
+		b, err := ioutil.ReadFile(filepath.Join(dir, "openapi."+format))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if string(b) != string(expected) {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, b)
+		}
+	}
+
+	// Invalid output folder
+	err = WriteFiles(doc, "format.go/tmp/openapi.yaml", []string{YAML})
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// Invalid file
+	os.MkdirAll(filepath.Join(dir, "invalid.json"), os.ModePerm)
+	err = WriteFiles(doc, filepath.Join(dir, "invalid.yaml"), []string{JSON})
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// Invalid document
+	err = WriteFiles(func() {}, filepath.Join(dir, "openapi.yaml"), []string{JSON})
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,390 @@
+// Package html generates the documentation into a static HTML site,
+// i.e. self-contained pages readable offline, without any CDN.
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spaceavocado/apidoc/misc"
+	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+type generator struct {
+	verbose bool
+	// OpenAPI version of the document, i.e. 3.0 or 3.1
+	version string
+	// Page of the component schemas
+	schemasFile string
+	// Reference prefix of the components
+	componentsRef string
+	// Group of the operations without a tag
+	defaultTag string
+	// Group of the webhooks
+	webhooksGroup string
+	// Example values of the string formats
+	stringExamples map[string]string
+	// Page templates by the page kind,
+	// i.e. index, schemas, operation
+	templates map[string]*template.Template
+	// Slug of the operation page name
+	slugRx *regexp.Regexp
+
+	// Component schemas of the document, by the name
+	schemas map[string]*openapi.Schema
+}
+
+// Site of the documentation
+type site struct {
+	Info    openapi.Info
+	Servers []*openapi.Server
+	// Navigation groups, by the tag
+	Groups []*group
+	Pages  []*page
+	// Component schemas, by the name
+	Schemas     []*schemaTable
+	IndexFile   string
+	SchemasFile string
+	Style       template.CSS
+}
+
+// Group of the operation pages by the tag
+type group struct {
+	Name  string
+	Pages []*page
+}
+
+// Page of the operation
+type page struct {
+	File       string
+	Title      string
+	Method     string
+	Path       string
+	Operation  *openapi.Operation
+	Parameters []parameter
+	Body       []content
+	Responses  []response
+}
+
+// Parameter of the operation
+type parameter struct {
+	Name        string
+	In          string
+	Type        []typePart
+	Required    bool
+	Description string
+}
+
+// Content of the request body, or of the response, by the media type
+type content struct {
+	MediaType string
+	Type      []typePart
+	Rows      []row
+	Example   string
+}
+
+// Response of the operation
+type response struct {
+	Code        string
+	Description string
+	Content     []content
+}
+
+// SchemaTable of the component
+type schemaTable struct {
+	Name        string
+	Description string
+	Type        []typePart
+	Rows        []row
+	Example     string
+}
+
+// View of the page rendered by the template
+type view struct {
+	Site  *site
+	File  string
+	Title string
+	Page  *page
+}
+
+// Generate the documentation from the given tokens for the
+// main section and for the given endpoints, into the file,
+// i.e. the index page. The other pages are written into the
+// folder of the index page.
+func (g *generator) Generate(main []token.Token, endpoints [][]token.Token, file string) error {
+	dir := filepath.Dir(file)
+	s := g.Site(openapi.NewDocument(g.verbose, g.version, main, endpoints), filepath.Base(file))
+
+	// Output folder
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	// Save the pages
+	err = g.Write(file, "index", &view{Site: s, File: s.IndexFile, Title: s.Info.Title})
+	if err != nil {
+		return err
+	}
+	err = g.Write(filepath.Join(dir, s.SchemasFile), "schemas", &view{Site: s, File: s.SchemasFile, Title: "Schemas"})
+	if err != nil {
+		return err
+	}
+	for _, p := range s.Pages {
+		err = g.Write(filepath.Join(dir, p.File), "operation", &view{Site: s, File: p.File, Title: p.Title, Page: p})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write the page of the kind into the file
+func (g *generator) Write(file, kind string, v *view) error {
+	fp, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = g.templates[kind].ExecuteTemplate(fp, "layout", v)
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Site model built from the OpenAPI document, the
+// operation pages are named by the operation id
+func (g *generator) Site(doc *openapi.Document, indexFile string) *site {
+	s := &site{
+		Info:        doc.Info,
+		Servers:     doc.Servers,
+		Groups:      make([]*group, 0),
+		Pages:       make([]*page, 0),
+		Schemas:     make([]*schemaTable, 0),
+		IndexFile:   indexFile,
+		SchemasFile: g.schemasFile,
+		Style:       template.CSS(style),
+	}
+	g.schemas = make(map[string]*openapi.Schema, 0)
+	if doc.Components != nil {
+		g.schemas = doc.Components.Schemas
+	}
+
+	// Pages, the names of the index and
+	// of the schemas pages are taken
+	taken := []string{s.IndexFile, s.SchemasFile}
+	groups := make(map[string]*group, 0)
+	urls := make([]string, 0, len(doc.Paths))
+	for url := range doc.Paths {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		item := doc.Paths[url]
+		for _, method := range item.Methods() {
+			p := g.Page(url, method, item.Operation(method))
+			p.File = g.PageFile(p, &taken)
+			s.Pages = append(s.Pages, p)
+
+			tags := p.Operation.Tags
+			if len(tags) == 0 {
+				tags = []string{g.defaultTag}
+			}
+			for _, tag := range tags {
+				if _, ok := groups[tag]; ok == false {
+					groups[tag] = &group{Name: tag}
+				}
+				groups[tag].Pages = append(groups[tag].Pages, p)
+			}
+		}
+	}
+
+	// Groups by the tag, the default group is the last
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != g.defaultTag {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[g.defaultTag]; ok {
+		names = append(names, g.defaultTag)
+	}
+	for _, name := range names {
+		s.Groups = append(s.Groups, groups[name])
+	}
+
+	// Webhooks of 3.1, sorted by the name, are the last group
+	webhooks := &group{Name: g.webhooksGroup}
+	names = make([]string, 0, len(doc.Webhooks))
+	for name := range doc.Webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		item := doc.Webhooks[name]
+		for _, method := range item.Methods() {
+			p := g.Page(name, method, item.Operation(method))
+			p.File = g.PageFile(p, &taken)
+			s.Pages = append(s.Pages, p)
+			webhooks.Pages = append(webhooks.Pages, p)
+		}
+	}
+	if len(webhooks.Pages) > 0 {
+		s.Groups = append(s.Groups, webhooks)
+	}
+
+	// Schemas, by the name
+	names = make([]string, 0, len(g.schemas))
+	for name := range g.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := g.schemas[name].Plain(g.schemas)
+		table := &schemaTable{
+			Name:        name,
+			Description: schema.Description,
+			Rows:        g.Rows(schema, ""),
+			Example:     g.ExamplePayload(schema),
+		}
+		if schema.InlineObject() == false {
+			table.Type = g.TypeParts(schema)
+		}
+		s.Schemas = append(s.Schemas, table)
+	}
+
+	return s
+}
+
+// Page of the operation
+func (g *generator) Page(url, method string, op *openapi.Operation) *page {
+	p := &page{
+		Title:     op.Summary,
+		Method:    method,
+		Path:      url,
+		Operation: op,
+	}
+	if p.Title == "" {
+		p.Title = fmt.Sprintf("%s %s", strings.ToUpper(method), url)
+	}
+
+	// Params
+	for _, param := range op.Parameters {
+		p.Parameters = append(p.Parameters, parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Type:        g.TypeParts(param.Schema),
+			Required:    param.Required,
+			Description: param.Description,
+		})
+	}
+
+	// Body
+	if op.RequestBody != nil {
+		p.Body = g.Content(op.RequestBody.Content)
+	}
+
+	// Responses, by the code
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		p.Responses = append(p.Responses, response{
+			Code:        code,
+			Description: op.Responses[code].Description,
+			Content:     g.Content(op.Responses[code].Content),
+		})
+	}
+	return p
+}
+
+// Content by the media type, the inline objects are described
+// by the rows, the JSON contents have the example payload
+func (g *generator) Content(mts map[string]*openapi.MediaType) []content {
+	names := make([]string, 0, len(mts))
+	for mt := range mts {
+		names = append(names, mt)
+	}
+	sort.Strings(names)
+
+	contents := make([]content, 0, len(names))
+	for _, mt := range names {
+		s := mts[mt].Schema.Plain(g.schemas)
+		c := content{
+			MediaType: mt,
+			Type:      g.TypeParts(s),
+		}
+		if s.InlineObject() {
+			c.Rows = g.Rows(s, "")
+		}
+		if strings.Contains(mt, "json") {
+			c.Example = g.ExamplePayload(s)
+		}
+		contents = append(contents, c)
+	}
+	return contents
+}
+
+// PageFile of the operation page, named by the operation id,
+// or by the method and the path, numbered if already taken
+func (g *generator) PageFile(p *page, taken *[]string) string {
+	name := p.Operation.OperationID
+	if name == "" {
+		name = fmt.Sprintf("%s %s", p.Method, p.Path)
+	}
+	base := strings.Trim(g.slugRx.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "operation"
+	}
+
+	file := base + ".html"
+	for i := 1; misc.StringInSlice(file, *taken); i++ {
+		file = fmt.Sprintf("%s-%d.html", base, i)
+	}
+	*taken = append(*taken, file)
+	return file
+}
+
+// NewGenerator instance.
+// Version of the OpenAPI document, 3.0 is used if not set.
+func NewGenerator(verbose bool, version string) output.Generator {
+	if version == "" {
+		version = openapi.Version30
+	}
+	layout := template.Must(template.New("layout").Parse(layoutTemplate))
+	templates := make(map[string]*template.Template, 0)
+	for kind, content := range map[string]string{
+		"index":     indexTemplate,
+		"schemas":   schemasTemplate,
+		"operation": operationTemplate,
+	} {
+		templates[kind] = template.Must(template.Must(layout.Clone()).Parse(content))
+	}
+	return &generator{
+		verbose:       verbose,
+		version:       version,
+		schemasFile:   "schemas.html",
+		componentsRef: "#/components/schemas/",
+		defaultTag:    "default",
+		webhooksGroup: "Webhooks",
+		stringExamples: map[string]string{
+			"date":      "2006-01-02",
+			"date-time": "2006-01-02T15:04:05Z",
+			"email":     "user@example.com",
+			"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			"uri":       "https://example.com",
+		},
+		templates: templates,
+		slugRx:    regexp.MustCompile(`[^a-z0-9]+`),
+	}
+}
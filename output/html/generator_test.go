@@ -0,0 +1,206 @@
+package html
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+func TestGenerate(t *testing.T) {
+	g := NewGenerator(false, "")
+
+	dir := "tmp-site"
+	defer os.RemoveAll(dir)
+
+	// Invalid output folder
+	err := g.Generate([]token.Token{{}}, [][]token.Token{{{}}}, "generator.go/tmp/index.html")
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// Invalid file
+	os.MkdirAll(filepath.Join(dir, "index.html"), os.ModePerm)
+	err = g.Generate([]token.Token{{}}, [][]token.Token{{{}}}, filepath.Join(dir, "index.html"))
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	os.RemoveAll(dir)
+
+	endpoint := func(id, url, method, tag string) []token.Token {
+		return []token.Token{
+			{Key: "id", Meta: map[string]string{"value": id}},
+			{Key: "summary", Meta: map[string]string{"value": "Summary of " + id}},
+			{Key: "tag", Meta: map[string]string{"value": tag}},
+			{Key: "produce", Meta: map[string]string{"value": "json"}},
+			{Key: "param", Meta: map[string]string{"key": "id", "in": "path", "type": "{int}", "req": "true", "desc": "Pet ID"}},
+			{Key: "bref", Meta: map[string]string{"pkg.type": "github.com/pkg.Pet", "key": "name", "type": "string", "req": "true", "desc": "<script>alert(1)</script>"}},
+			{Key: "success", Meta: map[string]string{"code": "200", "type": "{object}", "ref": "github.com/pkg.Pet", "desc": "OK"}},
+			{Key: "router", Meta: map[string]string{"url": url, "method": method}},
+		}
+	}
+	err = g.Generate(
+		[]token.Token{
+			{Key: "title", Meta: map[string]string{"value": "Pets"}},
+			{Key: "ver", Meta: map[string]string{"value": "1.0"}},
+			{Key: "server", Meta: map[string]string{"url": "https://pets.go/v1", "desc": "Production"}},
+		},
+		[][]token.Token{
+			endpoint("get-pet", "/pets/{id}", "[get]", "Pet"),
+			endpoint("delete-pet", "/pets/{id}", "[delete]", "Pet, Admin"),
+			endpoint("", "/stats", "[get]", ""),
+		},
+		filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	read := func(file string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		return string(b)
+	}
+	tests := map[string][]string{
+		"index.html": {
+			"<title>Pets</title>",
+			"<code>https://pets.go/v1</code> Production",
+			"<h3>Admin</h3>\n<ul>\n<li><a href=\"delete-pet.html\"><span class=\"method delete\">delete</span> Summary of delete-pet</a></li>\n</ul>",
+			"<h3>Pet</h3>\n<ul>\n<li><a href=\"get-pet.html\"><span class=\"method get\">get</span> Summary of get-pet</a></li>\n<li><a href=\"delete-pet.html\">",
+			"<h3>default</h3>\n<ul>\n<li><a href=\"get-stats.html\">",
+		},
+		"get-pet.html": {
+			"<title>Summary of get-pet - Pets</title>",
+			"<li class=\"active\"><a href=\"get-pet.html\">",
+			"<tr><td><code>id</code></td><td>path</td><td>integer</td><td>yes</td><td>Pet ID</td></tr>",
+			"<div class=\"media-type\">application/json: <a href=\"schemas.html#Pet\">Pet</a></div>",
+		},
+		"schemas.html": {
+			"<section id=\"Pet\">",
+			"<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>",
+			"<pre class=\"example\">{\n  &#34;name&#34;: &#34;string&#34;\n}</pre>",
+		},
+	}
+	for file, contains := range tests {
+		res := read(file)
+		for _, c := range contains {
+			if strings.Contains(res, c) == false {
+				t.Errorf("Expected \"%s\" in %s, got \"%s\"", c, file, res)
+			}
+		}
+		// Self-contained, i.e. no external resources
+		if strings.Contains(res, "<script") || strings.Contains(res, "<link") {
+			t.Errorf("Expected no external resources in %s", file)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "delete-pet.html")); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestPageFile(t *testing.T) {
+	g := NewGenerator(false, "").(*generator)
+	taken := []string{"index.html", "schemas.html"}
+	tests := []struct {
+		page     *page
+		expected string
+	}{
+		{&page{Operation: &openapi.Operation{OperationID: "Create Person"}}, "create-person.html"},
+		{&page{Operation: &openapi.Operation{OperationID: "create-person"}}, "create-person-1.html"},
+		{&page{Operation: &openapi.Operation{}, Method: "get", Path: "/person/{id}/address"}, "get-person-id-address.html"},
+		{&page{Operation: &openapi.Operation{OperationID: "schemas"}}, "schemas-1.html"},
+		{&page{Operation: &openapi.Operation{OperationID: "/"}}, "operation.html"},
+	}
+	for _, test := range tests {
+		if res := g.PageFile(test.page, &taken); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestGenerateVersion31(t *testing.T) {
+	dir := "tmp-site-31"
+	defer os.RemoveAll(dir)
+
+	prop := func(meta map[string]string) token.Token {
+		meta["pkg.type"] = "github.com/pkg.Pet"
+		if _, ok := meta["req"]; ok == false {
+			meta["req"] = "false"
+		}
+		return token.Token{Key: "bref", Meta: meta}
+	}
+	main := []token.Token{{Key: "title", Meta: map[string]string{"value": "Pets"}}}
+
+	// The tokens are transformed by the generator
+	endpoints := func() [][]token.Token {
+		return [][]token.Token{{
+			{Key: "webhook", Meta: map[string]string{"url": "newPet", "method": "post"}},
+			{Key: "accept", Meta: map[string]string{"value": "json"}},
+			{Key: "body", Meta: map[string]string{"value": "github.com/pkg.Pet"}},
+			prop(map[string]string{"key": "name", "type": "string", "nullable": "true", "example": "\"Rex\""}),
+			prop(map[string]string{"key": "kind", "type": "string", "enum": "[\"dog\"]"}),
+			prop(map[string]string{"key": "owner", "type": "github.com/pkg.Pet", "nullable": "true"}),
+			prop(map[string]string{"key": "home", "type": "object", "desc": "Home of the pet"}),
+			prop(map[string]string{"key": "home.geo", "type": "object"}),
+			prop(map[string]string{"key": "home.geo.lat", "type": "number"}),
+			prop(map[string]string{"key": "toys", "type": "array object"}),
+			prop(map[string]string{"key": "toys.name", "type": "string"}),
+			{Key: "success", Meta: map[string]string{"code": "200", "type": "string", "desc": "OK"}},
+		}}
+	}
+
+	// The webhooks are skipped in 3.0
+	err := NewGenerator(false, openapi.Version30).Generate(main, endpoints(), filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if strings.Contains(string(b), "Webhooks") {
+		t.Errorf("Expected no webhooks, got \"%s\"", b)
+	}
+	os.RemoveAll(dir)
+
+	err = NewGenerator(false, openapi.Version31).Generate(main, endpoints(), filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	tests := map[string][]string{
+		"index.html": {
+			"<h3>Webhooks</h3>\n<ul>\n<li><a href=\"post-newpet.html\"><span class=\"method post\">post</span> POST newPet</a></li>\n</ul>",
+			"<h2>Webhooks</h2>",
+		},
+		"post-newpet.html": {
+			"<p class=\"endpoint\"><span class=\"method post\">post</span> <code>newPet</code></p>",
+			"<div class=\"media-type\">application/json: <a href=\"schemas.html#Pet\">Pet</a></div>",
+		},
+		"schemas.html": {
+			"<tr><td><code>name</code></td><td>string</td><td></td><td><div class=\"detail\">example: &#34;Rex&#34;</div><div class=\"detail\">nullable</div></td></tr>",
+			"<tr><td><code>kind</code></td><td>string</td><td></td><td><div class=\"detail\">enum: &#34;dog&#34;</div></td></tr>",
+			"<tr><td><code>owner</code></td><td><a href=\"schemas.html#Pet\">Pet</a></td><td></td><td><div class=\"detail\">nullable</div></td></tr>",
+			"<tr><td><code>home</code></td><td>object</td><td></td><td>Home of the pet</td></tr>",
+			"<tr><td><code>home.geo.lat</code></td><td>number</td>",
+			"<tr><td><code>toys[].name</code></td><td>string</td>",
+			"&#34;name&#34;: &#34;Rex&#34;,\n  &#34;kind&#34;: &#34;dog&#34;",
+		},
+	}
+	for file, contains := range tests {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		for _, c := range contains {
+			if strings.Contains(string(b), c) == false {
+				t.Errorf("Expected \"%s\" in %s, got \"%s\"", c, file, b)
+			}
+		}
+	}
+}
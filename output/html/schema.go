@@ -0,0 +1,163 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spaceavocado/apidoc/misc"
+	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+)
+
+// typePart of the schema type label, optionally
+// linked to the schema of the component
+type typePart struct {
+	Text string
+	Link string
+}
+
+// row of the schema table, i.e. the property
+type row struct {
+	Name        string
+	Type        []typePart
+	Required    bool
+	Description string
+	Details     []string
+}
+
+// ComponentName of the reference, i.e. the
+// component name without the reference prefix
+func (g *generator) ComponentName(ref string) string {
+	return strings.TrimPrefix(ref, g.componentsRef)
+}
+
+// TypeParts of the schema type label, e.g. array of Person
+func (g *generator) TypeParts(s *openapi.Schema) []typePart {
+	s = s.Plain(g.schemas)
+	switch {
+	case s == nil:
+		return []typePart{{Text: "any"}}
+	case s.Ref != "":
+		name := g.ComponentName(s.Ref)
+		return []typePart{{Text: name, Link: fmt.Sprintf("%s#%s", g.schemasFile, name)}}
+	case len(s.AllOf) == 1:
+		return g.TypeParts(s.AllOf[0])
+	case s.Type == "array":
+		return append([]typePart{{Text: "array of "}}, g.TypeParts(s.Items)...)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return append([]typePart{{Text: "map of "}}, g.TypeParts(s.AdditionalProperties)...)
+	}
+	t, ok := s.Type.(string)
+	if ok == false || t == "" {
+		return []typePart{{Text: "any"}}
+	}
+	if s.Format != "" {
+		t = fmt.Sprintf("%s (%s)", t, s.Format)
+	}
+	return []typePart{{Text: t}}
+}
+
+// Rows of the object schema, the props of the inline
+// objects are listed by the path, e.g. address.city
+func (g *generator) Rows(s *openapi.Schema, prefix string) []row {
+	rows := make([]row, 0)
+	for _, prop := range s.Properties {
+		name := prefix + prop.Name
+		ps := prop.Schema.Plain(g.schemas)
+		rows = append(rows, row{
+			Name:        name,
+			Type:        g.TypeParts(ps),
+			Required:    misc.StringInSlice(prop.Name, s.Required),
+			Description: ps.Description,
+			Details:     ps.Details(),
+		})
+
+		// Inline object, or the array of inline objects
+		if items := ps.Items.Plain(g.schemas); ps.InlineObject() {
+			rows = append(rows, g.Rows(ps, name+".")...)
+		} else if ps.Type == "array" && items.InlineObject() {
+			rows = append(rows, g.Rows(items, name+"[].")...)
+		}
+	}
+	return rows
+}
+
+// Example payload of the schema, i.e. the example, the default
+// or the first enum value if set, otherwise a value of the type
+func (g *generator) Example(s *openapi.Schema, refs []string) json.RawMessage {
+	s = s.Plain(g.schemas)
+	switch {
+	case s == nil:
+		return json.RawMessage("null")
+	case s.Example != nil:
+		return metaJSON(s.Example)
+	case s.Default != nil:
+		return metaJSON(s.Default)
+	case s.Enum != nil:
+		values := make([]json.RawMessage, 0)
+		if raw, ok := s.Enum.(json.RawMessage); ok && json.Unmarshal(raw, &values) == nil && len(values) > 0 {
+			return values[0]
+		}
+	case s.Ref != "":
+		// The recursive components are cut
+		name := g.ComponentName(s.Ref)
+		if comp, ok := g.schemas[name]; ok && misc.StringInSlice(name, refs) == false {
+			return g.Example(comp, append(refs, name))
+		}
+		return json.RawMessage("null")
+	case len(s.AllOf) == 1:
+		return g.Example(s.AllOf[0], refs)
+	}
+
+	switch s.Type {
+	case "object":
+		b := &bytes.Buffer{}
+		b.WriteByte('{')
+		if s.AdditionalProperties != nil {
+			fmt.Fprintf(b, `"key":%s`, g.Example(s.AdditionalProperties, refs))
+		}
+		for i, prop := range s.Properties {
+			if i > 0 || s.AdditionalProperties != nil {
+				b.WriteByte(',')
+			}
+			name, _ := output.MarshalJSON(prop.Name, "")
+			fmt.Fprintf(b, "%s:%s", name, g.Example(prop.Schema, refs))
+		}
+		b.WriteByte('}')
+		return b.Bytes()
+	case "array":
+		return json.RawMessage(fmt.Sprintf("[%s]", g.Example(s.Items, refs)))
+	case "string":
+		if example, ok := g.stringExamples[s.Format]; ok {
+			b, _ := output.MarshalJSON(example, "")
+			return b
+		}
+		return json.RawMessage(`"string"`)
+	case "integer", "number":
+		return json.RawMessage("0")
+	case "boolean":
+		return json.RawMessage("true")
+	}
+	return json.RawMessage("null")
+}
+
+// ExamplePayload of the schema, indented
+func (g *generator) ExamplePayload(s *openapi.Schema) string {
+	b := &bytes.Buffer{}
+	if err := json.Indent(b, g.Example(s, []string{}), "", "  "); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// metaJSON of the meta value, the strings
+// are encoded as the JSON strings
+func metaJSON(v interface{}) json.RawMessage {
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw
+	}
+	b, _ := output.MarshalJSON(openapi.MetaString(v), "")
+	return b
+}
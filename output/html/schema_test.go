@@ -0,0 +1,120 @@
+package html
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spaceavocado/apidoc/output/openapi"
+)
+
+// typeText of the type parts, the links in the brackets
+func typeText(parts []typePart) string {
+	b := &strings.Builder{}
+	for _, p := range parts {
+		b.WriteString(p.Text)
+		if p.Link != "" {
+			b.WriteString("(" + p.Link + ")")
+		}
+	}
+	return b.String()
+}
+
+func TestTypeParts(t *testing.T) {
+	g := NewGenerator(false, "").(*generator)
+	tests := []struct {
+		schema   *openapi.Schema
+		expected string
+	}{
+		{nil, "any"},
+		{&openapi.Schema{}, "any"},
+		{&openapi.Schema{Type: "string", Format: "email"}, "string (email)"},
+		{&openapi.Schema{Ref: "#/components/schemas/Person"}, "Person(schemas.html#Person)"},
+		{&openapi.Schema{AllOf: []*openapi.Schema{{Ref: "#/components/schemas/Person"}}}, "Person(schemas.html#Person)"},
+		{&openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "integer"}}, "array of integer"},
+		{&openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/Tag"}}}, "map of array of Tag(schemas.html#Tag)"},
+		{&openapi.Schema{Type: []string{"array", "null"}, Items: &openapi.Schema{Type: "integer"}}, "array of integer"},
+		{&openapi.Schema{AnyOf: []*openapi.Schema{{Ref: "#/components/schemas/Person"}, {Type: "null"}}}, "Person(schemas.html#Person)"},
+	}
+	for _, test := range tests {
+		if res := typeText(g.TypeParts(test.schema)); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestRows(t *testing.T) {
+	g := NewGenerator(false, "").(*generator)
+	s := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: openapi.Properties{
+			{Name: "name", Schema: &openapi.Schema{Type: "string", Description: "Name", MinLength: json.RawMessage("1"), Nullable: json.RawMessage("true")}},
+			{Name: "home", Schema: &openapi.Schema{Type: "object", Properties: openapi.Properties{
+				{Name: "city", Schema: &openapi.Schema{Type: "string"}},
+			}}},
+			{Name: "toys", Schema: &openapi.Schema{Type: "array", MinItems: json.RawMessage("1"), Items: &openapi.Schema{Type: "object", Properties: openapi.Properties{
+				{Name: "kind", Schema: &openapi.Schema{Type: "string", Enum: json.RawMessage(`["ball","bone"]`)}},
+			}}}},
+		},
+	}
+
+	expected := []string{
+		"name|string|true|Name|min length: 1,nullable",
+		"home|object|false||",
+		"home.city|string|false||",
+		"toys|array of object|false||min items: 1",
+		"toys[].kind|string|false||enum: \"ball\", \"bone\"",
+	}
+	rows := g.Rows(s, "")
+	if len(rows) != len(expected) {
+		t.Errorf("Expected %d rows, got %d", len(expected), len(rows))
+		return
+	}
+	for i, r := range rows {
+		res := strings.Join([]string{
+			r.Name,
+			typeText(r.Type),
+			map[bool]string{true: "true", false: "false"}[r.Required],
+			r.Description,
+			strings.Join(r.Details, ","),
+		}, "|")
+		if res != expected[i] {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], res)
+		}
+	}
+}
+
+func TestExample(t *testing.T) {
+	g := NewGenerator(false, "").(*generator)
+	g.schemas = map[string]*openapi.Schema{
+		"Person": {
+			Type: "object",
+			Properties: openapi.Properties{
+				{Name: "name", Schema: &openapi.Schema{Type: "string", Example: json.RawMessage(`"Peter"`)}},
+				{Name: "email", Schema: &openapi.Schema{Type: "string", Format: "email"}},
+				{Name: "age", Schema: &openapi.Schema{Type: "integer", Default: json.RawMessage("18")}},
+				{Name: "role", Schema: &openapi.Schema{Type: "string", Enum: json.RawMessage(`["admin","user"]`)}},
+				{Name: "active", Schema: &openapi.Schema{Type: "boolean"}},
+				{Name: "note", Schema: &openapi.Schema{Type: "string", Example: "<b>"}},
+				{Name: "labels", Schema: &openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "number"}}},
+				{Name: "friends", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/Person"}}},
+				{Name: "any", Schema: &openapi.Schema{}},
+			},
+		},
+	}
+
+	expected := `{"name":"Peter","email":"user@example.com","age":18,"role":"admin","active":true,"note":"<b>","labels":{"key":0},"friends":[null],"any":null}`
+	res := string(g.Example(&openapi.Schema{Ref: "#/components/schemas/Person"}, []string{}))
+	if res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+	if json.Valid([]byte(res)) == false {
+		t.Errorf("Expected the valid JSON, got \"%s\"", res)
+	}
+
+	expected = "[\n  0\n]"
+	if res := g.ExamplePayload(&openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "number"}}); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
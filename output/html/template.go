@@ -0,0 +1,180 @@
+package html
+
+// Layout of the pages, i.e. the navigation
+// sidebar, and the content of the page
+const layoutTemplate = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if ne .Title .Site.Info.Title}} - {{.Site.Info.Title}}{{end}}</title>
+<style>{{.Site.Style}}</style>
+</head>
+<body>
+<nav class="sidebar">
+<a class="brand" href="{{.Site.IndexFile}}">{{.Site.Info.Title}}</a>{{if .Site.Info.Version}} <span class="version">{{.Site.Info.Version}}</span>{{end}}
+{{- range .Site.Groups}}
+<h3>{{.Name}}</h3>
+<ul>
+{{- range .Pages}}
+<li{{if eq .File $.File}} class="active"{{end}}><a href="{{.File}}"><span class="method {{.Method}}">{{.Method}}</span> {{.Title}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Site.Schemas}}
+<h3><a href="{{.Site.SchemasFile}}">Schemas</a></h3>
+{{- end}}
+</nav>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+{{end}}
+{{define "type"}}{{range .}}{{if .Link}}<a href="{{.Link}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}{{end}}{{end}}
+{{define "rows"}}<table>
+<thead><tr><th>Name</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td><code>{{.Name}}</code></td><td>{{template "type" .Type}}</td><td>{{if .Required}}yes{{end}}</td><td>{{.Description}}{{range .Details}}<div class="detail">{{.}}</div>{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>{{end}}
+{{define "content-types"}}{{range .}}
+<div class="content">
+<div class="media-type">{{.MediaType}}: {{template "type" .Type}}</div>
+{{- if .Rows}}
+{{template "rows" .Rows}}
+{{- end}}
+{{- if .Example}}
+<pre class="example">{{.Example}}</pre>
+{{- end}}
+</div>
+{{- end}}{{end}}`
+
+// Index page, i.e. the information about the API
+const indexTemplate = `{{define "content"}}{{with .Site.Info}}<h1>{{.Title}}</h1>
+{{- if .Version}}
+<p class="version">Version {{.Version}}</p>
+{{- end}}
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- if .TermsOfService}}
+<p><a href="{{.TermsOfService}}">Terms of Service</a></p>
+{{- end}}
+{{- with .Contact}}
+<p>Contact: {{if .URL}}<a href="{{.URL}}">{{or .Name .URL}}</a>{{else}}{{.Name}}{{end}}{{if .Email}} <a href="mailto:{{.Email}}">{{.Email}}</a>{{end}}</p>
+{{- end}}
+{{- with .License}}
+<p>License: {{if .URL}}<a href="{{.URL}}">{{or .Name .URL}}</a>{{else}}{{.Name}}{{end}}</p>
+{{- end}}
+{{- end}}
+{{- if .Site.Servers}}
+<h2>Servers</h2>
+<ul>
+{{- range .Site.Servers}}
+<li><code>{{.URL}}</code>{{if .Description}} {{.Description}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- range .Site.Groups}}
+<h2>{{.Name}}</h2>
+<table>
+<tbody>
+{{- range .Pages}}
+<tr><td><span class="method {{.Method}}">{{.Method}}</span></td><td><a href="{{.File}}"><code>{{.Path}}</code></a></td><td>{{.Operation.Summary}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{end}}`
+
+// Schemas page, i.e. the tables of the component schemas
+const schemasTemplate = `{{define "content"}}<h1>Schemas</h1>
+{{- range .Site.Schemas}}
+<section id="{{.Name}}">
+<h2>{{.Name}}</h2>
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- if .Type}}
+<p>Type: {{template "type" .Type}}</p>
+{{- end}}
+{{- if .Rows}}
+{{template "rows" .Rows}}
+{{- end}}
+{{- if .Example}}
+<pre class="example">{{.Example}}</pre>
+{{- end}}
+</section>
+{{- end}}
+{{end}}`
+
+// Operation page, i.e. the parameters,
+// the request body, and the responses
+const operationTemplate = `{{define "content"}}{{with .Page}}<h1>{{.Title}}</h1>
+<p class="endpoint"><span class="method {{.Method}}">{{.Method}}</span> <code>{{.Path}}</code></p>
+{{- with .Operation}}
+{{- if .OperationID}}
+<p class="detail">Operation ID: <code>{{.OperationID}}</code></p>
+{{- end}}
+{{- if .Description}}
+<p class="description">{{.Description}}</p>
+{{- end}}
+{{- end}}
+{{- if .Parameters}}
+<h2>Parameters</h2>
+<table>
+<thead><tr><th>Name</th><th>In</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Parameters}}
+<tr><td><code>{{.Name}}</code></td><td>{{.In}}</td><td>{{template "type" .Type}}</td><td>{{if .Required}}yes{{end}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- if .Body}}
+<h2>Request Body</h2>
+{{- template "content-types" .Body}}
+{{- end}}
+{{- if .Responses}}
+<h2>Responses</h2>
+{{- range .Responses}}
+<h3><span class="code">{{.Code}}</span> {{.Description}}</h3>
+{{- template "content-types" .Content}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}`
+
+// Style of the pages
+const style = `
+* { box-sizing: border-box; }
+body { margin: 0; display: flex; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 15px; color: #222; line-height: 1.5; }
+a { color: #1565c0; text-decoration: none; }
+a:hover { text-decoration: underline; }
+code, pre { font-family: Menlo, Consolas, monospace; font-size: 13px; }
+.sidebar { position: sticky; top: 0; width: 300px; height: 100vh; overflow-y: auto; flex-shrink: 0; padding: 16px; background: #f5f6f8; border-right: 1px solid #dde1e6; }
+.sidebar .brand { font-size: 18px; font-weight: bold; color: #222; }
+.sidebar h3 { margin: 20px 0 6px; font-size: 12px; text-transform: uppercase; color: #666; }
+.sidebar ul { list-style: none; margin: 0; padding: 0; }
+.sidebar li a { display: block; padding: 3px 6px; border-radius: 4px; color: #222; }
+.sidebar li.active a { background: #dde6f3; }
+main { flex-grow: 1; min-width: 0; padding: 24px 40px; max-width: 1100px; }
+h1 { margin-top: 0; }
+h2 { margin-top: 32px; border-bottom: 1px solid #dde1e6; padding-bottom: 4px; }
+.version, .detail { color: #666; font-size: 13px; }
+table { width: 100%; border-collapse: collapse; margin: 8px 0 16px; }
+th, td { text-align: left; vertical-align: top; padding: 6px 8px; border-bottom: 1px solid #e6e8eb; }
+th { background: #f5f6f8; font-size: 13px; }
+.method { display: inline-block; min-width: 56px; padding: 0 6px; border-radius: 3px; background: #757575; color: #fff; font-size: 11px; font-weight: bold; text-align: center; text-transform: uppercase; }
+.method.get { background: #1e88e5; }
+.method.post { background: #43a047; }
+.method.put { background: #fb8c00; }
+.method.patch { background: #00897b; }
+.method.delete { background: #e53935; }
+.code { font-family: Menlo, Consolas, monospace; }
+.media-type { margin: 8px 0; font-weight: bold; }
+pre.example { padding: 12px; overflow-x: auto; background: #f5f6f8; border: 1px solid #dde1e6; border-radius: 4px; }
+`
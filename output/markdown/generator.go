@@ -0,0 +1,548 @@
+// Package markdown generates the documentation into Markdown
+// files, i.e. one file for everything, or one file per tag.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spaceavocado/apidoc/misc"
+	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+type generator struct {
+	verbose bool
+	// OpenAPI version of the document, i.e. 3.0 or 3.1
+	version string
+	// One file per tag, otherwise one file for everything
+	perTag bool
+	// File of the component schemas, one file per tag mode
+	schemasFile string
+	// Reference prefix of the components
+	componentsRef string
+	// Group of the operations without a tag
+	defaultTag string
+	// Slug of the tag file name
+	slugRx *regexp.Regexp
+
+	// Component schemas of the document, by the name
+	schemas map[string]*openapi.Schema
+	// Links of the component schemas, by the name
+	schemaLinks map[string]string
+}
+
+// Operation of the path
+type operation struct {
+	method    string
+	path      string
+	operation *openapi.Operation
+	anchor    string
+}
+
+// Group of the operations by the tag
+type group struct {
+	name string
+	file string
+	ops  []*operation
+}
+
+// Generate the documentation from the given tokens for the main
+// section and for the given endpoints, into the file. In the one
+// file per tag mode, the file is the index of the tags, the other
+// files are written into the folder of the file.
+func (g *generator) Generate(main []token.Token, endpoints [][]token.Token, file string) error {
+	doc := openapi.NewDocument(g.verbose, g.version, main, endpoints)
+	g.schemas = make(map[string]*openapi.Schema, 0)
+	if doc.Components != nil {
+		g.schemas = doc.Components.Schemas
+	}
+	g.schemaLinks = make(map[string]string, len(g.schemas))
+
+	// Output folder
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	files := g.Files(doc, filepath.Base(file))
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files of the documentation, by the file name
+func (g *generator) Files(doc *openapi.Document, index string) map[string][]byte {
+	files := make(map[string][]byte, 0)
+	ops := g.Operations(doc.Paths)
+	webhooks := g.Operations(doc.Webhooks)
+	names := g.SchemaNames()
+
+	// One file for everything
+	if g.perTag == false {
+		a := newAnchors()
+		a.Add(doc.Info.Title)
+		a.Add("Endpoints")
+		for _, op := range ops {
+			op.anchor = a.Add(g.Heading(op))
+		}
+		g.WebhookAnchors(a, webhooks)
+		if len(names) > 0 {
+			a.Add("Schemas")
+		}
+		for _, name := range names {
+			g.schemaLinks[name] = "#" + a.Add(name)
+		}
+
+		b := &bytes.Buffer{}
+		g.WriteInfo(b, doc)
+		g.WriteOperations(b, "Endpoints", "Path", ops)
+		g.WriteWebhooks(b, webhooks)
+		if len(names) > 0 {
+			b.WriteString("\n## Schemas\n")
+			g.WriteSchemas(b, names, "###")
+		}
+		files[index] = b.Bytes()
+		return files
+	}
+
+	// One file per tag, the schemas are in the schemas file
+	a := newAnchors()
+	a.Add("Schemas")
+	for _, name := range names {
+		g.schemaLinks[name] = g.schemasFile + "#" + a.Add(name)
+	}
+	groups := g.Groups(ops, []string{index, g.schemasFile})
+	for _, gr := range groups {
+		a := newAnchors()
+		a.Add(gr.name)
+		a.Add("Endpoints")
+		for _, op := range gr.ops {
+			op.anchor = a.Add(g.Heading(op))
+		}
+		b := &bytes.Buffer{}
+		fmt.Fprintf(b, "# %s\n", gr.name)
+		g.WriteOperations(b, "Endpoints", "Path", gr.ops)
+		files[gr.file] = b.Bytes()
+	}
+	if len(names) > 0 {
+		b := &bytes.Buffer{}
+		b.WriteString("# Schemas\n")
+		g.WriteSchemas(b, names, "##")
+		files[g.schemasFile] = b.Bytes()
+	}
+
+	// Index of the tags, and the webhooks
+	a = newAnchors()
+	a.Add(doc.Info.Title)
+	a.Add("Tags")
+	g.WebhookAnchors(a, webhooks)
+	b := &bytes.Buffer{}
+	g.WriteInfo(b, doc)
+	rows := make([][]string, 0, len(groups))
+	for _, gr := range groups {
+		rows = append(rows, []string{link(cell(gr.name), gr.file), fmt.Sprintf("%d", len(gr.ops))})
+	}
+	b.WriteString("\n## Tags\n\n")
+	b.WriteString(table([]string{"Tag", "Endpoints"}, rows))
+	g.WriteWebhooks(b, webhooks)
+	if len(names) > 0 {
+		fmt.Fprintf(b, "\nSee %s.\n", link("Schemas", g.schemasFile))
+	}
+	files[index] = b.Bytes()
+	return files
+}
+
+// Operations of the paths, or of the webhooks, sorted by the
+// path, and by the canonical order of the methods
+func (g *generator) Operations(items map[string]*openapi.PathItem) []*operation {
+	urls := make([]string, 0, len(items))
+	for url := range items {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	ops := make([]*operation, 0)
+	for _, url := range urls {
+		item := items[url]
+		for _, method := range item.Methods() {
+			ops = append(ops, &operation{
+				method:    method,
+				path:      url,
+				operation: item.Operation(method),
+			})
+		}
+	}
+	return ops
+}
+
+// Groups of the operations by the tag, sorted by the tag, the
+// default group is the last. The files of the groups are named
+// by the tag, numbered if already taken.
+func (g *generator) Groups(ops []*operation, taken []string) []*group {
+	groups := make(map[string]*group, 0)
+	for _, op := range ops {
+		tags := op.operation.Tags
+		if len(tags) == 0 {
+			tags = []string{g.defaultTag}
+		}
+		for _, tag := range tags {
+			if _, ok := groups[tag]; ok == false {
+				groups[tag] = &group{name: tag}
+			}
+			// The operation of many tags has
+			// the anchor in each file
+			o := *op
+			groups[tag].ops = append(groups[tag].ops, &o)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != g.defaultTag {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[g.defaultTag]; ok {
+		names = append(names, g.defaultTag)
+	}
+
+	sorted := make([]*group, 0, len(names))
+	for _, name := range names {
+		base := strings.Trim(g.slugRx.ReplaceAllString(strings.ToLower(name), "-"), "-")
+		if base == "" {
+			base = "tag"
+		}
+		file := base + ".md"
+		for i := 1; misc.StringInSlice(file, taken); i++ {
+			file = fmt.Sprintf("%s-%d.md", base, i)
+		}
+		taken = append(taken, file)
+		groups[name].file = file
+		sorted = append(sorted, groups[name])
+	}
+	return sorted
+}
+
+// SchemaNames of the components, sorted
+func (g *generator) SchemaNames() []string {
+	names := make([]string, 0, len(g.schemas))
+	for name := range g.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Heading of the operation, i.e. the method and the path
+func (g *generator) Heading(op *operation) string {
+	return fmt.Sprintf("%s %s", strings.ToUpper(op.method), op.path)
+}
+
+// WriteInfo about the API
+func (g *generator) WriteInfo(b *bytes.Buffer, doc *openapi.Document) {
+	info := doc.Info
+	fmt.Fprintf(b, "# %s\n", info.Title)
+	if info.Version != "" {
+		fmt.Fprintf(b, "\nVersion: %s\n", info.Version)
+	}
+	if info.Description != "" {
+		fmt.Fprintf(b, "\n%s\n", info.Description)
+	}
+	lines := make([]string, 0)
+	if info.TermsOfService != "" {
+		lines = append(lines, fmt.Sprintf("Terms of Service: %s", info.TermsOfService))
+	}
+	if c := info.Contact; c != nil {
+		contact := c.Name
+		if c.URL != "" {
+			contact = link(orString(c.Name, c.URL), c.URL)
+		}
+		if c.Email != "" {
+			contact = strings.TrimSpace(fmt.Sprintf("%s <%s>", contact, c.Email))
+		}
+		lines = append(lines, fmt.Sprintf("Contact: %s", contact))
+	}
+	if l := info.License; l != nil {
+		license := l.Name
+		if l.URL != "" {
+			license = link(orString(l.Name, l.URL), l.URL)
+		}
+		lines = append(lines, fmt.Sprintf("License: %s", license))
+	}
+	if len(lines) > 0 {
+		fmt.Fprintf(b, "\n%s\n", strings.Join(lines, "  \n"))
+	}
+	if len(doc.Servers) > 0 {
+		b.WriteString("\n**Servers**\n\n")
+		for _, s := range doc.Servers {
+			fmt.Fprintf(b, "- %s", code(s.URL))
+			if s.Description != "" {
+				fmt.Fprintf(b, " %s", s.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// WebhookAnchors of the webhooks section, if any
+func (g *generator) WebhookAnchors(a *anchors, webhooks []*operation) {
+	if len(webhooks) == 0 {
+		return
+	}
+	a.Add("Webhooks")
+	for _, op := range webhooks {
+		op.anchor = a.Add(g.Heading(op))
+	}
+}
+
+// WriteWebhooks section of 3.1, if any
+func (g *generator) WriteWebhooks(b *bytes.Buffer, webhooks []*operation) {
+	if len(webhooks) > 0 {
+		g.WriteOperations(b, "Webhooks", "Name", webhooks)
+	}
+}
+
+// WriteOperations table of the section, i.e. the endpoints
+// or the webhooks, and the sections of the operations
+func (g *generator) WriteOperations(b *bytes.Buffer, section, column string, ops []*operation) {
+	rows := make([][]string, 0, len(ops))
+	for _, op := range ops {
+		rows = append(rows, []string{
+			strings.ToUpper(op.method),
+			link(code(op.path), "#"+op.anchor),
+			cell(op.operation.Summary),
+		})
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", section)
+	b.WriteString(table([]string{"Method", column, "Summary"}, rows))
+	for _, op := range ops {
+		g.WriteOperation(b, op)
+	}
+}
+
+// WriteOperation section, i.e. the parameters,
+// the request body, and the responses
+func (g *generator) WriteOperation(b *bytes.Buffer, op *operation) {
+	o := op.operation
+	fmt.Fprintf(b, "\n### %s\n", g.Heading(op))
+	if o.Summary != "" {
+		fmt.Fprintf(b, "\n%s\n", o.Summary)
+	}
+	if o.Description != "" {
+		fmt.Fprintf(b, "\n%s\n", o.Description)
+	}
+	if o.OperationID != "" {
+		fmt.Fprintf(b, "\nOperation ID: %s\n", code(o.OperationID))
+	}
+
+	// Params
+	if len(o.Parameters) > 0 {
+		rows := make([][]string, 0, len(o.Parameters))
+		for _, p := range o.Parameters {
+			rows = append(rows, []string{
+				code(p.Name),
+				p.In,
+				g.Type(p.Schema),
+				required(p.Required),
+				cell(p.Description),
+			})
+		}
+		b.WriteString("\n**Parameters**\n\n")
+		b.WriteString(table([]string{"Name", "In", "Type", "Required", "Description"}, rows))
+	}
+
+	// Body
+	if o.RequestBody != nil && len(o.RequestBody.Content) > 0 {
+		b.WriteString("\n**Request Body**\n")
+		for _, mt := range mediaTypes(o.RequestBody.Content) {
+			s := o.RequestBody.Content[mt].Schema.Plain(g.schemas)
+			fmt.Fprintf(b, "\n%s: %s\n", code(mt), g.Type(s))
+			if s.InlineObject() {
+				g.WriteTable(b, s, "")
+			}
+		}
+	}
+
+	// Responses, by the code
+	if len(o.Responses) > 0 {
+		codes := make([]string, 0, len(o.Responses))
+		for c := range o.Responses {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+
+		rows := make([][]string, 0, len(codes))
+		inline := make([]string, 0)
+		for _, c := range codes {
+			resp := o.Responses[c]
+			contents := make([]string, 0)
+			for _, mt := range mediaTypes(resp.Content) {
+				s := resp.Content[mt].Schema.Plain(g.schemas)
+				contents = append(contents, fmt.Sprintf("%s: %s", code(mt), g.Type(s)))
+				if s.InlineObject() && misc.StringInSlice(c, inline) == false {
+					inline = append(inline, c)
+				}
+			}
+			rows = append(rows, []string{c, cell(resp.Description), strings.Join(contents, "<br>")})
+		}
+		b.WriteString("\n**Responses**\n\n")
+		b.WriteString(table([]string{"Code", "Description", "Content"}, rows))
+
+		// Inline objects, e.g. the wrapped responses
+		for _, c := range inline {
+			resp := o.Responses[c]
+			for _, mt := range mediaTypes(resp.Content) {
+				if s := resp.Content[mt].Schema.Plain(g.schemas); s.InlineObject() {
+					fmt.Fprintf(b, "\nResponse %s %s:\n", c, code(mt))
+					g.WriteTable(b, s, "")
+				}
+			}
+		}
+	}
+}
+
+// WriteSchemas of the components, by the name,
+// under the headings of the given level
+func (g *generator) WriteSchemas(b *bytes.Buffer, names []string, level string) {
+	for _, name := range names {
+		s := g.schemas[name].Plain(g.schemas)
+		fmt.Fprintf(b, "\n%s %s\n", level, name)
+		if s.Description != "" {
+			fmt.Fprintf(b, "\n%s\n", s.Description)
+		}
+		if s.InlineObject() {
+			g.WriteTable(b, s, "")
+		} else {
+			fmt.Fprintf(b, "\nType: %s\n", g.Type(s))
+		}
+	}
+}
+
+// WriteTable of the object schema, followed by the nested
+// tables of its inline objects, named by the property path
+func (g *generator) WriteTable(b *bytes.Buffer, s *openapi.Schema, path string) {
+	s = s.Plain(g.schemas)
+	if path != "" {
+		fmt.Fprintf(b, "\n%s:\n", code(path))
+	}
+	rows := make([][]string, 0, len(s.Properties))
+	nested := make([]openapi.Property, 0)
+	for _, prop := range s.Properties {
+		ps := prop.Schema.Plain(g.schemas)
+		desc := ps.Description
+		for _, d := range ps.Details() {
+			desc = strings.TrimPrefix(desc+"\n"+d, "\n")
+		}
+		rows = append(rows, []string{
+			code(prop.Name),
+			g.Type(ps),
+			required(misc.StringInSlice(prop.Name, s.Required)),
+			cell(desc),
+		})
+		if ps.InlineObject() || (ps.Type == "array" && ps.Items.Plain(g.schemas).InlineObject()) {
+			nested = append(nested, openapi.Property{Name: prop.Name, Schema: ps})
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(table([]string{"Name", "Type", "Required", "Description"}, rows))
+
+	for _, prop := range nested {
+		name := strings.TrimPrefix(path+"."+prop.Name, ".")
+		if prop.Schema.InlineObject() {
+			g.WriteTable(b, prop.Schema, name)
+		} else {
+			g.WriteTable(b, prop.Schema.Items, name+"[]")
+		}
+	}
+}
+
+// Type label of the schema, the components
+// are linked to their schemas
+func (g *generator) Type(s *openapi.Schema) string {
+	s = s.Plain(g.schemas)
+	switch {
+	case s == nil:
+		return "any"
+	case s.Ref != "":
+		name := strings.TrimPrefix(s.Ref, g.componentsRef)
+		if l, ok := g.schemaLinks[name]; ok {
+			return link(name, l)
+		}
+		return name
+	case len(s.AllOf) == 1:
+		return g.Type(s.AllOf[0])
+	case s.Type == "array":
+		return "array of " + g.Type(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map of " + g.Type(s.AdditionalProperties)
+	}
+	t, ok := s.Type.(string)
+	if ok == false || t == "" {
+		return "any"
+	}
+	if s.Format != "" {
+		t = fmt.Sprintf("%s (%s)", t, s.Format)
+	}
+	return t
+}
+
+// orString returns the value, or the fallback if empty
+func orString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// required label
+func required(req bool) string {
+	if req {
+		return "yes"
+	}
+	return ""
+}
+
+// mediaTypes of the content, sorted by the name
+func mediaTypes(content map[string]*openapi.MediaType) []string {
+	mts := make([]string, 0, len(content))
+	for mt := range content {
+		mts = append(mts, mt)
+	}
+	sort.Strings(mts)
+	return mts
+}
+
+// NewGenerator instance.
+// Version of the OpenAPI document, 3.0 is used if not set.
+// Per tag, one file per tag is generated, otherwise one file for everything.
+func NewGenerator(verbose bool, version string, perTag bool) output.Generator {
+	if version == "" {
+		version = openapi.Version30
+	}
+	return &generator{
+		verbose:       verbose,
+		version:       version,
+		perTag:        perTag,
+		schemasFile:   "schemas.md",
+		componentsRef: "#/components/schemas/",
+		defaultTag:    "default",
+		slugRx:        regexp.MustCompile(`[^a-z0-9]+`),
+	}
+}
@@ -0,0 +1,313 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+// endpoint tokens of the pet operation
+func endpoint(id, url, method, tag string) []token.Token {
+	return []token.Token{
+		{Key: "id", Meta: map[string]string{"value": id}},
+		{Key: "summary", Meta: map[string]string{"value": "Summary of " + id}},
+		{Key: "tag", Meta: map[string]string{"value": tag}},
+		{Key: "produce", Meta: map[string]string{"value": "json"}},
+		{Key: "param", Meta: map[string]string{"key": "id", "in": "path", "type": "{int}", "req": "true", "desc": "Pet ID"}},
+		{Key: "bref", Meta: map[string]string{"pkg.type": "github.com/pkg.Pet", "key": "name", "type": "string", "req": "true", "desc": "Name | nick"}},
+		{Key: "success", Meta: map[string]string{"code": "200", "type": "{object}", "ref": "github.com/pkg.Pet", "desc": "OK"}},
+		{Key: "router", Meta: map[string]string{"url": url, "method": method}},
+	}
+}
+
+var main = []token.Token{
+	{Key: "title", Meta: map[string]string{"value": "Pets"}},
+	{Key: "ver", Meta: map[string]string{"value": "1.0"}},
+	{Key: "server", Meta: map[string]string{"url": "https://pets.go/v1", "desc": "Production"}},
+}
+
+func TestGenerate(t *testing.T) {
+	g := NewGenerator(false, "", false)
+
+	dir := "tmp-markdown"
+	defer os.RemoveAll(dir)
+
+	// Invalid output folder
+	err := g.Generate([]token.Token{{}}, [][]token.Token{{{}}}, "generator.go/tmp/index.md")
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// Invalid file
+	os.MkdirAll(filepath.Join(dir, "index.md"), os.ModePerm)
+	err = g.Generate([]token.Token{{}}, [][]token.Token{{{}}}, filepath.Join(dir, "index.md"))
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	os.RemoveAll(dir)
+
+	err = g.Generate(main,
+		[][]token.Token{
+			endpoint("get-pet", "/pets/{id}", "[get]", "Pet"),
+			endpoint("delete-pet", "/pets/{id}", "[delete]", "Pet, Admin"),
+		},
+		filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	res := string(b)
+	contains := []string{
+		"# Pets\n\nVersion: 1.0\n",
+		"- `https://pets.go/v1` Production\n",
+		"| GET | [`/pets/{id}`](#get-petsid) | Summary of get-pet |\n| DELETE | [`/pets/{id}`](#delete-petsid) | Summary of delete-pet |\n",
+		"### GET /pets/{id}\n\nSummary of get-pet\n\nOperation ID: `get-pet`\n",
+		"| `id` | path | integer | yes | Pet ID |\n",
+		"| 200 | OK | `application/json`: [Pet](#pet) |\n",
+		"## Schemas\n\n### Pet\n",
+		"| `name` | string | yes | Name \\| nick |\n",
+	}
+	for _, c := range contains {
+		if strings.Contains(res, c) == false {
+			t.Errorf("Expected \"%s\", got \"%s\"", c, res)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "schemas.md")); err == nil {
+		t.Errorf("Expected no schemas file in the one file mode")
+	}
+}
+
+func TestGeneratePerTag(t *testing.T) {
+	g := NewGenerator(false, "", true)
+
+	dir := "tmp-markdown-tags"
+	defer os.RemoveAll(dir)
+
+	err := g.Generate(main,
+		[][]token.Token{
+			endpoint("get-pet", "/pets/{id}", "[get]", "Pet"),
+			endpoint("delete-pet", "/pets/{id}", "[delete]", "Pet, Admin"),
+			endpoint("", "/stats", "[get]", ""),
+		},
+		filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	read := func(file string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		return string(b)
+	}
+	tests := map[string][]string{
+		"index.md": {
+			"# Pets\n",
+			"## Tags\n\n| Tag | Endpoints |\n| --- | --- |\n| [Admin](admin.md) | 1 |\n| [Pet](pet.md) | 2 |\n| [default](default.md) | 1 |\n",
+			"See [Schemas](schemas.md).\n",
+		},
+		"pet.md": {
+			"# Pet\n\n## Endpoints\n",
+			"| GET | [`/pets/{id}`](#get-petsid) | Summary of get-pet |\n",
+			"| 200 | OK | `application/json`: [Pet](schemas.md#pet) |\n",
+		},
+		"admin.md": {
+			"| DELETE | [`/pets/{id}`](#delete-petsid) | Summary of delete-pet |\n",
+			"### DELETE /pets/{id}\n",
+		},
+		"default.md": {
+			"| GET | [`/stats`](#get-stats) | Summary of  |\n",
+		},
+		"schemas.md": {
+			"# Schemas\n\n## Pet\n",
+			"| `name` | string | yes | Name \\| nick |\n",
+		},
+	}
+	for file, contains := range tests {
+		res := read(file)
+		for _, c := range contains {
+			if strings.Contains(res, c) == false {
+				t.Errorf("Expected \"%s\" in %s, got \"%s\"", c, file, res)
+			}
+		}
+	}
+}
+
+func TestGenerateVersion31(t *testing.T) {
+	dir := "tmp-markdown-31"
+	defer os.RemoveAll(dir)
+
+	// The tokens are transformed by the generator
+	endpoints := func() [][]token.Token {
+		prop := func(meta map[string]string) token.Token {
+			meta["pkg.type"] = "github.com/pkg.Pet"
+			if _, ok := meta["req"]; ok == false {
+				meta["req"] = "false"
+			}
+			return token.Token{Key: "bref", Meta: meta}
+		}
+		return [][]token.Token{
+			{
+				{Key: "summary", Meta: map[string]string{"value": "New pet"}},
+				{Key: "webhook", Meta: map[string]string{"url": "newPet", "method": "post"}},
+				{Key: "accept", Meta: map[string]string{"value": "json"}},
+				{Key: "body", Meta: map[string]string{"value": "github.com/pkg.Pet"}},
+				prop(map[string]string{"key": "name", "type": "string", "nullable": "true", "example": "\"Rex\""}),
+				prop(map[string]string{"key": "kind", "type": "string", "enum": "[\"dog\"]"}),
+				prop(map[string]string{"key": "owner", "type": "github.com/pkg.Pet", "nullable": "true"}),
+				prop(map[string]string{"key": "home", "type": "object", "desc": "Home of the pet"}),
+				prop(map[string]string{"key": "home.geo", "type": "object"}),
+				prop(map[string]string{"key": "home.geo.lat", "type": "number"}),
+				{Key: "success", Meta: map[string]string{"code": "200", "type": "string", "desc": "OK"}},
+			},
+			{
+				{Key: "summary", Meta: map[string]string{"value": "List pets"}},
+				{Key: "router", Meta: map[string]string{"url": "/pets", "method": "get"}},
+				{Key: "success", Meta: map[string]string{"code": "200", "type": "{array}", "ref": "github.com/pkg.Pet", "desc": "OK"}},
+			},
+		}
+	}
+
+	// The webhooks are skipped in 3.0
+	err := NewGenerator(false, openapi.Version30, false).Generate(main, endpoints(), filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "index.md"))
+	if strings.Contains(string(b), "Webhooks") {
+		t.Errorf("Expected no webhooks, got \"%s\"", b)
+	}
+	os.RemoveAll(dir)
+
+	err = NewGenerator(false, openapi.Version31, false).Generate(main, endpoints(), filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "index.md"))
+	contains := []string{
+		"## Endpoints\n\n| Method | Path | Summary |\n| --- | --- | --- |\n| GET | [`/pets`](#get-pets) | List pets |\n",
+		"## Webhooks\n\n| Method | Name | Summary |\n| --- | --- | --- |\n| POST | [`newPet`](#post-newpet) | New pet |\n",
+		"### POST newPet\n\nNew pet\n",
+		"| `name` | string |  | example: \"Rex\"<br>nullable |\n",
+		"| `kind` | string |  | enum: \"dog\" |\n",
+		"| `owner` | [Pet](#pet) |  | nullable |\n",
+		"| `home` | object |  | Home of the pet |\n",
+		"\n`home.geo`:\n\n| Name | Type | Required | Description |\n| --- | --- | --- | --- |\n| `lat` | number |  |  |\n",
+	}
+	for _, c := range contains {
+		if strings.Contains(string(b), c) == false {
+			t.Errorf("Expected \"%s\", got \"%s\"", c, b)
+		}
+	}
+
+	// The webhooks are in the index of the tags
+	os.RemoveAll(dir)
+	err = NewGenerator(false, openapi.Version31, true).Generate(main, endpoints(), filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "index.md"))
+	contains = []string{
+		"| [default](default.md) | 1 |\n",
+		"## Webhooks\n\n| Method | Name | Summary |\n| --- | --- | --- |\n| POST | [`newPet`](#post-newpet) | New pet |\n",
+		"| 200 | OK | `text/plain`: string |\n",
+	}
+	for _, c := range contains {
+		if strings.Contains(string(b), c) == false {
+			t.Errorf("Expected \"%s\", got \"%s\"", c, b)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	g := NewGenerator(false, "", true).(*generator)
+	ops := []*operation{
+		{operation: &openapi.Operation{Tags: []string{"Pet Store"}}},
+		{operation: &openapi.Operation{Tags: []string{"schemas"}}},
+		{operation: &openapi.Operation{Tags: []string{"Pet store"}}},
+		{operation: &openapi.Operation{}},
+	}
+	expected := []string{"pet-store.md", "pet-store-1.md", "schemas-1.md", "default.md"}
+	groups := g.Groups(ops, []string{"index.md", "schemas.md"})
+	if len(groups) != len(expected) {
+		t.Errorf("Expected %d groups, got %d", len(expected), len(groups))
+		return
+	}
+	for i, gr := range groups {
+		if gr.file != expected[i] {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], gr.file)
+		}
+	}
+}
+
+func TestType(t *testing.T) {
+	g := NewGenerator(false, "", false).(*generator)
+	g.schemaLinks = map[string]string{"Person": "#person"}
+	tests := []struct {
+		schema   *openapi.Schema
+		expected string
+	}{
+		{nil, "any"},
+		{&openapi.Schema{}, "any"},
+		{&openapi.Schema{Type: "string", Format: "email"}, "string (email)"},
+		{&openapi.Schema{Ref: "#/components/schemas/Person"}, "[Person](#person)"},
+		{&openapi.Schema{Ref: "#/components/schemas/Tag"}, "Tag"},
+		{&openapi.Schema{AllOf: []*openapi.Schema{{Ref: "#/components/schemas/Person"}}}, "[Person](#person)"},
+		{&openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "integer"}}, "array of integer"},
+		{&openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/Person"}}}, "map of array of [Person](#person)"},
+		{&openapi.Schema{Type: []string{"string", "null"}}, "string"},
+		{&openapi.Schema{AnyOf: []*openapi.Schema{{Ref: "#/components/schemas/Person"}, {Type: "null"}}}, "[Person](#person)"},
+	}
+	for _, test := range tests {
+		if res := g.Type(test.schema); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	g := NewGenerator(false, "", false).(*generator)
+	s := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: openapi.Properties{
+			{Name: "name", Schema: &openapi.Schema{Type: "string", Description: "Name", MinLength: json.RawMessage("1")}},
+			{Name: "home", Schema: &openapi.Schema{Type: "object", Properties: openapi.Properties{
+				{Name: "city", Schema: &openapi.Schema{Type: "string"}},
+			}}},
+			{Name: "toys", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "object", Properties: openapi.Properties{
+				{Name: "kind", Schema: &openapi.Schema{Type: "string"}},
+			}}}},
+		},
+	}
+	expected := "\n| Name | Type | Required | Description |\n| --- | --- | --- | --- |\n" +
+		"| `name` | string | yes | Name<br>min length: 1 |\n" +
+		"| `home` | object |  |  |\n" +
+		"| `toys` | array of object |  |  |\n" +
+		"\n`home`:\n\n| Name | Type | Required | Description |\n| --- | --- | --- | --- |\n" +
+		"| `city` | string |  |  |\n" +
+		"\n`toys[]`:\n\n| Name | Type | Required | Description |\n| --- | --- | --- | --- |\n" +
+		"| `kind` | string |  |  |\n"
+	b := &bytes.Buffer{}
+	g.WriteTable(b, s, "")
+	if res := b.String(); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
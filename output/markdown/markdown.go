@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// anchors of the headings of the file, i.e. the
+// duplicated headings are numbered as by GitHub
+type anchors struct {
+	used map[string]int
+}
+
+// Add the heading, and get its anchor
+func (a *anchors) Add(heading string) string {
+	b := &strings.Builder{}
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	anchor := b.String()
+	if n, ok := a.used[anchor]; ok {
+		a.used[anchor] = n + 1
+		anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+	} else {
+		a.used[anchor] = 0
+	}
+	return anchor
+}
+
+// newAnchors of the file
+func newAnchors() *anchors {
+	return &anchors{used: make(map[string]int, 0)}
+}
+
+// cell of the table, i.e. the pipes are escaped,
+// the new lines are the line breaks
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// code span of the text
+func code(s string) string {
+	if strings.Contains(s, "`") {
+		return fmt.Sprintf("`` %s ``", s)
+	}
+	return fmt.Sprintf("`%s`", s)
+}
+
+// link of the text, the text is escaped
+func link(text, href string) string {
+	text = strings.NewReplacer("[", "\\[", "]", "\\]").Replace(text)
+	return fmt.Sprintf("[%s](%s)", text, href)
+}
+
+// table of the rows, by the header
+func table(header []string, rows [][]string) string {
+	b := &strings.Builder{}
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString(strings.Repeat("| --- ", len(header)) + "|\n")
+	for _, r := range rows {
+		b.WriteString("| " + strings.Join(r, " | ") + " |\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	a := newAnchors()
+	tests := []struct {
+		heading  string
+		expected string
+	}{
+		{"Pet Store API", "pet-store-api"},
+		{"GET /pets/{id}", "get-petsid"},
+		{"DELETE /pets/{id}", "delete-petsid"},
+		{"GET /pets/{id}", "get-petsid-1"},
+		{"GET /pets/{id}", "get-petsid-2"},
+		{"snake_case-Name", "snake_case-name"},
+	}
+	for _, test := range tests {
+		if res := a.Add(test.heading); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestCell(t *testing.T) {
+	tests := map[string]string{
+		"plain":        "plain",
+		"a|b":          "a\\|b",
+		"line\nline":   "line<br>line",
+		"line\r\nline": "line<br>line",
+	}
+	for input, expected := range tests {
+		if res := cell(input); res != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := map[string]string{
+		"name": "`name`",
+		"a`b":  "`` a`b ``",
+	}
+	for input, expected := range tests {
+		if res := code(input); res != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	expected := "[array\\[\\]](#pet)"
+	if res := link("array[]", "#pet"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
+
+func TestTable(t *testing.T) {
+	expected := "| Name | Type |\n| --- | --- |\n| `id` | integer |\n"
+	if res := table([]string{"Name", "Type"}, [][]string{{"`id`", "integer"}}); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
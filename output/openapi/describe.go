@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// InlineObject checks if the schema is the object
+// described by the properties, i.e. not a map
+func (s *Schema) InlineObject() bool {
+	return s != nil && s.Type == "object" && s.Ref == "" && s.AdditionalProperties == nil && len(s.Properties) > 0
+}
+
+// Plain copy of the schema, i.e. the 3.1 constructs are described
+// as the 3.0 schema: the null type, or the null branch of anyOf, is
+// the nullable flag, the const is the single enum value, the first
+// example is the example. The references of the definitions of the
+// components are resolved into the inline objects.
+func (s *Schema) Plain(schemas map[string]*Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	p := *s
+	if def, ok := defSchema(schemas, s.Ref); ok {
+		p = *def
+		// The description belongs to the property
+		if s.Description != "" {
+			p.Description = s.Description
+		}
+	}
+	if types, ok := p.Type.([]string); ok {
+		p.Type = ""
+		for _, t := range types {
+			if t == "null" {
+				p.Nullable = true
+			} else {
+				p.Type = t
+			}
+		}
+	}
+	if len(p.AnyOf) == 2 && p.Ref == "" {
+		for i, branch := range p.AnyOf {
+			if branch.Type == "null" {
+				p.Ref, p.AnyOf, p.Nullable = p.AnyOf[1-i].Ref, nil, true
+				break
+			}
+		}
+	}
+	if p.Const != nil && p.Enum == nil {
+		p.Enum, p.Const = json.RawMessage(fmt.Sprintf("[%s]", MetaString(p.Const))), nil
+	}
+	if p.Example == nil && len(p.Examples) > 0 {
+		p.Example, p.Examples = p.Examples[0], nil
+	}
+	return &p
+}
+
+// Details of the schema, i.e. the enum values, the
+// validation constraints, and the annotations
+func (s *Schema) Details() []string {
+	details := make([]string, 0)
+	s = s.Plain(nil)
+	schemas := []*Schema{s}
+	if s.Type == "array" && s.Items != nil {
+		schemas = append(schemas, s.Items.Plain(nil))
+	}
+	for _, s := range schemas {
+		if s.Enum != nil {
+			details = append(details, fmt.Sprintf("enum: %s", enumValues(s.Enum)))
+		}
+		for _, d := range []struct {
+			name  string
+			value interface{}
+		}{
+			{"minimum", s.Minimum},
+			{"exclusive minimum", s.ExclusiveMinimum},
+			{"maximum", s.Maximum},
+			{"exclusive maximum", s.ExclusiveMaximum},
+			{"min length", s.MinLength},
+			{"max length", s.MaxLength},
+			{"min items", s.MinItems},
+			{"max items", s.MaxItems},
+			{"min properties", s.MinProperties},
+			{"max properties", s.MaxProperties},
+			{"default", s.Default},
+			{"example", s.Example},
+		} {
+			if d.value != nil {
+				details = append(details, fmt.Sprintf("%s: %s", d.name, MetaString(d.value)))
+			}
+		}
+		if s.Pattern != "" {
+			details = append(details, fmt.Sprintf("pattern: %s", s.Pattern))
+		}
+	}
+	for _, d := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"nullable", s.Nullable},
+		{"read only", s.ReadOnly},
+		{"write only", s.WriteOnly},
+		{"deprecated", s.Deprecated},
+	} {
+		if MetaString(d.value) == "true" {
+			details = append(details, d.name)
+		}
+	}
+	return details
+}
+
+// MetaString of the meta value, i.e. the JSON
+// value, or the string
+func MetaString(v interface{}) string {
+	switch m := v.(type) {
+	case nil:
+		return ""
+	case json.RawMessage:
+		return string(m)
+	case string:
+		return m
+	}
+	return fmt.Sprint(v)
+}
+
+// defSchema of the reference of the definition of the component,
+// e.g. #/components/schemas/Person/$defs/address.geo
+func defSchema(schemas map[string]*Schema, ref string) (*Schema, bool) {
+	prefix := "#/components/schemas/"
+	path := strings.SplitN(strings.TrimPrefix(ref, prefix), "/$defs/", 2)
+	if strings.HasPrefix(ref, prefix) == false || len(path) != 2 {
+		return nil, false
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	comp, ok := schemas[unescape.Replace(path[0])]
+	if ok == false {
+		return nil, false
+	}
+	def, ok := comp.Defs[unescape.Replace(path[1])]
+	return def, ok
+}
+
+// enumValues listed, comma separated
+func enumValues(enum interface{}) string {
+	values := make([]json.RawMessage, 0)
+	raw, ok := enum.(json.RawMessage)
+	if ok == false || json.Unmarshal(raw, &values) != nil {
+		return MetaString(enum)
+	}
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = string(v)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,106 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInlineObject(t *testing.T) {
+	tests := []struct {
+		schema   *Schema
+		expected bool
+	}{
+		{nil, false},
+		{&Schema{Type: "object"}, false},
+		{&Schema{Type: "object", Properties: Properties{{Name: "a", Schema: &Schema{}}}}, true},
+		{&Schema{Type: "object", AdditionalProperties: &Schema{}, Properties: Properties{{Name: "a", Schema: &Schema{}}}}, false},
+		{&Schema{Ref: "#/components/schemas/A"}, false},
+	}
+	for i, test := range tests {
+		if res := test.schema.InlineObject(); res != test.expected {
+			t.Errorf("%d: Expected %t, got %t", i, test.expected, res)
+		}
+	}
+}
+
+func TestDetails(t *testing.T) {
+	s := &Schema{
+		Type:       "array",
+		MinItems:   json.RawMessage("1"),
+		Deprecated: json.RawMessage("true"),
+		Nullable:   true,
+		ReadOnly:   json.RawMessage("false"),
+		Items: &Schema{
+			Type:             "integer",
+			Enum:             json.RawMessage("[1, 2]"),
+			Minimum:          json.RawMessage("0"),
+			ExclusiveMinimum: json.RawMessage("true"),
+			Default:          "1",
+		},
+	}
+	expected := "min items: 1|enum: 1, 2|minimum: 0|exclusive minimum: true|default: 1|nullable|deprecated"
+	if res := strings.Join(s.Details(), "|"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+
+	// 3.1
+	s = &Schema{
+		Type:     []string{"integer", "null"},
+		Const:    json.RawMessage("1"),
+		Examples: []interface{}{json.RawMessage("1")},
+	}
+	expected = "enum: 1|example: 1|nullable"
+	if res := strings.Join(s.Details(), "|"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+
+	expected = "enum: a,b|pattern: ^[a-z]+$"
+	s = &Schema{Type: "string", Pattern: "^[a-z]+$", Enum: "a,b"}
+	if res := strings.Join(s.Details(), "|"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
+
+func TestPlain(t *testing.T) {
+	geo := &Schema{Type: "object", Properties: Properties{{Name: "lat", Schema: &Schema{Type: "number"}}}}
+	schemas := map[string]*Schema{
+		"Person": {Type: "object", Defs: map[string]*Schema{"address.geo": geo}},
+		"a/b":    {Type: "object", Defs: map[string]*Schema{"x~y": geo}},
+	}
+	tests := []struct {
+		schema   *Schema
+		expected *Schema
+	}{
+		{nil, nil},
+		{&Schema{Type: "string"}, &Schema{Type: "string"}},
+		{&Schema{Type: []string{"string", "null"}}, &Schema{Type: "string", Nullable: true}},
+		{&Schema{AnyOf: []*Schema{{Ref: "#/components/schemas/Person"}, {Type: "null"}}}, &Schema{Ref: "#/components/schemas/Person", Nullable: true}},
+		{&Schema{Type: "string", Const: json.RawMessage(`"dog"`)}, &Schema{Type: "string", Enum: json.RawMessage(`["dog"]`)}},
+		{&Schema{Type: "string", Examples: []interface{}{"Peter"}}, &Schema{Type: "string", Example: "Peter"}},
+		{&Schema{Ref: "#/components/schemas/Person/$defs/address.geo", Description: "Geo"}, &Schema{Type: "object", Description: "Geo", Properties: geo.Properties}},
+		{&Schema{Ref: "#/components/schemas/a~1b/$defs/x~0y"}, geo},
+		{&Schema{Ref: "#/components/schemas/Pet/$defs/address"}, &Schema{Ref: "#/components/schemas/Pet/$defs/address"}},
+	}
+	for i, test := range tests {
+		res := test.schema.Plain(schemas)
+		if test.expected == nil {
+			if res != nil {
+				t.Errorf("%d: Expected nil, got %v", i, res)
+			}
+			continue
+		}
+		expected, _ := json.Marshal(test.expected)
+		got, _ := json.Marshal(res)
+		if string(got) != string(expected) {
+			t.Errorf("%d: Expected \"%s\", got \"%s\"", i, expected, got)
+		}
+	}
+
+	// The schema is not changed
+	s := &Schema{Type: []string{"string", "null"}}
+	s.Plain(nil)
+	if _, ok := s.Type.([]string); ok == false {
+		t.Errorf("Expected the type unchanged, got %v", s.Type)
+	}
+}
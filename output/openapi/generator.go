@@ -4,9 +4,7 @@ package openapi
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -15,13 +13,21 @@ import (
 	"github.com/spaceavocado/apidoc/token"
 )
 
+// Versions of the OpenAPI specification
+const (
+	// Version30 of the OpenAPI specification
+	Version30 = "3.0.2"
+	// Version31 of the OpenAPI specification,
+	// i.e. the schemas are JSON Schema 2020-12
+	Version31 = "3.1.0"
+)
+
 type generator struct {
 	verbose bool
 	// OpenAPI version
 	version string
-
-	// Output buffer
-	buffer buffer
+	// Output formats, i.e. yaml, json
+	formats []string
 
 	// Components unique names
 	// References extracted from the documentation might have
@@ -31,9 +37,9 @@ type generator struct {
 	// and clean simplified name, i.e. components have
 	// nice struct names in the documentation
 	compMapping map[string]string
-	// Component buffer cache
-	// comp.name -> buffer lines
-	compCache map[string][]string
+	// Component schema cache
+	// comp.name -> schema
+	compCache map[string]*Schema
 	// Component token keys
 	compTokenKeys []string
 
@@ -53,27 +59,46 @@ type generator struct {
 	prtMetaKey string
 	// Meta key used for property/filed name data type
 	typeMetaKey string
+	// Meta key used for property/filed enum values
+	enumMetaKey string
+	// Meta key used for property/filed format
+	formatMetaKey string
+	// Meta key used for map value data type
+	valueMetaKey string
+	// Meta keys used for the validation constraints
+	// of the value, the array and the map respectively
+	constraintKeys    []string
+	arrConstraintKeys []string
+	mapConstraintKeys []string
+	// Meta keys used for the property annotations
+	annotationKeys []string
 
 	// Token meta values transformation mapping
 	// TokenKey -> MetaKey -> transformation
 	trs map[string]map[string]transformation
 }
 
-// DataWrapper structure holds the generated
-// data wrapper output annotation. Depth indicates
-// where the data pointer is horizontally, pos
-// indicates the vertical position.
-// The data pointer is the object prop used to
-// contain the data object, wrapped in this wrapper.
+// DataWrapper structure holds the parsed data wrapper
+// object, and the path of the property names leading
+// to the data pointer. The data pointer is the object
+// prop used to contain the data object, wrapped in
+// this wrapper.
 type dataWrapper struct {
-	pos   int
-	depth int
-	lines []string
+	schema *Schema
+	path   []string
 }
 
 // Generate the documentation from the given tokens for the
 // main section and for the given endpoints, into the file.
+// The document is written in each format, i.e. the extension
+// of the file is replaced by the format.
 func (g *generator) Generate(main []token.Token, endpoints [][]token.Token, file string) error {
+	return output.WriteFiles(g.Document(main, endpoints), file, g.formats)
+}
+
+// Document model built from the given tokens for
+// the main section and for the given endpoints
+func (g *generator) Document(main []token.Token, endpoints [][]token.Token) *Document {
 	// Transform meta
 	for _, t := range main {
 		if _, ok := g.trs[t.Key]; ok {
@@ -96,219 +121,214 @@ func (g *generator) Generate(main []token.Token, endpoints [][]token.Token, file
 		}
 	}
 
+	doc := &Document{
+		OpenAPI: g.version,
+		Paths:   make(map[string]*PathItem, 0),
+	}
+
 	// Resolve main secion
-	g.MainSection(main)
+	g.MainSection(doc, main)
+
+	// Name all components first, i.e. the names
+	// do not depend on the order of the endpoints
+	g.NameComponents(endpoints)
 
-	// Wrappers and references
+	// References and wrappers, i.e. the wrappers
+	// might reference the components
 	for _, e := range endpoints {
-		g.ResolveWrappers(e)
 		g.ResolveComponents(e)
+		g.ResolveWrappers(e)
 	}
 
-	// Components
+	// Components, by the component name
 	if len(g.compCache) > 0 {
-		g.buffer.Label("components", 0)
-		g.buffer.Label("schemas", 1)
-		for _, c := range g.compCache {
-			for _, l := range c {
-				g.buffer.Write(l, 2)
-			}
+		doc.Components = &Components{
+			Schemas: make(map[string]*Schema, len(g.compCache)),
+		}
+		names := make([]string, 0, len(g.compCache))
+		for name := range g.compCache {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			g.NameComponent(name)
+			doc.Components.Schemas[g.compMapping[name]] = g.compCache[name]
 		}
 	}
 
-	// Resolved endpoints
-	// Used to prevent re-generation of sibling endpoints
-	resolved := make([]string, 0, len(endpoints))
-
-	// Resolve endpoints
-	g.buffer.Label("paths", 0)
-	for index, e := range endpoints {
-		if t, ok := g.GetToken(e, "router"); ok {
-			if m, ok := g.TokenMeta(t, "url"); ok {
-				if misc.StringInSlice(m, resolved) {
-					continue
-				}
-				resolved = append(resolved, m)
-				g.buffer.Label(m, 1)
-
-				// Get sibling endpoints
-				group := [][]token.Token{e}
-				if index < len(endpoints)-1 {
-					if siblings := g.SiblingEndpoints(m, endpoints[index+1:]); len(siblings) > 0 {
-						group = append(group, siblings...)
-					}
-				}
-
-				// Resolved groupped endpoints
-				for _, e := range group {
-					// Tags
-					tags := make([]string, 0)
-					if t, ok := g.GetToken(e, "tag"); ok {
-						if m, ok := g.TokenMeta(t, "value"); ok && len(m) > 0 {
-							tags = g.ParseArray(m, trsEmpty)
-						}
-					}
+	// Resolve endpoints, the methods of the
+	// path in the canonical order
+	for _, url := range g.SortedURLs(endpoints, "router") {
+		doc.Paths[url] = g.PathItem(url, "router", endpoints)
+	}
 
-					t, _ := g.GetToken(e, "router")
-
-					// Methods
-					if m, ok := g.TokenMeta(t, "method"); ok {
-						methods := g.ParseArray(m, trsEmpty)
-						for _, method := range methods {
-							g.buffer.Label(method, 2)
-							if t, ok := g.GetToken(e, "summary"); ok {
-								g.BufferTokenMeta(t, "value", "summary", 3)
-							}
-							if t, ok := g.GetToken(e, "id"); ok {
-								g.BufferTokenMeta(t, "value", "operationId", 3)
-							}
-							if t, ok := g.GetToken(e, "desc"); ok {
-								g.BufferTokenMeta(t, "value", "description", 3)
-							}
-
-							// Tags
-							if len(tags) > 0 {
-								g.buffer.Label("tags", 3)
-								for _, tag := range tags {
-									g.buffer.Line(fmt.Sprintf("- %s", tag), 3)
-								}
-							}
-
-							// Params
-							if params := g.GetTokens(e, "param"); len(params) > 0 {
-								g.ParamsSection(params, 3)
-							}
-
-							// Body
-							if body, ok := g.GetToken(e, "body"); ok {
-								if t, ok := g.GetToken(e, "accept"); ok {
-									if m, ok := g.TokenMeta(t, "value"); ok && len(m) > 0 {
-										mts := g.ParseArray(m, g.trs["accept"]["value"])
-										g.BodySection(body, mts, 3)
-									}
-								}
-							}
-
-							// Response
-							g.ResponseSection(index, e, 3)
-						}
-					}
-				}
+	// Resolve webhooks, supported since 3.1
+	for _, name := range g.SortedURLs(endpoints, "webhook") {
+		if g.version != Version31 {
+			if g.verbose {
+				log.Warnf("generator: webhook \"%s\" skipped, webhooks require OpenAPI %s", name, Version31)
 			}
+			continue
+		}
+		if doc.Webhooks == nil {
+			doc.Webhooks = make(map[string]*PathItem, 0)
 		}
+		doc.Webhooks[name] = g.PathItem(name, "webhook", endpoints)
 	}
 
-	// OpenAPI version
-	g.buffer.Write(fmt.Sprintf("openapi: \"%s\"", g.version), 0)
-
-	// Output folder
-	err := os.MkdirAll(filepath.Dir(file), os.ModePerm)
-	if err != nil {
-		return err
+	if g.version == Version31 {
+		g.Upgrade(doc)
 	}
+	return doc
+}
 
-	// Save the output file
-	fp, err := os.Create(file)
-	if err != nil {
-		return err
+// PathItem of the url, or of the webhook name,
+// resolved from the endpoints by the token key
+func (g *generator) PathItem(url, key string, endpoints [][]token.Token) *PathItem {
+	item := &PathItem{}
+	for _, op := range g.Operations(url, key, endpoints) {
+		if item.SetOperation(op.method, g.Operation(op.index, endpoints[op.index])) == false && g.verbose {
+			log.Warnf("generator: unsupported method \"%s\" of the path \"%s\"", op.method, url)
+		}
 	}
-	fp.Write([]byte(g.buffer.Flush()))
-	return fp.Close()
+	return item
 }
 
 // MainSection processing
-func (g *generator) MainSection(main []token.Token) {
+func (g *generator) MainSection(doc *Document, main []token.Token) {
 	// Info section
-	g.buffer.Label("info", 0)
 	if t, ok := g.GetToken(main, "title"); ok {
-		g.BufferTokenMeta(t, "value", "title", 1)
+		doc.Info.Title, _ = g.TokenMeta(t, "value")
 	}
 	if t, ok := g.GetToken(main, "ver"); ok {
-		g.BufferTokenMeta(t, "value", "version", 1)
+		doc.Info.Version, _ = g.TokenMeta(t, "value")
 	}
 	if t, ok := g.GetToken(main, "desc"); ok {
-		g.BufferTokenMeta(t, "value", "description", 1)
+		doc.Info.Description, _ = g.TokenMeta(t, "value")
+	}
+	if t, ok := g.GetToken(main, "terms"); ok {
+		doc.Info.TermsOfService, _ = g.TokenMeta(t, "value")
 	}
 
 	// Contact
 	if col := g.GetTokensByPrefix(main, "contact."); len(col) > 0 {
-		g.buffer.Label("contact", 1)
+		doc.Info.Contact = &Contact{}
 		for _, t := range col {
-			if m, ok := t.Meta["value"]; ok {
-				name := strings.TrimPrefix(t.Key, "contact.")
-				g.buffer.KeyValue(name, trsSafeValue(m), 2)
+			switch t.Key {
+			case "contact.name":
+				doc.Info.Contact.Name = t.Meta["value"]
+			case "contact.url":
+				doc.Info.Contact.URL = t.Meta["value"]
+			case "contact.email":
+				doc.Info.Contact.Email = t.Meta["value"]
 			}
 		}
 	}
 
 	// License
 	if col := g.GetTokensByPrefix(main, "lic."); len(col) > 0 {
-		g.buffer.Label("license", 1)
+		doc.Info.License = &License{}
 		for _, t := range col {
-			if m, ok := t.Meta["value"]; ok {
-				name := strings.TrimPrefix(t.Key, "lic.")
-				g.buffer.KeyValue(name, trsSafeValue(m), 2)
+			switch t.Key {
+			case "lic.name":
+				doc.Info.License.Name = t.Meta["value"]
+			case "lic.url":
+				doc.Info.License.URL = t.Meta["value"]
 			}
 		}
 	}
 
 	// Servers
-	if servers := g.GetTokens(main, "server"); len(servers) > 0 {
-		g.buffer.Label("servers", 0)
-		for _, t := range servers {
-			g.BufferTokenMeta(t, "url", "- url", 1)
-			g.BufferTokenMeta(t, "desc", "description", 2)
+	for _, t := range g.GetTokens(main, "server") {
+		doc.Servers = append(doc.Servers, &Server{
+			URL:         t.Meta["url"],
+			Description: t.Meta["desc"],
+		})
+	}
+}
+
+// Operation of the endpoint
+func (g *generator) Operation(eIndex int, e []token.Token) *Operation {
+	op := &Operation{}
+	if t, ok := g.GetToken(e, "summary"); ok {
+		op.Summary, _ = g.TokenMeta(t, "value")
+	}
+	if t, ok := g.GetToken(e, "id"); ok {
+		op.OperationID, _ = g.TokenMeta(t, "value")
+	}
+	if t, ok := g.GetToken(e, "desc"); ok {
+		op.Description, _ = g.TokenMeta(t, "value")
+	}
+
+	// Tags
+	if t, ok := g.GetToken(e, "tag"); ok {
+		if m, ok := g.TokenMeta(t, "value"); ok && len(m) > 0 {
+			op.Tags = g.ParseArray(m, trsEmpty)
 		}
 	}
+
+	// Params
+	if params := g.GetTokens(e, "param"); len(params) > 0 {
+		op.Parameters = g.ParamsSection(params)
+	}
+
+	// Body
+	if body, ok := g.GetToken(e, "body"); ok {
+		if t, ok := g.GetToken(e, "accept"); ok {
+			if m, ok := g.TokenMeta(t, "value"); ok && len(m) > 0 {
+				mts := g.ParseArray(m, g.trs["accept"]["value"])
+				op.RequestBody = g.BodySection(body, mts)
+			}
+		}
+	}
+
+	// Response
+	op.Responses = g.ResponseSection(eIndex, e)
+	return op
 }
 
 // ParamsSection processing
-func (g *generator) ParamsSection(params []token.Token, depth int) {
-	g.buffer.Label("parameters", depth)
+func (g *generator) ParamsSection(params []token.Token) []*Parameter {
+	parameters := make([]*Parameter, 0, len(params))
 	for _, t := range params {
-		g.BufferTokenMeta(t, g.nameMetaKey, "- name", depth)
-		g.BufferTokenMeta(t, "desc", "description", depth+1)
-		g.BufferTokenMeta(t, "in", "in", depth+1)
-		g.BufferTokenMeta(t, g.reqMetaKey, "required", depth+1)
-		g.buffer.Label("schema", depth+1)
+		p := &Parameter{
+			Name:        t.Meta[g.nameMetaKey],
+			In:          t.Meta["in"],
+			Description: t.Meta["desc"],
+			Required:    t.Meta[g.reqMetaKey] == "true",
+			Schema:      &Schema{},
+		}
 		if m, ok := t.Meta[g.typeMetaKey]; ok {
 			// Array type
 			if strings.HasPrefix(m, "array ") {
-				g.buffer.KeyValue("type", "array", depth+2)
-				g.buffer.Label("items", depth+2)
-				g.buffer.KeyValue("type", strings.TrimPrefix(m, "array "), depth+3)
+				p.Schema.Type = "array"
+				p.Schema.Items = &Schema{Type: strings.TrimPrefix(m, "array ")}
 				// Regular type
 			} else {
-				g.buffer.KeyValue("type", trsSafeValue(m), depth+2)
+				p.Schema.Type = m
 			}
 		}
+		parameters = append(parameters, p)
 	}
+	return parameters
 }
 
 // BodySection processing
-func (g *generator) BodySection(body token.Token, mediaTypes []string, depth int) {
-	g.buffer.Label("requestBody", depth)
-	g.buffer.Label("content", depth+1)
+func (g *generator) BodySection(body token.Token, mediaTypes []string) *RequestBody {
+	rb := &RequestBody{
+		Content: make(map[string]*MediaType, len(mediaTypes)),
+	}
 	for _, mt := range mediaTypes {
-		g.buffer.Label(mt, depth+2)
-		g.buffer.Label("schema", depth+3)
-		if m, ok := body.Meta["value"]; ok {
-			// Array type
-			if strings.HasPrefix(m, "[]") {
-				g.buffer.KeyValue("type", "array", depth+4)
-				g.buffer.Label("items", depth+4)
-				g.buffer.KeyValue("$ref", g.ComponentRef(strings.TrimPrefix(m, "[]")), depth+5)
-				// Regular type
-			} else {
-				g.buffer.KeyValue("$ref", g.ComponentRef(m), depth+4)
-			}
+		rb.Content[mt] = &MediaType{
+			Schema: g.RefSchema(body.Meta["value"]),
 		}
 	}
+	return rb
 }
 
 // ResponseSection processing
-func (g *generator) ResponseSection(eIndex int, tokens []token.Token, depth int) {
-	g.buffer.Label("responses", depth)
+func (g *generator) ResponseSection(eIndex int, tokens []token.Token) map[string]*Response {
+	responses := make(map[string]*Response, 0)
 
 	// Produces media types
 	mts := make([]string, 0)
@@ -318,114 +338,70 @@ func (g *generator) ResponseSection(eIndex int, tokens []token.Token, depth int)
 		}
 	}
 
-	g.Response("success", mts, eIndex, tokens, depth)
-	g.Response("failure", mts, eIndex, tokens, depth)
+	g.Response("success", mts, eIndex, tokens, responses)
+	g.Response("failure", mts, eIndex, tokens, responses)
+	return responses
 }
 
-// Response processing
-func (g *generator) Response(respType string, mts []string, eIndex int, tokens []token.Token, depth int) {
+// Response processing, the responses are
+// stored into the collection by the code
+func (g *generator) Response(respType string, mts []string, eIndex int, tokens []token.Token, responses map[string]*Response) {
 	reps := g.GetTokens(tokens, respType)
 	for _, t := range reps {
-		if m, ok := g.TokenMeta(t, "code"); ok {
-			g.buffer.Label(m, depth+1)
-			g.BufferTokenMeta(t, "desc", "description", depth+2)
-			g.buffer.Label("content", depth+2)
-			dataType := t.Meta["type"]
-
-			// Plain text
-			if dataType != "object" {
-				g.buffer.Label("text/plain", depth+3)
-				g.buffer.Label("schema", depth+4)
-				g.buffer.KeyValue("type", dataType, depth+5)
-				// Component
-			} else {
-				for _, mt := range mts {
-					g.buffer.Label(mt, depth+3)
-					g.buffer.Label("schema", depth+4)
-
-					// Wrapper
-					if len(g.wrappers[respType][eIndex].lines) > 0 {
-						for i, l := range g.wrappers[respType][eIndex].lines {
-							// Data pointer
-							if g.wrappers[respType][eIndex].pos == i {
-								// Array type
-								if strings.HasPrefix(t.Meta["ref"], "[]") {
-									g.buffer.KeyValue("type", "array", depth+7)
-									g.buffer.Label("items", depth+7)
-									g.buffer.KeyValue("$ref", g.ComponentRef(strings.TrimPrefix(t.Meta["ref"], "[]")), depth+8)
-									// Regular type
-								} else {
-									g.buffer.KeyValue("$ref", g.ComponentRef(t.Meta["ref"]), depth+7)
-								}
-
-								// Wrapper prop
-							} else {
-								// Skip first line, i.e. name of the wrapper
-								if i > 0 {
-									g.buffer.Write(l, depth+4)
-								}
-							}
-						}
-
-						// Direct reference
-					} else {
-						// Array type
-						if strings.HasPrefix(t.Meta["ref"], "[]") {
-							g.buffer.KeyValue("type", "array", depth+5)
-							g.buffer.Label("items", depth+5)
-							g.buffer.KeyValue("$ref", g.ComponentRef(strings.TrimPrefix(t.Meta["ref"], "[]")), depth+6)
-							// Regular type
-						} else {
-							g.buffer.KeyValue("$ref", g.ComponentRef(t.Meta["ref"]), depth+5)
-						}
-					}
-				}
+		m, ok := g.TokenMeta(t, "code")
+		if ok == false {
+			continue
+		}
+		resp := &Response{
+			Description: t.Meta["desc"],
+			Content:     make(map[string]*MediaType, 0),
+		}
+		responses[m] = resp
+		dataType := t.Meta["type"]
+
+		// Plain text
+		if dataType != "object" {
+			resp.Content["text/plain"] = &MediaType{
+				Schema: &Schema{Type: dataType},
 			}
+			continue
+		}
+
+		// Component, wrapped if the endpoint has the wrapper
+		schema := g.RefSchema(t.Meta["ref"])
+		if w := g.Wrapper(respType, eIndex); w.schema != nil {
+			schema = w.schema.Wrap(w.path, schema)
+		}
+		for _, mt := range mts {
+			resp.Content[mt] = &MediaType{Schema: schema}
 		}
 	}
 }
 
-// ResolveWrappers within the endpoint and stored them into wrappers buffer
-func (g *generator) ResolveWrappers(tokens []token.Token) {
-	// Success wrapper
-	g.wrappers["success"] = append(g.wrappers["success"], dataWrapper{})
-	index := len(g.wrappers["success"]) - 1
-	if col := g.GetTokens(tokens, g.wrapperSuccessTokenKey); len(col) > 0 {
-		// Parse the wrapper object
-		g.wrappers["success"][index].lines = g.ParseObject("", col, 0, false)
-
-		// Resolve the data pointer location
-		// The parsed indicates the pointer location by this entry:
-		// -> X, where X is the depth of the pointer
-		for i, l := range g.wrappers["success"][index].lines {
-			if strings.HasPrefix(l, "->") {
-				g.wrappers["success"][index].pos = i
-				prtDepth, _ := strconv.Atoi(strings.Split(l, " ")[1])
-				g.wrappers["success"][index].depth = prtDepth
-				break
-			}
-		}
+// Wrapper of the endpoint by the response type
+func (g *generator) Wrapper(respType string, eIndex int) dataWrapper {
+	if eIndex < len(g.wrappers[respType]) {
+		return g.wrappers[respType][eIndex]
 	}
+	return dataWrapper{}
+}
 
-	// Error wrapper
-	g.wrappers["failure"] = append(g.wrappers["failure"], dataWrapper{})
-	index = len(g.wrappers["failure"]) - 1
-	if col := g.GetTokens(tokens, g.wrapperErrorTokenKey); len(col) > 0 {
-		// Parse the wrapper object
-		g.wrappers["failure"][index].lines = g.ParseObject("", col, 0, false)
+// ResolveWrappers within the endpoint and stored them into the wrappers
+func (g *generator) ResolveWrappers(tokens []token.Token) {
+	g.wrappers["success"] = append(g.wrappers["success"], g.ParseWrapper(g.GetTokens(tokens, g.wrapperSuccessTokenKey)))
+	g.wrappers["failure"] = append(g.wrappers["failure"], g.ParseWrapper(g.GetTokens(tokens, g.wrapperErrorTokenKey)))
+}
 
-		// Resolve the data pointer location
-		// The parsed indicates the pointer location by this entry:
-		// -> X, where X is the depth of the pointer
-		for i, l := range g.wrappers["failure"][index].lines {
-			if strings.HasPrefix(l, "->") {
-				g.wrappers["failure"][index].pos = i
-				prtDepth, _ := strconv.Atoi(strings.Split(l, " ")[1])
-				g.wrappers["failure"][index].depth = prtDepth
-				break
-			}
-		}
+// ParseWrapper object, and resolve the data pointer location
+func (g *generator) ParseWrapper(col []token.Token) dataWrapper {
+	if len(col) == 0 {
+		return dataWrapper{}
 	}
+	w := dataWrapper{
+		schema: g.ParseObject(col, false),
+	}
+	w.path, _ = w.schema.DataPointer()
+	return w
 }
 
 // ResolveComponents structures and store them into the components cache
@@ -443,77 +419,167 @@ func (g *generator) ResolveComponents(tokens []token.Token) {
 			}
 		}
 
-		for name, compDefs := range comps {
+		// Name all components first, i.e. components
+		// might reference each other, e.g. map values
+		names := make([]string, 0, len(comps))
+		for name := range comps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			g.NameComponent(name)
+		}
+
+		for _, name := range names {
+			compDefs := comps[name]
 			// Already in cache
 			if _, ok := g.compCache[name]; ok {
 				continue
 			}
 
-			// Produce an unique nice name
-			chunks := strings.Split(name, ".")
-			niceNameBase := chunks[len(chunks)-1]
-			niceName := niceNameBase
-			i := 0
-			for true {
-				taken := false
-				for _, n := range g.compUniqueName {
-					if n == niceName {
-						taken = true
-						break
-					}
-				}
-				if taken == false {
-					g.compUniqueName = append(g.compUniqueName, niceName)
-					break
-				}
-				i++
-				niceName = fmt.Sprintf("%s%d", niceNameBase, i)
-			}
-
-			// Set mapping between the reference name and component name
-			g.compMapping[name] = niceName
-
 			// At this point the component definitions, i.e. tokens,
 			// is mixed collection of bref, sref, fref token , i.e. one
 			// object might be used many times in within the endpoint.
 			// From parsing perspective, we can grad just one kind of tokens
 			// and ignore rest. Therefore, reduce the collection on one
-			// type here.
+			// type here. The same kind might be referenced more times
+			// as well, e.g. by a response and by a wrapper, therefore
+			// the props are taken only once.
 			reduced := make([]token.Token, 0)
 			kind := compDefs[0].Type
+			props := make(map[string]bool, 0)
 			for _, t := range compDefs {
-				if kind == t.Type {
+				if kind == t.Type && props[t.Meta[g.nameMetaKey]] == false {
+					props[t.Meta[g.nameMetaKey]] = true
 					reduced = append(reduced, t)
 				}
 			}
 
 			// Parse component object and store it into the cache
-			g.compCache[name] = g.ParseObject(name, reduced, 0, false)
+			g.compCache[name] = g.ParseObject(reduced, false)
 		}
 	}
 }
 
-// ParseObject from the collection of tokens describing the object, recursively
-func (g *generator) ParseObject(name string, data []token.Token, depth int, isArray bool) []string {
-	// Normalize the name to a local format, i.e. removed the depth information
-	// e.g. website.data.name -> name
-	if chunks := strings.Split(name, "."); len(chunks) > 1 {
-		name = chunks[len(chunks)-1]
+// NameComponents of all endpoints, sorted by the package path,
+// i.e. the component of the first package takes the plain
+// name, the others are numbered
+func (g *generator) NameComponents(endpoints [][]token.Token) {
+	names := make([]string, 0)
+	for _, e := range endpoints {
+		for _, t := range g.GetTokens(e, g.compTokenKeys...) {
+			if name, ok := t.Meta["pkg.type"]; ok && misc.StringInSlice(name, names) == false {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.NameComponent(name)
 	}
+}
 
-	// Local buffer
-	b := buffer{
-		indentChar: "  ",
+// NameComponent by an unique nice name, i.e. the type name
+// without the package, numbered if already taken. The already
+// named component is skipped.
+func (g *generator) NameComponent(name string) {
+	if _, ok := g.compMapping[name]; ok {
+		return
+	}
+
+	// Produce an unique nice name
+	chunks := strings.Split(name, ".")
+	niceNameBase := chunks[len(chunks)-1]
+	niceName := niceNameBase
+	i := 0
+	for true {
+		taken := false
+		for _, n := range g.compUniqueName {
+			if n == niceName {
+				taken = true
+				break
+			}
+		}
+		if taken == false {
+			g.compUniqueName = append(g.compUniqueName, niceName)
+			break
+		}
+		i++
+		niceName = fmt.Sprintf("%s%d", niceNameBase, i)
 	}
 
+	// Set mapping between the reference name and component name
+	g.compMapping[name] = niceName
+}
+
+// SortedURLs of the endpoints, found by
+// the token key, i.e. router or webhook
+func (g *generator) SortedURLs(endpoints [][]token.Token, key string) []string {
+	urls := make([]string, 0)
+	for _, e := range endpoints {
+		if t, ok := g.GetToken(e, key); ok {
+			if m, ok := g.TokenMeta(t, "url"); ok && misc.StringInSlice(m, urls) == false {
+				urls = append(urls, m)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// Operation of the path, i.e. the method of the endpoint
+type operation struct {
+	index  int
+	method string
+}
+
+// Operations of the endpoints found by the url and the token key,
+// sorted by the canonical order of the methods, and by the endpoint order
+func (g *generator) Operations(url, key string, endpoints [][]token.Token) []operation {
+	ops := make([]operation, 0)
+	for index, e := range endpoints {
+		t, ok := g.GetToken(e, key)
+		if ok == false || t.Meta["url"] != url {
+			continue
+		}
+		if m, ok := g.TokenMeta(t, "method"); ok {
+			for _, method := range g.ParseArray(m, trsEmpty) {
+				ops = append(ops, operation{index: index, method: method})
+			}
+		}
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		oi, oj := methodOrder(ops[i].method), methodOrder(ops[j].method)
+		if oi != oj {
+			return oi < oj
+		}
+		return oi == len(methodsOrder) && ops[i].method < ops[j].method
+	})
+	return ops
+}
+
+// Canonical order of the methods,
+// i.e. as listed by the specification
+var methodsOrder = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// methodOrder of the method, the unknown
+// methods are ordered after the known ones
+func methodOrder(method string) int {
+	for i, m := range methodsOrder {
+		if m == method {
+			return i
+		}
+	}
+	return len(methodsOrder)
+}
+
+// ParseObject from the collection of tokens describing the object, recursively
+func (g *generator) ParseObject(data []token.Token, isArray bool) *Schema {
 	// Base props
-	b.Label(name, depth)
+	object := &Schema{Type: "object"}
+	schema := object
 	if isArray {
-		b.Line("type: array", depth+1)
-		b.Label("items", depth+1)
-		depth++
-	} else {
-		b.Line("type: object", depth+1)
+		schema = &Schema{Type: "array", Items: object}
 	}
 
 	// Distinct local and inner props
@@ -529,89 +595,164 @@ func (g *generator) ParseObject(name string, data []token.Token, depth int, isAr
 	}
 
 	// Required fields
-	if req := g.GetRequiredTokens(local); len(req) > 0 {
-		b.Label("required", depth+1)
-		for _, t := range req {
-			if propName, ok := g.TokenMeta(t, g.nameMetaKey); ok {
-				b.Line(fmt.Sprintf("- %s", propName), depth+1)
-			}
+	for _, t := range g.GetRequiredTokens(local) {
+		if propName, ok := g.TokenMeta(t, g.nameMetaKey); ok {
+			object.Required = append(object.Required, propName)
 		}
 	}
 
 	// Local props
-	if len(local) > 0 {
-		b.Label("properties", depth+1)
-		for _, t := range local {
-			metaType := t.Meta[g.typeMetaKey]
-			metaArr := false
-			if strings.HasPrefix(metaType, "array ") {
-				metaArr = true
-				metaType = strings.TrimPrefix(metaType, "array ")
-			}
+	for _, t := range local {
+		metaType := t.Meta[g.typeMetaKey]
+		metaArr := false
+		if strings.HasPrefix(metaType, "array ") {
+			metaArr = true
+			metaType = strings.TrimPrefix(metaType, "array ")
+		}
 
-			// Reference, inner object
-			if metaType == "object" {
-				if ptr, ok := t.Meta[g.prtMetaKey]; ok && ptr == "true" {
-					b.Label(t.Meta[g.nameMetaKey], depth+2)
-					b.Line(fmt.Sprintf("-> %d", depth), 0)
-				} else {
-					// Flatten the inner ref props, so they will be
-					// threadted in deeper parsing as a local props
-					prefix := fmt.Sprintf("%s.", t.Meta[g.nameMetaKey])
-					reduced := make([]token.Token, 0)
-					for _, rt := range ref {
-						// Take only the props with the same prefix, i.e. in the same object
-						if strings.HasPrefix(rt.Meta[g.nameMetaKey], prefix) {
-							rt.Meta[g.nameMetaKey] = strings.TrimPrefix(rt.Meta[g.nameMetaKey], prefix)
-							reduced = append(reduced, rt)
-						}
-					}
-					// Parse inner object props
-					b.lines = append(b.lines, g.ParseObject(t.Meta[g.nameMetaKey], reduced, depth+2, metaArr)...)
-				}
-				// Plain props
-			} else {
-				b.Label(t.Meta[g.nameMetaKey], depth+2)
-				if m, ok := t.Meta["desc"]; ok {
-					b.KeyValue("description", trsSafeValue(m), depth+3)
-				}
+		_, isComp := g.compMapping[metaType]
+		prop := Property{Name: t.Meta[g.nameMetaKey]}
 
-				// Array type
-				if metaArr {
-					b.KeyValue("type", "array", depth+3)
-					b.Label("items", depth+3)
-					b.KeyValue("type", metaType, depth+4)
-					// Regular type
-				} else {
-					b.KeyValue("type", metaType, depth+3)
+		// Data pointer of the wrapper
+		if ptr, ok := t.Meta[g.prtMetaKey]; ok && ptr == "true" && (metaType == "object" || isComp) {
+			prop.Schema = &Schema{ptr: true}
+
+			// Inline object
+		} else if metaType == "object" {
+			// Flatten the inner ref props, so they will be
+			// threadted in deeper parsing as a local props
+			prefix := fmt.Sprintf("%s.", t.Meta[g.nameMetaKey])
+			reduced := make([]token.Token, 0)
+			for _, rt := range ref {
+				// Take only the props with the same prefix, i.e. in the same object
+				if strings.HasPrefix(rt.Meta[g.nameMetaKey], prefix) {
+					rt.Meta[g.nameMetaKey] = strings.TrimPrefix(rt.Meta[g.nameMetaKey], prefix)
+					reduced = append(reduced, rt)
 				}
 			}
+			// Parse inner object props
+			prop.Schema = g.ParseObject(reduced, metaArr)
+			prop.Schema.Description = t.Meta["desc"]
+
+			// Plain props
+		} else {
+			prop.Schema = g.PropertySchema(t, metaType, metaArr, isComp)
 		}
+		object.Properties = append(object.Properties, prop)
 	}
 
-	return b.lines
+	return schema
 }
 
-// SiblingEndpoints found by the same url
-func (g *generator) SiblingEndpoints(url string, endpoints [][]token.Token) [][]token.Token {
-	found := make([][]token.Token, 0)
-	for _, e := range endpoints {
-		if t, ok := g.GetToken(e, "router"); ok {
-			if m, ok := g.TokenMeta(t, "url"); ok {
-				if m == url {
-					found = append(found, e)
-				}
-			}
+// PropertySchema of the plain object prop
+func (g *generator) PropertySchema(t token.Token, metaType string, metaArr, isComp bool) *Schema {
+	prop := &Schema{
+		Description: t.Meta["desc"],
+	}
+
+	// Array type
+	s := prop
+	if metaArr {
+		s.Type = "array"
+		g.SetConstraints(s, t, g.arrConstraintKeys)
+		s.Items = &Schema{}
+		s = s.Items
+	}
+
+	// Map type, the values are described
+	// by the additional properties
+	if metaType == "map" {
+		s.Type = "object"
+		g.SetConstraints(s, t, g.mapConstraintKeys)
+		s.AdditionalProperties = &Schema{}
+		s = s.AdditionalProperties
+		metaType = t.Meta[g.valueMetaKey]
+		if strings.HasPrefix(metaType, "array ") {
+			metaType = strings.TrimPrefix(metaType, "array ")
+			s.Type = "array"
+			s.Items = &Schema{}
+			s = s.Items
 		}
+		_, isComp = g.compMapping[metaType]
 	}
-	return found
+
+	// Component reference, the description, and
+	// annotations, siblings are allowed only within allOf
+	if isComp {
+		if s == prop && (prop.Description != "" || g.HasAnnotations(t)) {
+			prop.AllOf = []*Schema{{Ref: g.ComponentRef(metaType)}}
+		} else {
+			s.Ref = g.ComponentRef(metaType)
+		}
+		g.SetConstraints(prop, t, g.annotationKeys)
+		return prop
+	}
+
+	s.Type = metaType
+	s.Format = t.Meta[g.formatMetaKey]
+	if m, ok := t.Meta[g.enumMetaKey]; ok {
+		s.Enum = metaValue(m)
+	}
+	g.SetConstraints(s, t, g.constraintKeys)
+	g.SetConstraints(prop, t, g.annotationKeys)
+	return prop
+}
+
+// HasAnnotations checks if the token
+// has any of the property annotations
+func (g *generator) HasAnnotations(t token.Token) bool {
+	for _, k := range g.annotationKeys {
+		if _, ok := t.Meta[k]; ok {
+			return true
+		}
+	}
+	return false
 }
 
-// BufferTokenMeta writes a key/value pair into the buffer
-// where value is the value of the meta prop
-func (g *generator) BufferTokenMeta(t token.Token, meta, key string, indent int) {
-	if m, ok := g.TokenMeta(t, meta); ok {
-		g.buffer.KeyValue(key, trsSafeValue(m), indent)
+// SetConstraints sets the validation constraints, or the
+// annotations, of the token found by the given meta keys
+func (g *generator) SetConstraints(s *Schema, t token.Token, keys []string) {
+	for _, k := range keys {
+		m, ok := t.Meta[k]
+		if ok == false {
+			continue
+		}
+		switch k {
+		case "minimum":
+			s.Minimum = metaValue(m)
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum = metaValue(m)
+		case "maximum":
+			s.Maximum = metaValue(m)
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum = metaValue(m)
+		case "minLength":
+			s.MinLength = metaValue(m)
+		case "maxLength":
+			s.MaxLength = metaValue(m)
+		case "pattern":
+			s.Pattern = m
+		case "minItems":
+			s.MinItems = metaValue(m)
+		case "maxItems":
+			s.MaxItems = metaValue(m)
+		case "minProperties":
+			s.MinProperties = metaValue(m)
+		case "maxProperties":
+			s.MaxProperties = metaValue(m)
+		case "default":
+			s.Default = metaValue(m)
+		case "example":
+			s.Example = metaValue(m)
+		case "nullable":
+			s.Nullable = metaValue(m)
+		case "readOnly":
+			s.ReadOnly = metaValue(m)
+		case "writeOnly":
+			s.WriteOnly = metaValue(m)
+		case "deprecated":
+			s.Deprecated = metaValue(m)
+		}
 	}
 }
 
@@ -684,7 +825,7 @@ func (g *generator) ParseArray(content string, trs transformation) []string {
 // ComponentRef resolved from the name mapping
 func (g *generator) ComponentRef(name string) string {
 	if m, ok := g.compMapping[name]; ok {
-		return fmt.Sprintf("\"#/components/schemas/%s\"", m)
+		return fmt.Sprintf("#/components/schemas/%s", m)
 	}
 	if g.verbose {
 		log.Warnf("generator: missing component reference \"%s\"", name)
@@ -692,18 +833,55 @@ func (g *generator) ComponentRef(name string) string {
 	return ""
 }
 
-// NewGenerator instance
-func NewGenerator(verbose bool) output.Generator {
+// RefSchema of the component, or of the array
+// of the components if prefixed by []
+func (g *generator) RefSchema(name string) *Schema {
+	if strings.HasPrefix(name, "[]") {
+		return &Schema{
+			Type:  "array",
+			Items: &Schema{Ref: g.ComponentRef(strings.TrimPrefix(name, "[]"))},
+		}
+	}
+	return &Schema{Ref: g.ComponentRef(name)}
+}
+
+// ParseVersion of the OpenAPI specification,
+// i.e. 3.0 or 3.1
+func ParseVersion(version string) (string, error) {
+	switch version {
+	case "3.0", Version30:
+		return Version30, nil
+	case "3.1", Version31:
+		return Version31, nil
+	}
+	return "", fmt.Errorf("unsupported OpenAPI version \"%s\"", version)
+}
+
+// NewDocument model of the OpenAPI specification built from the
+// given tokens for the main section and for the given endpoints,
+// i.e. the model is shared by the other generators
+func NewDocument(verbose bool, version string, main []token.Token, endpoints [][]token.Token) *Document {
+	return NewGenerator(verbose, version).(*generator).Document(main, endpoints)
+}
+
+// NewGenerator instance.
+// Version of the OpenAPI specification, 3.0 is used if not set.
+// Formats of the output, YAML is used if not set.
+func NewGenerator(verbose bool, version string, formats ...string) output.Generator {
+	if version == "" {
+		version = Version30
+	}
+	if len(formats) == 0 {
+		formats = []string{output.YAML}
+	}
 	trsTypeClean := trsChain([]transformation{trsArray, trsSpecialChars, trsType})
 	return &generator{
-		verbose: verbose,
-		version: "3.0.2",
-		buffer: buffer{
-			indentChar: "  ",
-		},
+		verbose:        verbose,
+		version:        version,
+		formats:        formats,
 		compUniqueName: make([]string, 0),
 		compMapping:    make(map[string]string, 0),
-		compCache:      make(map[string][]string, 0),
+		compCache:      make(map[string]*Schema, 0),
 		compTokenKeys: []string{
 			"bref", "fref", "sref",
 		},
@@ -717,35 +895,52 @@ func NewGenerator(verbose bool) output.Generator {
 		nameMetaKey:            "key",
 		prtMetaKey:             "ptr",
 		typeMetaKey:            "type",
+		enumMetaKey:            "enum",
+		formatMetaKey:          "format",
+		valueMetaKey:           "value",
+		constraintKeys: []string{
+			"minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum",
+			"minLength", "maxLength", "pattern",
+		},
+		arrConstraintKeys: []string{"minItems", "maxItems"},
+		mapConstraintKeys: []string{"minProperties", "maxProperties"},
+		annotationKeys: []string{
+			"default", "example", "nullable", "readOnly", "writeOnly", "deprecated",
+		},
 		trs: map[string]map[string]transformation{
-			"ver": {
-				"value": trsQuote,
-			},
 			"param": {
 				"type": trsTypeClean,
 			},
 			"success": {
 				"type": trsTypeClean,
-				"code": trsQuote,
 			},
 			"failure": {
 				"type": trsTypeClean,
-				"code": trsQuote,
 			},
 			"sref": {
-				"type": trsTypeClean,
+				"type":  trsTypeClean,
+				"value": trsTypeClean,
+				"desc":  trsUnquote,
 			},
 			"fref": {
-				"type": trsTypeClean,
+				"type":  trsTypeClean,
+				"value": trsTypeClean,
+				"desc":  trsUnquote,
 			},
 			"bref": {
-				"type": trsTypeClean,
+				"type":  trsTypeClean,
+				"value": trsTypeClean,
+				"desc":  trsUnquote,
 			},
 			"swrapref": {
-				"type": trsTypeClean,
+				"type":  trsTypeClean,
+				"value": trsTypeClean,
+				"desc":  trsUnquote,
 			},
 			"fwrapref": {
-				"type": trsTypeClean,
+				"type":  trsTypeClean,
+				"value": trsTypeClean,
+				"desc":  trsUnquote,
 			},
 			"accept": {
 				"value": trsMediaType,
@@ -756,6 +951,9 @@ func NewGenerator(verbose bool) output.Generator {
 			"router": {
 				"method": trsSpecialChars,
 			},
+			"webhook": {
+				"method": trsSpecialChars,
+			},
 		},
 	}
 }
@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -9,13 +10,24 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spaceavocado/apidoc/output"
 	"github.com/spaceavocado/apidoc/token"
 )
 
+// yamlOf the model, i.e. the expected
+// outcome is described by the YAML lines
+func yamlOf(t *testing.T, v interface{}) string {
+	b, err := output.MarshalYAML(v)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	return string(b)
+}
+
 func TestGenerate(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
-	file := "tmp"
+	file := "tmp.yaml"
 	defer func() {
 		os.Remove(file)
 	}()
@@ -30,29 +42,29 @@ func TestGenerate(t *testing.T) {
 			t.Errorf("Unexpected error %v", err)
 		}
 		res = string(b)
-		g.buffer.Clear()
 	}
 
 	// Invalid output folder
 	err = g.Generate(
 		[]token.Token{{}},
 		[][]token.Token{{{}}},
-		"/../../../../../../")
+		"generator.go/tmp/tmp.yaml")
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 
 	// Invalid file
+	os.MkdirAll("tmp-folder.yaml", os.ModePerm)
+	defer os.Remove("tmp-folder.yaml")
 	err = g.Generate(
 		[]token.Token{{}},
 		[][]token.Token{{{}}},
-		"/missing-file.go/")
+		"tmp-folder.yaml")
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 
 	// Transformations
-	g.buffer.Clear()
 	g.Generate(
 		[]token.Token{
 			{
@@ -71,24 +83,29 @@ func TestGenerate(t *testing.T) {
 		file)
 
 	expected = ""
+	expected += "openapi: 3.0.2\n"
 	expected += "info:\n"
 	expected += "  version: \"1.0\"\n"
-	expected += "paths:\n"
-	expected += "openapi: \"3.0.2\""
+	expected += "paths: {}\n"
 
 	read()
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
-	// Components
-	g.compCache = map[string][]string{
+	// Components, sorted by the name
+	g.compMapping = map[string]string{
+		"github.com/pkg.Zebra": "Zebra",
+		"github.com/a.Peter":   "Peter1",
+	}
+	g.compCache = map[string]*Schema{
+		"github.com/pkg.Zebra": {Type: "object"},
+		"github.com/a.Peter":   {Type: "object"},
 		"github.com/pkg.Peter": {
-			"Peter:\n",
-			"  type: object\n",
-			"  properties:\n",
-			"    firstname:\n",
-			"      type: string\n",
+			Type: "object",
+			Properties: Properties{
+				{Name: "firstname", Schema: &Schema{Type: "string"}},
+			},
 		},
 	}
 	g.Generate(
@@ -106,8 +123,10 @@ func TestGenerate(t *testing.T) {
 		file)
 
 	expected = ""
+	expected += "openapi: 3.0.2\n"
 	expected += "info:\n"
 	expected += "  version: \"1.0\"\n"
+	expected += "paths: {}\n"
 	expected += "components:\n"
 	expected += "  schemas:\n"
 	expected += "    Peter:\n"
@@ -115,8 +134,10 @@ func TestGenerate(t *testing.T) {
 	expected += "      properties:\n"
 	expected += "        firstname:\n"
 	expected += "          type: string\n"
-	expected += "paths:\n"
-	expected += "openapi: \"3.0.2\""
+	expected += "    Peter1:\n"
+	expected += "      type: object\n"
+	expected += "    Zebra:\n"
+	expected += "      type: object\n"
 
 	read()
 	if res != expected {
@@ -124,7 +145,7 @@ func TestGenerate(t *testing.T) {
 	}
 
 	// Router
-	g.compCache = make(map[string][]string, 0)
+	g = NewGenerator(false, Version30).(*generator)
 	g.Generate(
 		[]token.Token{
 			{},
@@ -146,7 +167,7 @@ func TestGenerate(t *testing.T) {
 				{
 					Key: "desc",
 					Meta: map[string]string{
-						"value": "lorem",
+						"value": "lorem: \"ipsum\"\ndolor",
 					},
 				},
 				{
@@ -181,7 +202,7 @@ func TestGenerate(t *testing.T) {
 						"key":      "firstname",
 						"type":     "{string}",
 						"req":      "false",
-						"desc":     "Name",
+						"desc":     "\"Name\"",
 					},
 				},
 				{
@@ -212,34 +233,29 @@ func TestGenerate(t *testing.T) {
 		file)
 
 	expected = ""
-	expected += "info:\n"
-	expected += "components:\n"
-	expected += "  schemas:\n"
-	expected += "    Peter:\n"
-	expected += "      type: object\n"
-	expected += "      properties:\n"
-	expected += "        firstname:\n"
-	expected += "          description: Name\n"
-	expected += "          type: string\n"
+	expected += "openapi: 3.0.2\n"
+	expected += "info: {}\n"
 	expected += "paths:\n"
 	expected += "  /sample:\n"
 	expected += "    get:\n"
 	expected += "      summary: sample\n"
-	expected += "      operationId: \"action-id\"\n"
-	expected += "      description: lorem\n"
+	expected += "      operationId: action-id\n"
+	expected += "      description: |-\n"
+	expected += "        lorem: \"ipsum\"\n"
+	expected += "        dolor\n"
 	expected += "      tags:\n"
-	expected += "      - Dolor\n"
+	expected += "        - Dolor\n"
 	expected += "      parameters:\n"
-	expected += "      - name: token\n"
-	expected += "        description: Token\n"
-	expected += "        required: true\n"
-	expected += "        schema:\n"
-	expected += "          type: string\n"
+	expected += "        - name: token\n"
+	expected += "          description: Token\n"
+	expected += "          required: true\n"
+	expected += "          schema:\n"
+	expected += "            type: string\n"
 	expected += "      requestBody:\n"
 	expected += "        content:\n"
 	expected += "          application/json:\n"
 	expected += "            schema:\n"
-	expected += "              $ref: \"#/components/schemas/Peter\"\n"
+	expected += "              $ref: '#/components/schemas/Peter'\n"
 	expected += "      responses:\n"
 	expected += "        \"200\":\n"
 	expected += "          description: OK\n"
@@ -247,15 +263,23 @@ func TestGenerate(t *testing.T) {
 	expected += "            text/plain:\n"
 	expected += "              schema:\n"
 	expected += "                type: string\n"
-	expected += "openapi: \"3.0.2\""
+	expected += "components:\n"
+	expected += "  schemas:\n"
+	expected += "    Peter:\n"
+	expected += "      type: object\n"
+	expected += "      properties:\n"
+	expected += "        firstname:\n"
+	expected += "          description: Name\n"
+	expected += "          type: string\n"
 
 	read()
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
-	// Groupped endpoints
-	g.compCache = make(map[string][]string, 0)
+	// Groupped endpoints, the methods
+	// in the canonical order
+	g = NewGenerator(false, Version30).(*generator)
 	g.Generate(
 		[]token.Token{
 			{
@@ -269,7 +293,7 @@ func TestGenerate(t *testing.T) {
 					Key: "router",
 					Meta: map[string]string{
 						"url":    "/test",
-						"method": "get",
+						"method": "post",
 					},
 				},
 			},
@@ -278,7 +302,7 @@ func TestGenerate(t *testing.T) {
 					Key: "router",
 					Meta: map[string]string{
 						"url":    "/test",
-						"method": "post",
+						"method": "get",
 					},
 				},
 			},
@@ -286,25 +310,79 @@ func TestGenerate(t *testing.T) {
 		file)
 
 	expected = ""
+	expected += "openapi: 3.0.2\n"
 	expected += "info:\n"
 	expected += "  version: \"1.0\"\n"
 	expected += "paths:\n"
 	expected += "  /test:\n"
 	expected += "    get:\n"
-	expected += "      responses:\n"
+	expected += "      responses: {}\n"
 	expected += "    post:\n"
-	expected += "      responses:\n"
-	expected += "openapi: \"3.0.2\""
+	expected += "      responses: {}\n"
 
 	read()
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
+
+	// Both formats, the same document
+	g = NewGenerator(false, Version30, "yaml", "json").(*generator)
+	defer os.Remove("tmp.json")
+	err = g.Generate(
+		[]token.Token{
+			{
+				Key:  "ver",
+				Meta: map[string]string{"value": "1.0"},
+			},
+		},
+		[][]token.Token{
+			{
+				{
+					Key: "router",
+					Meta: map[string]string{
+						"url":    "/test",
+						"method": "get",
+					},
+				},
+			},
+		},
+		file)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	expected = ""
+	expected += "{\n"
+	expected += "  \"openapi\": \"3.0.2\",\n"
+	expected += "  \"info\": {\n"
+	expected += "    \"version\": \"1.0\"\n"
+	expected += "  },\n"
+	expected += "  \"paths\": {\n"
+	expected += "    \"/test\": {\n"
+	expected += "      \"get\": {\n"
+	expected += "        \"responses\": {}\n"
+	expected += "      }\n"
+	expected += "    }\n"
+	expected += "  }\n"
+	expected += "}\n"
+
+	b, err := ioutil.ReadFile("tmp.json")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if string(b) != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, string(b))
+	}
+	read()
+	if strings.HasPrefix(res, "openapi: 3.0.2\n") == false {
+		t.Errorf("Expected the YAML document, got \"%s\"", res)
+	}
 }
 
 func TestMainSection(t *testing.T) {
-	g := NewGenerator(false).(*generator)
-	g.MainSection([]token.Token{
+	g := NewGenerator(false, Version30).(*generator)
+	doc := &Document{}
+	g.MainSection(doc, []token.Token{
 		{
 			Key:  "title",
 			Meta: map[string]string{"value": "title"},
@@ -317,6 +395,10 @@ func TestMainSection(t *testing.T) {
 			Key:  "desc",
 			Meta: map[string]string{"value": "lorem"},
 		},
+		{
+			Key:  "terms",
+			Meta: map[string]string{"value": "terms"},
+		},
 		{
 			Key:  "contact.name",
 			Meta: map[string]string{"value": "name"},
@@ -336,26 +418,36 @@ func TestMainSection(t *testing.T) {
 	})
 
 	expected := ""
-	expected += "info:\n  title: title\n  version: 1.0\n  description: lorem\n"
-	expected += "  contact:\n    name: name\n"
-	expected += "  license:\n    url: url\n"
+	expected += "openapi: \"\"\n"
+	expected += "info:\n"
+	expected += "  title: title\n"
+	expected += "  description: lorem\n"
+	expected += "  termsOfService: terms\n"
+	expected += "  contact:\n"
+	expected += "    name: name\n"
+	expected += "  license:\n"
+	expected += "    url: url\n"
+	expected += "  version: \"1.0\"\n"
 	expected += "servers:\n"
-	expected += "  - url: url1\n    description: lorem 1\n"
-	expected += "  - url: url2\n    description: lorem 2\n"
+	expected += "  - url: url1\n"
+	expected += "    description: lorem 1\n"
+	expected += "  - url: url2\n"
+	expected += "    description: lorem 2\n"
+	expected += "paths: null\n"
 
-	res := g.buffer.Flush()
+	res := yamlOf(t, doc)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 }
 
 func TestParamsSection(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
 	var res string
 
 	// Non array
-	g.ParamsSection([]token.Token{
+	params := g.ParamsSection([]token.Token{
 		{
 			Key: "param",
 			Meta: map[string]string{
@@ -366,25 +458,23 @@ func TestParamsSection(t *testing.T) {
 				(g.typeMetaKey): "int",
 			},
 		},
-	}, 0)
+	})
 
 	expected := ""
-	expected += "parameters:\n"
 	expected += "- name: param1\n"
-	expected += "  description: desc1\n"
 	expected += "  in: path\n"
+	expected += "  description: desc1\n"
 	expected += "  required: true\n"
 	expected += "  schema:\n"
 	expected += "    type: int\n"
 
-	res = g.buffer.Flush()
+	res = yamlOf(t, params)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Array
-	g.buffer.Clear()
-	g.ParamsSection([]token.Token{
+	params = g.ParamsSection([]token.Token{
 		{
 			Key: "param",
 			Meta: map[string]string{
@@ -395,27 +485,26 @@ func TestParamsSection(t *testing.T) {
 				(g.typeMetaKey): "array int",
 			},
 		},
-	}, 0)
+	})
 
 	expected = ""
-	expected += "parameters:\n"
 	expected += "- name: param1\n"
-	expected += "  description: desc1\n"
 	expected += "  in: path\n"
+	expected += "  description: desc1\n"
 	expected += "  required: true\n"
 	expected += "  schema:\n"
 	expected += "    type: array\n"
 	expected += "    items:\n"
 	expected += "      type: int\n"
 
-	res = g.buffer.Flush()
+	res = yamlOf(t, params)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 }
 
 func TestBodySection(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 	g.compMapping = map[string]string{
 		"github.com/pkg.Peter": "Peter",
 	}
@@ -427,615 +516,592 @@ func TestBodySection(t *testing.T) {
 	}
 
 	// Non array
-	g.BodySection(token.Token{
+	body := g.BodySection(token.Token{
 		Key: "body",
 		Meta: map[string]string{
 			"value": "github.com/pkg.Peter",
 		},
-	}, mts, 0)
+	}, mts)
 
 	expected := ""
-	expected += "requestBody:\n"
-	expected += "  content:\n"
-	expected += "    application/json:\n"
-	expected += "      schema:\n"
-	expected += "        $ref: \"#/components/schemas/Peter\"\n"
-	expected += "    multipart/form-data:\n"
-	expected += "      schema:\n"
-	expected += "        $ref: \"#/components/schemas/Peter\"\n"
-
-	res = g.buffer.Flush()
+	expected += "content:\n"
+	expected += "  application/json:\n"
+	expected += "    schema:\n"
+	expected += "      $ref: '#/components/schemas/Peter'\n"
+	expected += "  multipart/form-data:\n"
+	expected += "    schema:\n"
+	expected += "      $ref: '#/components/schemas/Peter'\n"
+
+	res = yamlOf(t, body)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Array
-	g.buffer.Clear()
-	g.BodySection(token.Token{
+	body = g.BodySection(token.Token{
 		Key: "param",
 		Meta: map[string]string{
 			"value": "[]github.com/pkg.Peter",
 		},
-	}, mts, 0)
+	}, mts)
 
 	expected = ""
-	expected += "requestBody:\n"
-	expected += "  content:\n"
-	expected += "    application/json:\n"
-	expected += "      schema:\n"
-	expected += "        type: array\n"
-	expected += "        items:\n"
-	expected += "          $ref: \"#/components/schemas/Peter\"\n"
-	expected += "    multipart/form-data:\n"
-	expected += "      schema:\n"
-	expected += "        type: array\n"
-	expected += "        items:\n"
-	expected += "          $ref: \"#/components/schemas/Peter\"\n"
-
-	res = g.buffer.Flush()
+	expected += "content:\n"
+	expected += "  application/json:\n"
+	expected += "    schema:\n"
+	expected += "      type: array\n"
+	expected += "      items:\n"
+	expected += "        $ref: '#/components/schemas/Peter'\n"
+	expected += "  multipart/form-data:\n"
+	expected += "    schema:\n"
+	expected += "      type: array\n"
+	expected += "      items:\n"
+	expected += "        $ref: '#/components/schemas/Peter'\n"
+
+	res = yamlOf(t, body)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 }
 
 func TestResponseSection(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
-	// Just run the method, to see if any error occurs
-	g.ResponseSection(0, []token.Token{
+	// Responses of both kinds, by the code, the
+	// endpoint without the resolved wrappers
+	responses := g.ResponseSection(0, []token.Token{
 		{
 			Key: "produce",
 			Meta: map[string]string{
 				"value": "application/json",
 			},
 		},
-	}, 0)
+		{
+			Key: "failure",
+			Meta: map[string]string{
+				"code": "500",
+				"type": "string",
+				"desc": "Error",
+			},
+		},
+		{
+			Key: "success",
+			Meta: map[string]string{
+				"code": "200",
+				"type": "object",
+				"ref":  "github.com/pkg.Missing",
+				"desc": "OK",
+			},
+		},
+	})
+
+	expected := ""
+	expected += "\"200\":\n"
+	expected += "  description: OK\n"
+	expected += "  content:\n"
+	expected += "    application/json:\n"
+	expected += "      schema: {}\n"
+	expected += "\"500\":\n"
+	expected += "  description: Error\n"
+	expected += "  content:\n"
+	expected += "    text/plain:\n"
+	expected += "      schema:\n"
+	expected += "        type: string\n"
+
+	res := yamlOf(t, responses)
+	if res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
 }
 
 func TestResponse(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 	g.compMapping = map[string]string{
 		"github.com/pkg.Peter": "Peter",
 	}
 	g.wrappers = map[string][]dataWrapper{
 		"success": {
-			{
-				lines: make([]string, 0),
-			},
+			{},
 		},
 	}
 
 	var res string
 	var expected string
+	var responses map[string]*Response
 	var mts = []string{
 		"application/json",
 		"multipart/form-data",
 	}
 
 	// Success, plain
+	responses = make(map[string]*Response, 0)
 	g.Response("success", mts, 0, []token.Token{
 		{
 			Key: "success",
 			Meta: map[string]string{
-				"code": "\"200\"",
+				"code": "200",
 				"type": "string",
 				"desc": "lorem",
 			},
 		},
-	}, 0)
+	}, responses)
 	expected = ""
-	expected += "  \"200\":\n"
-	expected += "    description: lorem\n"
-	expected += "    content:\n"
-	expected += "      text/plain:\n"
-	expected += "        schema:\n"
-	expected += "          type: string\n"
+	expected += "\"200\":\n"
+	expected += "  description: lorem\n"
+	expected += "  content:\n"
+	expected += "    text/plain:\n"
+	expected += "      schema:\n"
+	expected += "        type: string\n"
 
-	res = g.buffer.Flush()
+	res = yamlOf(t, responses)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Success, direct reference
-	g.buffer.Clear()
+	responses = make(map[string]*Response, 0)
 	g.Response("success", mts, 0, []token.Token{
 		{
 			Key: "success",
 			Meta: map[string]string{
-				"code": "\"200\"",
+				"code": "200",
 				"type": "object",
 				"ref":  "github.com/pkg.Peter",
 				"desc": "lorem",
 			},
 		},
-	}, 0)
+	}, responses)
 	expected = ""
-	expected += "  \"200\":\n"
-	expected += "    description: lorem\n"
-	expected += "    content:\n"
-	expected += "      application/json:\n"
-	expected += "        schema:\n"
-	expected += "          $ref: \"#/components/schemas/Peter\"\n"
-	expected += "      multipart/form-data:\n"
-	expected += "        schema:\n"
-	expected += "          $ref: \"#/components/schemas/Peter\"\n"
-
-	res = g.buffer.Flush()
+	expected += "\"200\":\n"
+	expected += "  description: lorem\n"
+	expected += "  content:\n"
+	expected += "    application/json:\n"
+	expected += "      schema:\n"
+	expected += "        $ref: '#/components/schemas/Peter'\n"
+	expected += "    multipart/form-data:\n"
+	expected += "      schema:\n"
+	expected += "        $ref: '#/components/schemas/Peter'\n"
+
+	res = yamlOf(t, responses)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Success, direct array
-	g.buffer.Clear()
+	responses = make(map[string]*Response, 0)
 	g.Response("success", mts, 0, []token.Token{
 		{
 			Key: "success",
 			Meta: map[string]string{
-				"code": "\"200\"",
+				"code": "200",
 				"type": "object",
 				"ref":  "[]github.com/pkg.Peter",
 				"desc": "lorem",
 			},
 		},
-	}, 0)
+	}, responses)
 	expected = ""
-	expected += "  \"200\":\n"
-	expected += "    description: lorem\n"
-	expected += "    content:\n"
-	expected += "      application/json:\n"
-	expected += "        schema:\n"
-	expected += "          type: array\n"
-	expected += "          items:\n"
-	expected += "            $ref: \"#/components/schemas/Peter\"\n"
-	expected += "      multipart/form-data:\n"
-	expected += "        schema:\n"
-	expected += "          type: array\n"
-	expected += "          items:\n"
-	expected += "            $ref: \"#/components/schemas/Peter\"\n"
-
-	res = g.buffer.Flush()
+	expected += "\"200\":\n"
+	expected += "  description: lorem\n"
+	expected += "  content:\n"
+	expected += "    application/json:\n"
+	expected += "      schema:\n"
+	expected += "        type: array\n"
+	expected += "        items:\n"
+	expected += "          $ref: '#/components/schemas/Peter'\n"
+	expected += "    multipart/form-data:\n"
+	expected += "      schema:\n"
+	expected += "        type: array\n"
+	expected += "        items:\n"
+	expected += "          $ref: '#/components/schemas/Peter'\n"
+
+	res = yamlOf(t, responses)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Success, Wrapper
-	g.compMapping = map[string]string{
-		"github.com/pkg.Peter": "Peter",
+	wrapper := &Schema{
+		Type: "object",
+		Properties: Properties{
+			{Name: "data", Schema: &Schema{ptr: true}},
+			{Name: "status", Schema: &Schema{Type: "string"}},
+		},
 	}
 	g.wrappers = map[string][]dataWrapper{
 		"success": {
 			{
-				pos: 4,
-				lines: []string{
-					":\n",
-					"  type: object\n",
-					"  properties:\n",
-					"    data:\n",
-					"->4\n",
-					"    status:\n",
-					"      type: string\n",
-				},
+				schema: wrapper,
+				path:   []string{"data"},
 			},
 		},
 	}
-	g.buffer.Clear()
+	responses = make(map[string]*Response, 0)
 	g.Response("success", mts, 0, []token.Token{
 		{
 			Key: "success",
 			Meta: map[string]string{
-				"code": "\"200\"",
+				"code": "200",
 				"type": "object",
 				"ref":  "github.com/pkg.Peter",
 				"desc": "lorem",
 			},
 		},
-	}, 0)
+	}, responses)
 	expected = ""
-	expected += "  \"200\":\n"
-	expected += "    description: lorem\n"
-	expected += "    content:\n"
-	expected += "      application/json:\n"
-	expected += "        schema:\n"
-	expected += "          type: object\n"
-	expected += "          properties:\n"
-	expected += "            data:\n"
-	expected += "              $ref: \"#/components/schemas/Peter\"\n"
-	expected += "            status:\n"
-	expected += "              type: string\n"
-	expected += "      multipart/form-data:\n"
-	expected += "        schema:\n"
-	expected += "          type: object\n"
-	expected += "          properties:\n"
-	expected += "            data:\n"
-	expected += "              $ref: \"#/components/schemas/Peter\"\n"
-	expected += "            status:\n"
-	expected += "              type: string\n"
-
-	res = g.buffer.Flush()
+	expected += "\"200\":\n"
+	expected += "  description: lorem\n"
+	expected += "  content:\n"
+	expected += "    application/json:\n"
+	expected += "      schema:\n"
+	expected += "        type: object\n"
+	expected += "        properties:\n"
+	expected += "          data:\n"
+	expected += "            $ref: '#/components/schemas/Peter'\n"
+	expected += "          status:\n"
+	expected += "            type: string\n"
+	expected += "    multipart/form-data:\n"
+	expected += "      schema:\n"
+	expected += "        type: object\n"
+	expected += "        properties:\n"
+	expected += "          data:\n"
+	expected += "            $ref: '#/components/schemas/Peter'\n"
+	expected += "          status:\n"
+	expected += "            type: string\n"
+
+	res = yamlOf(t, responses)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
 
 	// Success, Wrapper, array
-	g.buffer.Clear()
+	responses = make(map[string]*Response, 0)
 	g.Response("success", mts, 0, []token.Token{
 		{
 			Key: "success",
 			Meta: map[string]string{
-				"code": "\"200\"",
+				"code": "200",
 				"type": "object",
 				"ref":  "[]github.com/pkg.Peter",
 				"desc": "lorem",
 			},
 		},
-	}, 0)
+	}, responses)
 	expected = ""
-	expected += "  \"200\":\n"
-	expected += "    description: lorem\n"
-	expected += "    content:\n"
-	expected += "      application/json:\n"
-	expected += "        schema:\n"
-	expected += "          type: object\n"
-	expected += "          properties:\n"
-	expected += "            data:\n"
-	expected += "              type: array\n"
-	expected += "              items:\n"
-	expected += "                $ref: \"#/components/schemas/Peter\"\n"
-	expected += "            status:\n"
-	expected += "              type: string\n"
-	expected += "      multipart/form-data:\n"
-	expected += "        schema:\n"
-	expected += "          type: object\n"
-	expected += "          properties:\n"
-	expected += "            data:\n"
-	expected += "              type: array\n"
-	expected += "              items:\n"
-	expected += "                $ref: \"#/components/schemas/Peter\"\n"
-	expected += "            status:\n"
-	expected += "              type: string\n"
-
-	res = g.buffer.Flush()
+	expected += "\"200\":\n"
+	expected += "  description: lorem\n"
+	expected += "  content:\n"
+	expected += "    application/json:\n"
+	expected += "      schema:\n"
+	expected += "        type: object\n"
+	expected += "        properties:\n"
+	expected += "          data:\n"
+	expected += "            type: array\n"
+	expected += "            items:\n"
+	expected += "              $ref: '#/components/schemas/Peter'\n"
+	expected += "          status:\n"
+	expected += "            type: string\n"
+	expected += "    multipart/form-data:\n"
+	expected += "      schema:\n"
+	expected += "        type: object\n"
+	expected += "        properties:\n"
+	expected += "          data:\n"
+	expected += "            type: array\n"
+	expected += "            items:\n"
+	expected += "              $ref: '#/components/schemas/Peter'\n"
+	expected += "          status:\n"
+	expected += "            type: string\n"
+
+	res = yamlOf(t, responses)
 	if res != expected {
 		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
 	}
+
+	// The wrapper is not modified
+	if wrapper.Properties[0].Schema.ptr == false {
+		t.Errorf("Expected the data pointer of the wrapper")
+	}
 }
 
-func TestBufferTokenMeta(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+func TestResolveWrappers(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
 
-	// Valid meta
-	g.BufferTokenMeta(
-		token.Token{
-			Key: "a",
-			Meta: map[string]string{
-				"meta_a": "value_a",
-				"meta_b": "value_b",
-				"meta_c": "value_c",
+	var expected string
+
+	wrapper := func(key string) []token.Token {
+		return []token.Token{
+			{
+				Key: key,
+				Meta: map[string]string{
+					"pkg.type":      "github.com/pkg.Peter",
+					(g.nameMetaKey): "firstname",
+					(g.typeMetaKey): "string",
+					(g.reqMetaKey):  "false",
+					(g.prtMetaKey):  "false",
+					"desc":          "lorem",
+				},
+			},
+			{
+				Key: key,
+				Meta: map[string]string{
+					"pkg.type":      "github.com/pkg.Peter",
+					(g.nameMetaKey): "data",
+					(g.typeMetaKey): "object",
+					(g.reqMetaKey):  "false",
+					(g.prtMetaKey):  "true",
+					"desc":          "lorem",
+				},
 			},
-		}, "meta_b", "key", 0)
-	res := g.buffer.Flush()
-	if res != "key: value_b\n" {
-		t.Errorf("Expected \"%s\", got \"%s\"", "key: value_b\n", res)
+			{
+				Key: key,
+				Meta: map[string]string{
+					"pkg.type":      "github.com/pkg.Peter",
+					(g.nameMetaKey): "lastname",
+					(g.typeMetaKey): "string",
+					(g.reqMetaKey):  "false",
+					(g.prtMetaKey):  "false",
+					"desc":          "lorem",
+				},
+			},
+		}
 	}
+	expected = ""
+	expected += "type: object\n"
+	expected += "properties:\n"
+	expected += "  firstname:\n"
+	expected += "    description: lorem\n"
+	expected += "    type: string\n"
+	expected += "  data: {}\n"
+	expected += "  lastname:\n"
+	expected += "    description: lorem\n"
+	expected += "    type: string\n"
 
-	// Invalid meta
-	g.BufferTokenMeta(
-		token.Token{
-			Key: "a",
-			Meta: map[string]string{
-				"meta_a": "value_a",
+	// Success
+	g.wrappers["success"] = make([]dataWrapper, 0)
+	g.wrappers["failure"] = make([]dataWrapper, 0)
+	g.ResolveWrappers(wrapper(g.wrapperSuccessTokenKey))
+	if len(g.wrappers["success"]) != 1 || len(g.wrappers["failure"]) != 1 {
+		t.Errorf("Expected a wrapper of each kind")
+		return
+	}
+	if g.wrappers["failure"][0].schema != nil {
+		t.Errorf("Expected no failure wrapper")
+	}
+	if res := yamlOf(t, g.wrappers["success"][0].schema); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+	if path := strings.Join(g.wrappers["success"][0].path, "."); path != "data" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "data", path)
+	}
+
+	// Failure
+	g.wrappers["success"] = make([]dataWrapper, 0)
+	g.wrappers["failure"] = make([]dataWrapper, 0)
+	g.ResolveWrappers(wrapper(g.wrapperErrorTokenKey))
+	if len(g.wrappers["success"]) != 1 || len(g.wrappers["failure"]) != 1 {
+		t.Errorf("Expected a wrapper of each kind")
+		return
+	}
+	if g.wrappers["success"][0].schema != nil {
+		t.Errorf("Expected no success wrapper")
+	}
+	if res := yamlOf(t, g.wrappers["failure"][0].schema); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+	if path := strings.Join(g.wrappers["failure"][0].path, "."); path != "data" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "data", path)
+	}
+}
+
+func TestResolveComponents(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+
+	props := func(key string) []token.Token {
+		return []token.Token{
+			{
+				Key: "bref",
+				Meta: map[string]string{
+					"pkg.type":      "github.com/pkg.Peter",
+					(g.nameMetaKey): "firstname",
+					(g.typeMetaKey): "string",
+					(g.reqMetaKey):  "false",
+					"desc":          "lorem",
+				},
 			},
-		}, "meta_b", "key", 0)
-	res = g.buffer.Flush()
-	if res != "key: value_b\n" {
-		t.Errorf("Expected \"%s\", got \"%s\"", "key: value_b\n", res)
+			{
+				Key: key,
+				Meta: map[string]string{
+					"pkg.type":      "github.com/pkg.Peter",
+					(g.nameMetaKey): "lastname",
+					(g.typeMetaKey): "string",
+					(g.reqMetaKey):  "false",
+					"desc":          "ipsum",
+				},
+			},
+		}
+	}
+
+	// Already in cache
+	cached := &Schema{}
+	g.compCache = map[string]*Schema{"github.com/pkg.Peter": cached}
+	g.ResolveComponents(props("bref"))
+	if len(g.compCache) != 1 || g.compCache["github.com/pkg.Peter"] != cached {
+		t.Errorf("Invalid cache modification")
+	}
+
+	// Unique name
+	g.compCache = map[string]*Schema{"": {}}
+	g.compMapping = make(map[string]string, 0)
+	g.compUniqueName = []string{
+		"Peter",
+	}
+	g.ResolveComponents(props("bref"))
+	if len(g.compUniqueName) != 2 {
+		t.Errorf("Expected %d, got %d", 2, len(g.compUniqueName))
+		return
+	}
+	if g.compUniqueName[1] != "Peter1" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "Peter1", g.compUniqueName[1])
+	}
+
+	// Cache properly build
+	g.compCache = make(map[string]*Schema, 0)
+	g.ResolveComponents(props("bref"))
+	if len(g.compCache) != 1 {
+		t.Errorf("Invalid cache modification")
+		return
+	}
+	if s := g.compCache["github.com/pkg.Peter"]; s == nil || len(s.Properties) != 2 {
+		t.Errorf("Expected the component with %d properties, got %v", 2, s)
+	}
+
+	// Reducing
+	g.compCache = make(map[string]*Schema, 0)
+	g.ResolveComponents(props("sref"))
+	if len(g.compCache) != 1 {
+		t.Errorf("Invalid cache modification")
+		return
+	}
+	if s := g.compCache["github.com/pkg.Peter"]; s == nil || len(s.Properties) != 2 {
+		t.Errorf("Expected the component with %d properties, got %v", 2, s)
 	}
 }
 
-func TestResolveWrappers(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+func TestParseObject(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
 
+	var res string
 	var expected []string
 
-	// Success
-	g.wrappers["success"] = make([]dataWrapper, 0)
-	g.wrappers["failure"] = make([]dataWrapper, 0)
-	g.ResolveWrappers([]token.Token{
+	// Flat, no array
+	res = yamlOf(t, g.ParseObject([]token.Token{
 		{
-			Key: g.wrapperSuccessTokenKey,
+			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
 				(g.nameMetaKey): "firstname",
 				(g.typeMetaKey): "string",
 				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "false",
 				"desc":          "lorem",
 			},
 		},
 		{
-			Key: g.wrapperSuccessTokenKey,
+			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "data",
-				(g.typeMetaKey): "object",
-				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "true",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: g.wrapperSuccessTokenKey,
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
 				(g.nameMetaKey): "lastname",
 				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "false",
-				"desc":          "lorem",
+				(g.reqMetaKey):  "true",
+				"desc":          "impsum",
 			},
 		},
-	})
-	expected = []string{
-		":\n",
-		"  type: object\n",
-		"  properties:\n",
-		"    firstname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-		"    data:\n",
-		"-> 0\n",
-		"    lastname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-	}
-	if len(g.wrappers["success"][0].lines) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(g.wrappers["success"][0].lines))
-		return
-	}
-	for i, l := range g.wrappers["success"][0].lines {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
-	}
+	}, false))
 
-	// Failure
-	g.wrappers["success"] = make([]dataWrapper, 0)
-	g.wrappers["failure"] = make([]dataWrapper, 0)
-	g.ResolveWrappers([]token.Token{
-		{
-			Key: g.wrapperErrorTokenKey,
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "false",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: g.wrapperErrorTokenKey,
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "data",
-				(g.typeMetaKey): "object",
-				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "true",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: g.wrapperErrorTokenKey,
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				(g.prtMetaKey):  "false",
-				"desc":          "lorem",
-			},
-		},
-	})
 	expected = []string{
-		":\n",
-		"  type: object\n",
-		"  properties:\n",
-		"    firstname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-		"    data:\n",
-		"-> 0\n",
-		"    lastname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-	}
-	if len(g.wrappers["failure"][0].lines) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(g.wrappers["failure"][0].lines))
-		return
+		"type: object\n",
+		"required:\n",
+		"  - lastname\n",
+		"properties:\n",
+		"  firstname:\n",
+		"    description: lorem\n",
+		"    type: string\n",
+		"  lastname:\n",
+		"    description: impsum\n",
+		"    type: string\n",
 	}
-	for i, l := range g.wrappers["failure"][0].lines {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
 	}
-}
-
-func TestResolveComponents(t *testing.T) {
-	g := NewGenerator(false).(*generator)
-
-	var expected map[string][]string
 
-	// Already in cache
-	g.compCache = map[string][]string{"github.com/pkg.Peter": {""}}
-	expected = map[string][]string{"github.com/pkg.Peter": {""}}
-	g.ResolveComponents([]token.Token{
+	// Enum values and formats
+	res = yamlOf(t, g.ParseObject([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "lorem",
+				(g.nameMetaKey):   "born",
+				(g.typeMetaKey):   "string",
+				(g.reqMetaKey):    "false",
+				(g.formatMetaKey): "date-time",
 			},
 		},
 		{
 			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "ipsum",
+				(g.nameMetaKey):   "ids",
+				(g.typeMetaKey):   "array string",
+				(g.reqMetaKey):    "false",
+				(g.formatMetaKey): "uuid",
 			},
 		},
-	})
-	if len(g.compCache) != len(expected) {
-		t.Errorf("Invalid cache modification")
-		return
-	}
-	for k, c := range g.compCache {
-		el, ok := expected[k]
-		if ok == false {
-			t.Errorf("Invalid cache modification")
-			return
-		}
-		for i, l := range el {
-			if l != c[i] {
-				t.Errorf("Expected \"%s\", got \"%s\"", c[i], l)
-			}
-		}
-	}
-
-	// Unique name
-	g.compCache = map[string][]string{"": {""}}
-	g.compUniqueName = []string{
-		"Peter",
-	}
-	g.ResolveComponents([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
+				(g.nameMetaKey): "status",
+				(g.typeMetaKey): "integer",
 				(g.reqMetaKey):  "false",
-				"desc":          "lorem",
+				(g.enumMetaKey): "[0,1,2]",
 			},
 		},
 		{
 			Key: "bref",
 			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
+				(g.nameMetaKey): "roles",
+				(g.typeMetaKey): "array string",
 				(g.reqMetaKey):  "false",
-				"desc":          "ipsum",
+				(g.enumMetaKey): "[\"admin\",\"user\"]",
 			},
 		},
-	})
-	if len(g.compUniqueName) != 2 {
-		t.Errorf("Expected %d, got %d", 2, len(g.compUniqueName))
-		return
-	}
-	if g.compUniqueName[1] != "Peter1" {
-		t.Errorf("Expected \"%s\", got \"%s\"", "Peter1", g.compUniqueName[1])
-	}
+	}, false))
 
-	// Cache properly build
-	g.compCache = make(map[string][]string, 0)
-	expected = map[string][]string{
-		"github.com/pkg.Peter": {
-			"Peter:\n",
-			"  type: object\n",
-		},
-	}
-	g.ResolveComponents([]token.Token{
-		{
-			Key: "bref",
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: "bref",
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "ipsum",
-			},
-		},
-	})
-	if len(g.compCache) != len(expected) {
-		t.Errorf("Invalid cache modification")
-		return
-	}
-	for k, c := range g.compCache {
-		el, ok := expected[k]
-		if ok == false {
-			t.Errorf("Invalid cache modification")
-			return
-		}
-		for i, l := range el {
-			if l != c[i] {
-				t.Errorf("Expected \"%s\", got \"%s\"", l, c[i])
-			}
-		}
-	}
-
-	// Reducing
-	g.compCache = make(map[string][]string, 0)
-	expected = map[string][]string{
-		"github.com/pkg.Peter": {
-			"Peter:\n",
-			"  type: object\n",
-		},
-	}
-	g.ResolveComponents([]token.Token{
-		{
-			Key: "bref",
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: "sref",
-			Meta: map[string]string{
-				"pkg.type":      "github.com/pkg.Peter",
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "ipsum",
-			},
-		},
-	})
-	if len(g.compCache) != len(expected) {
-		t.Errorf("Invalid cache modification")
-		return
+	expected = []string{
+		"type: object\n",
+		"properties:\n",
+		"  born:\n",
+		"    type: string\n",
+		"    format: date-time\n",
+		"  ids:\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: string\n",
+		"      format: uuid\n",
+		"  status:\n",
+		"    type: integer\n",
+		"    enum:\n",
+		"      - 0\n",
+		"      - 1\n",
+		"      - 2\n",
+		"  roles:\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: string\n",
+		"      enum:\n",
+		"        - admin\n",
+		"        - user\n",
 	}
-	if len(g.compCache["github.com/pkg.Peter"]) != 9 {
-		t.Errorf("Expected %d, got %d", 9, len(g.compCache["github.com/pkg.Peter"]))
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
 	}
-}
-
-func TestParseObject(t *testing.T) {
-	g := NewGenerator(false).(*generator)
-
-	var res []string
-	var expected []string
 
-	// Flat, no array
-	res = g.ParseObject("github.com/pkg.Peter", []token.Token{
+	// Flat, array
+	res = yamlOf(t, g.ParseObject([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
@@ -1054,13 +1120,14 @@ func TestParseObject(t *testing.T) {
 				"desc":          "impsum",
 			},
 		},
-	}, 0, false)
+	}, true))
 
 	expected = []string{
-		"Peter:\n",
+		"type: array\n",
+		"items:\n",
 		"  type: object\n",
 		"  required:\n",
-		"  - lastname\n",
+		"    - lastname\n",
 		"  properties:\n",
 		"    firstname:\n",
 		"      description: lorem\n",
@@ -1069,65 +1136,12 @@ func TestParseObject(t *testing.T) {
 		"      description: impsum\n",
 		"      type: string\n",
 	}
-	if len(res) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(res))
-		return
-	}
-	for i, l := range res {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
-	}
-
-	// Flat, array
-	res = g.ParseObject("github.com/pkg.Peter", []token.Token{
-		{
-			Key: "bref",
-			Meta: map[string]string{
-				(g.nameMetaKey): "firstname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "false",
-				"desc":          "lorem",
-			},
-		},
-		{
-			Key: "bref",
-			Meta: map[string]string{
-				(g.nameMetaKey): "lastname",
-				(g.typeMetaKey): "string",
-				(g.reqMetaKey):  "true",
-				"desc":          "impsum",
-			},
-		},
-	}, 0, true)
-
-	expected = []string{
-		"Peter:\n",
-		"  type: array\n",
-		"  items:\n",
-		"    required:\n",
-		"    - lastname\n",
-		"    properties:\n",
-		"      firstname:\n",
-		"        description: lorem\n",
-		"        type: string\n",
-		"      lastname:\n",
-		"        description: impsum\n",
-		"        type: string\n",
-	}
-
-	if len(res) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(res))
-		return
-	}
-	for i, l := range res {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
 	}
 
 	// With references, no array
-	res = g.ParseObject("github.com/pkg.Peter", []token.Token{
+	res = yamlOf(t, g.ParseObject([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
@@ -1155,36 +1169,30 @@ func TestParseObject(t *testing.T) {
 				"desc":          "dolor",
 			},
 		},
-	}, 0, false)
+	}, false))
 
 	expected = []string{
-		"Peter:\n",
-		"  type: object\n",
-		"  properties:\n",
-		"    firstname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-		"    details:\n",
-		"      type: object\n",
-		"      required:\n",
+		"type: object\n",
+		"properties:\n",
+		"  firstname:\n",
+		"    description: lorem\n",
+		"    type: string\n",
+		"  details:\n",
+		"    description: impsum\n",
+		"    type: object\n",
+		"    required:\n",
 		"      - age\n",
-		"      properties:\n",
-		"        age:\n",
-		"          description: dolor\n",
-		"          type: int\n",
-	}
-	if len(res) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(res))
-		return
+		"    properties:\n",
+		"      age:\n",
+		"        description: dolor\n",
+		"        type: int\n",
 	}
-	for i, l := range res {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
 	}
 
 	// With references, inner array
-	res = g.ParseObject("github.com/pkg.Peter", []token.Token{
+	res = yamlOf(t, g.ParseObject([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
@@ -1200,7 +1208,6 @@ func TestParseObject(t *testing.T) {
 				(g.nameMetaKey): "details",
 				(g.typeMetaKey): "object",
 				(g.reqMetaKey):  "false",
-				"desc":          "impsum",
 			},
 		},
 		{
@@ -1212,38 +1219,31 @@ func TestParseObject(t *testing.T) {
 				"desc":          "dolor",
 			},
 		},
-	}, 0, false)
+	}, false))
 
 	expected = []string{
-		"Peter:\n",
-		"  type: object\n",
-		"  properties:\n",
-		"    firstname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-		"    details:\n",
-		"      type: object\n",
-		"      required:\n",
+		"type: object\n",
+		"properties:\n",
+		"  firstname:\n",
+		"    description: lorem\n",
+		"    type: string\n",
+		"  details:\n",
+		"    type: object\n",
+		"    required:\n",
 		"      - age\n",
-		"      properties:\n",
-		"        age:\n",
-		"          description: dolor\n",
-		"          type: array\n",
-		"          items:\n",
-		"            type: int\n",
-	}
-	if len(res) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(res))
-		return
+		"    properties:\n",
+		"      age:\n",
+		"        description: dolor\n",
+		"        type: array\n",
+		"        items:\n",
+		"          type: int\n",
 	}
-	for i, l := range res {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
 	}
 
 	// Wrapper
-	res = g.ParseObject("github.com/pkg.Peter", []token.Token{
+	s := g.ParseObject([]token.Token{
 		{
 			Key: "bref",
 			Meta: map[string]string{
@@ -1272,34 +1272,29 @@ func TestParseObject(t *testing.T) {
 				"desc":          "impsum",
 			},
 		},
-	}, 0, false)
+	}, false)
 
 	expected = []string{
-		"Peter:\n",
-		"  type: object\n",
-		"  properties:\n",
-		"    firstname:\n",
-		"      description: lorem\n",
-		"      type: string\n",
-		"    data:\n",
-		"-> 0\n",
-		"    lastname:\n",
-		"      description: impsum\n",
-		"      type: string\n",
-	}
-	if len(res) != len(expected) {
-		t.Errorf("Expected %d entries, got %d", len(expected), len(res))
-		return
-	}
-	for i, l := range res {
-		if l != expected[i] {
-			t.Errorf("Expected \"%s\", got \"%s\"", expected[i], l)
-		}
+		"type: object\n",
+		"properties:\n",
+		"  firstname:\n",
+		"    description: lorem\n",
+		"    type: string\n",
+		"  data: {}\n",
+		"  lastname:\n",
+		"    description: impsum\n",
+		"    type: string\n",
+	}
+	if res = yamlOf(t, s); res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+	if ptr, ok := s.Properties.Property("data"); ok == false || ptr.ptr == false {
+		t.Errorf("Expected the data pointer")
 	}
 }
 
 func TestTokenMeta(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
 	// Found
 	res, ok := g.TokenMeta(
@@ -1334,7 +1329,7 @@ func TestTokenMeta(t *testing.T) {
 }
 
 func TestGetToken(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
 	// Found
 	res, ok := g.GetToken([]token.Token{
@@ -1369,7 +1364,7 @@ func TestGetToken(t *testing.T) {
 }
 
 func TestGetTokens(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
 	var res []token.Token
 
@@ -1416,7 +1411,7 @@ func TestGetTokens(t *testing.T) {
 }
 
 func TestTokensByPrefix(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 	res := g.GetTokensByPrefix([]token.Token{
 		{
 			Key: "a",
@@ -1437,7 +1432,7 @@ func TestTokensByPrefix(t *testing.T) {
 }
 
 func TestGetRequiredTokens(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 	res := g.GetRequiredTokens([]token.Token{
 		{
 			Key: "a",
@@ -1467,7 +1462,7 @@ func TestGetRequiredTokens(t *testing.T) {
 }
 
 func TestParseArray(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 
 	var res []string
 
@@ -1499,7 +1494,7 @@ func TestParseArray(t *testing.T) {
 }
 
 func TestComponentRef(t *testing.T) {
-	g := NewGenerator(false).(*generator)
+	g := NewGenerator(false, Version30).(*generator)
 	g.compMapping = map[string]string{
 		"github.com/pkg.Peter": "Peter",
 	}
@@ -1522,7 +1517,7 @@ func TestComponentRef(t *testing.T) {
 	b := &bytes.Buffer{}
 	log.SetOutput(b)
 	hook := test.NewGlobal()
-	g = NewGenerator(true).(*generator)
+	g = NewGenerator(true, Version30).(*generator)
 	g.compMapping = map[string]string{
 		"github.com/pkg.Peter": "Peter",
 	}
@@ -1541,3 +1536,474 @@ func TestComponentRef(t *testing.T) {
 		t.Errorf("Has %s, expected %s", o, "generator: missing component reference")
 	}
 }
+
+func TestParseObjectMap(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+	g.compMapping["github.com/pkg.Item"] = "Item"
+
+	res := yamlOf(t, g.ParseObject([]token.Token{
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):  "labels",
+				(g.typeMetaKey):  "map",
+				(g.valueMetaKey): "string",
+				(g.reqMetaKey):   "false",
+				"desc":           "lorem",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):   "dates",
+				(g.typeMetaKey):   "map",
+				(g.valueMetaKey):  "array string",
+				(g.formatMetaKey): "date-time",
+				(g.reqMetaKey):    "false",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):  "items",
+				(g.typeMetaKey):  "map",
+				(g.valueMetaKey): "github.com/pkg.Item",
+				(g.reqMetaKey):   "false",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):  "many",
+				(g.typeMetaKey):  "array map",
+				(g.valueMetaKey): "array github.com/pkg.Item",
+				(g.reqMetaKey):   "false",
+			},
+		},
+	}, false))
+
+	expected := []string{
+		"type: object\n",
+		"properties:\n",
+		"  labels:\n",
+		"    description: lorem\n",
+		"    type: object\n",
+		"    additionalProperties:\n",
+		"      type: string\n",
+		"  dates:\n",
+		"    type: object\n",
+		"    additionalProperties:\n",
+		"      type: array\n",
+		"      items:\n",
+		"        type: string\n",
+		"        format: date-time\n",
+		"  items:\n",
+		"    type: object\n",
+		"    additionalProperties:\n",
+		"      $ref: '#/components/schemas/Item'\n",
+		"  many:\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: object\n",
+		"      additionalProperties:\n",
+		"        type: array\n",
+		"        items:\n",
+		"          $ref: '#/components/schemas/Item'\n",
+	}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+}
+
+func TestResolveComponentsMap(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+
+	// Component referencing other component
+	// declared within the same endpoint
+	g.ResolveComponents([]token.Token{
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				"pkg.type":       "github.com/pkg.Order",
+				(g.nameMetaKey):  "items",
+				(g.typeMetaKey):  "map",
+				(g.valueMetaKey): "github.com/pkg.Item",
+				(g.reqMetaKey):   "false",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				"pkg.type":      "github.com/pkg.Item",
+				(g.nameMetaKey): "name",
+				(g.typeMetaKey): "string",
+				(g.reqMetaKey):  "false",
+			},
+		},
+	})
+
+	expected := []string{
+		"type: object\n",
+		"properties:\n",
+		"  items:\n",
+		"    type: object\n",
+		"    additionalProperties:\n",
+		"      $ref: '#/components/schemas/Item'\n",
+	}
+	res := yamlOf(t, g.compCache["github.com/pkg.Order"])
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+	if _, ok := g.compCache["github.com/pkg.Item"]; ok == false {
+		t.Errorf("Expected \"%s\" component", "github.com/pkg.Item")
+	}
+}
+
+func TestParseObjectComponents(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+	g.compMapping["github.com/pkg.Node"] = "Node"
+
+	res := yamlOf(t, g.ParseObject([]token.Token{
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "parent",
+				(g.typeMetaKey): "github.com/pkg.Node",
+				(g.reqMetaKey):  "false",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "first",
+				(g.typeMetaKey): "github.com/pkg.Node",
+				(g.reqMetaKey):  "false",
+				"desc":          "lorem",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "children",
+				(g.typeMetaKey): "array github.com/pkg.Node",
+				(g.reqMetaKey):  "true",
+				"desc":          "ipsum",
+			},
+		},
+	}, false))
+
+	expected := []string{
+		"type: object\n",
+		"required:\n",
+		"  - children\n",
+		"properties:\n",
+		"  parent:\n",
+		"    $ref: '#/components/schemas/Node'\n",
+		"  first:\n",
+		"    description: lorem\n",
+		"    allOf:\n",
+		"      - $ref: '#/components/schemas/Node'\n",
+		"  children:\n",
+		"    description: ipsum\n",
+		"    type: array\n",
+		"    items:\n",
+		"      $ref: '#/components/schemas/Node'\n",
+	}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+
+	// Data pointer of the wrapper
+	s := g.ParseObject([]token.Token{
+		{
+			Key: "swrapref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "data",
+				(g.typeMetaKey): "github.com/pkg.Node",
+				(g.reqMetaKey):  "false",
+				(g.prtMetaKey):  "true",
+			},
+		},
+	}, false)
+	if path, ok := s.DataPointer(); ok == false || strings.Join(path, ".") != "data" {
+		t.Errorf("Expected \"%s\", got \"%v\"", "data", path)
+	}
+}
+
+func TestParseObjectConstraints(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+
+	res := yamlOf(t, g.ParseObject([]token.Token{
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):   "email",
+				(g.typeMetaKey):   "string",
+				(g.formatMetaKey): "email",
+				(g.reqMetaKey):    "true",
+				"minLength":       "1",
+				"maxLength":       "64",
+				"pattern":         "^[a-z\\.]+$",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):    "age",
+				(g.typeMetaKey):    "integer",
+				(g.reqMetaKey):     "false",
+				"minimum":          "0",
+				"exclusiveMinimum": "true",
+				"maximum":          "150",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "tags",
+				(g.typeMetaKey): "array string",
+				(g.enumMetaKey): "[\"a\",\"b\"]",
+				(g.reqMetaKey):  "false",
+				"minItems":      "1",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey):  "labels",
+				(g.typeMetaKey):  "map",
+				(g.valueMetaKey): "string",
+				(g.reqMetaKey):   "false",
+				"maxProperties":  "10",
+			},
+		},
+	}, false))
+
+	expected := []string{
+		"type: object\n",
+		"required:\n",
+		"  - email\n",
+		"properties:\n",
+		"  email:\n",
+		"    type: string\n",
+		"    format: email\n",
+		"    minLength: 1\n",
+		"    maxLength: 64\n",
+		"    pattern: ^[a-z\\.]+$\n",
+		"  age:\n",
+		"    type: integer\n",
+		"    minimum: 0\n",
+		"    exclusiveMinimum: true\n",
+		"    maximum: 150\n",
+		"  tags:\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: string\n",
+		"      enum:\n",
+		"        - a\n",
+		"        - b\n",
+		"    minItems: 1\n",
+		"  labels:\n",
+		"    type: object\n",
+		"    additionalProperties:\n",
+		"      type: string\n",
+		"    maxProperties: 10\n",
+	}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+}
+
+func TestResolveComponentsDuplicate(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+
+	// The same component referenced twice
+	// within the endpoint, e.g. by a wrapper
+	// and by a response
+	prop := func() token.Token {
+		return token.Token{
+			Key: "fref",
+			Meta: map[string]string{
+				"pkg.type":      "github.com/pkg.Error",
+				(g.nameMetaKey): "code",
+				(g.typeMetaKey): "integer",
+				(g.reqMetaKey):  "false",
+			},
+		}
+	}
+	g.ResolveComponents([]token.Token{prop(), prop()})
+
+	expected := []string{
+		"type: object\n",
+		"properties:\n",
+		"  code:\n",
+		"    type: integer\n",
+	}
+	res := yamlOf(t, g.compCache["github.com/pkg.Error"])
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+}
+
+func TestParseObjectAnnotations(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+	g.compMapping["github.com/pkg.Detail"] = "Detail"
+
+	res := yamlOf(t, g.ParseObject([]token.Token{
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "id",
+				(g.typeMetaKey): "integer",
+				(g.reqMetaKey):  "false",
+				"example":       "42",
+				"readOnly":      "true",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "tags",
+				(g.typeMetaKey): "array string",
+				(g.reqMetaKey):  "false",
+				"default":       "[\"a\"]",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "detail",
+				(g.typeMetaKey): "github.com/pkg.Detail",
+				(g.reqMetaKey):  "false",
+				"deprecated":    "true",
+			},
+		},
+		{
+			Key: "bref",
+			Meta: map[string]string{
+				(g.nameMetaKey): "name",
+				(g.typeMetaKey): "string",
+				(g.reqMetaKey):  "false",
+				"example":       "\"yes: no\"",
+			},
+		},
+	}, false))
+
+	expected := []string{
+		"type: object\n",
+		"properties:\n",
+		"  id:\n",
+		"    type: integer\n",
+		"    example: 42\n",
+		"    readOnly: true\n",
+		"  tags:\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: string\n",
+		"    default:\n",
+		"      - a\n",
+		"  detail:\n",
+		"    allOf:\n",
+		"      - $ref: '#/components/schemas/Detail'\n",
+		"    deprecated: true\n",
+		"  name:\n",
+		"    type: string\n",
+		"    example: 'yes: no'\n",
+	}
+	if res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+}
+
+func TestNameComponents(t *testing.T) {
+	endpoints := [][]token.Token{
+		{
+			{Key: "bref", Meta: map[string]string{"pkg.type": "github.com/pkg/b.Person"}},
+			{Key: "sref", Meta: map[string]string{"pkg.type": "github.com/pkg/b.Item"}},
+		},
+		{
+			{Key: "fref", Meta: map[string]string{"pkg.type": "github.com/pkg/a.Person"}},
+			{Key: "swrapref", Meta: map[string]string{"pkg.type": "github.com/pkg/c.Person"}},
+		},
+	}
+	expected := map[string]string{
+		"github.com/pkg/a.Person": "Person",
+		"github.com/pkg/b.Person": "Person1",
+		"github.com/pkg/b.Item":   "Item",
+	}
+
+	// The naming does not depend on the order of the endpoints
+	for _, e := range [][][]token.Token{endpoints, {endpoints[1], endpoints[0]}} {
+		g := NewGenerator(false, Version30).(*generator)
+		g.NameComponents(e)
+		if len(g.compMapping) != len(expected) {
+			t.Errorf("Expected %d components, got %d", len(expected), len(g.compMapping))
+			continue
+		}
+		for k, v := range expected {
+			if g.compMapping[k] != v {
+				t.Errorf("Expected \"%s\", got \"%s\"", v, g.compMapping[k])
+			}
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	g := NewGenerator(false, Version30).(*generator)
+	router := func(url, method string) []token.Token {
+		return []token.Token{{Key: "router", Meta: map[string]string{"url": url, "method": method}}}
+	}
+	endpoints := [][]token.Token{
+		router("/b", "post"),
+		router("/a", "delete, get"),
+		router("/b", "search, get"),
+		router("/a", "patch"),
+		router("/b", "copy"),
+		{{Key: "summary"}},
+		{{Key: "webhook", Meta: map[string]string{"url": "newPet", "method": "post"}}},
+	}
+
+	expected := "/a,/b"
+	if urls := strings.Join(g.SortedURLs(endpoints, "router"), ","); urls != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, urls)
+	}
+	expected = "newPet"
+	if urls := strings.Join(g.SortedURLs(endpoints, "webhook"), ","); urls != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, urls)
+	}
+
+	tests := map[string]string{
+		"/a": "1:get,1:delete,3:patch",
+		"/b": "2:get,0:post,4:copy,2:search",
+	}
+	for url, expected := range tests {
+		ops := make([]string, 0)
+		for _, op := range g.Operations(url, "router", endpoints) {
+			ops = append(ops, fmt.Sprintf("%d:%s", op.index, op.method))
+		}
+		if strings.Join(ops, ",") != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, strings.Join(ops, ","))
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]string{
+		"3.0":   Version30,
+		"3.0.2": Version30,
+		"3.1":   Version31,
+		"3.1.0": Version31,
+	}
+	for v, expected := range tests {
+		res, err := ParseVersion(v)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		if res != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+		}
+	}
+	if _, err := ParseVersion("2.0"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
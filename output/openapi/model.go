@@ -0,0 +1,378 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/spaceavocado/apidoc/output"
+)
+
+// Document of the OpenAPI specification
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []*Server            `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Webhooks   map[string]*PathItem `json:"webhooks,omitempty"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// Info about the API
+type Info struct {
+	Title          string   `json:"title,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Contact        *Contact `json:"contact,omitempty"`
+	License        *License `json:"license,omitempty"`
+	Version        string   `json:"version,omitempty"`
+}
+
+// Contact of the API
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// License of the API
+type License struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Server of the API
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of the path,
+// in the canonical order of the methods
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+	Trace   *Operation `json:"trace,omitempty"`
+}
+
+// SetOperation of the method, false if
+// the method is not supported
+func (p *PathItem) SetOperation(method string, op *Operation) bool {
+	switch method {
+	case "get":
+		p.Get = op
+	case "put":
+		p.Put = op
+	case "post":
+		p.Post = op
+	case "delete":
+		p.Delete = op
+	case "options":
+		p.Options = op
+	case "head":
+		p.Head = op
+	case "patch":
+		p.Patch = op
+	case "trace":
+		p.Trace = op
+	default:
+		return false
+	}
+	return true
+}
+
+// Operation of the method, nil if not set
+func (p *PathItem) Operation(method string) *Operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	case "trace":
+		return p.Trace
+	}
+	return nil
+}
+
+// Methods of the operations of the path,
+// in the canonical order of the methods
+func (p *PathItem) Methods() []string {
+	methods := make([]string, 0)
+	for _, m := range methodsOrder {
+		if p.Operation(m) != nil {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// Operations of the path, in the canonical order of the methods
+func (p *PathItem) Operations() []*Operation {
+	ops := make([]*Operation, 0)
+	for _, m := range p.Methods() {
+		ops = append(ops, p.Operation(m))
+	}
+	return ops
+}
+
+// Operation of the endpoint
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter of the operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody of the operation
+type RequestBody struct {
+	Content map[string]*MediaType `json:"content"`
+}
+
+// Response of the operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType of the content
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components of the document
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema of the data. The type is the list of
+// the types in 3.1, if nullable, e.g. [string, null].
+// The nullable is the x-nullable extension in Swagger 2.0.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 interface{}        `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           Properties         `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              interface{}        `json:"minimum,omitempty"`
+	ExclusiveMinimum     interface{}        `json:"exclusiveMinimum,omitempty"`
+	Maximum              interface{}        `json:"maximum,omitempty"`
+	ExclusiveMaximum     interface{}        `json:"exclusiveMaximum,omitempty"`
+	MinLength            interface{}        `json:"minLength,omitempty"`
+	MaxLength            interface{}        `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             interface{}        `json:"minItems,omitempty"`
+	MaxItems             interface{}        `json:"maxItems,omitempty"`
+	MinProperties        interface{}        `json:"minProperties,omitempty"`
+	MaxProperties        interface{}        `json:"maxProperties,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Nullable             interface{}        `json:"nullable,omitempty"`
+	XNullable            interface{}        `json:"x-nullable,omitempty"`
+	ReadOnly             interface{}        `json:"readOnly,omitempty"`
+	WriteOnly            interface{}        `json:"writeOnly,omitempty"`
+	Deprecated           interface{}        `json:"deprecated,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// Data pointer of the wrapper object,
+	// i.e. replaced by the wrapped data
+	ptr bool
+}
+
+// MarshalJSON of the schema, the empty
+// type is omitted as the other empty fields
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	c := schema(s)
+	if c.Type == "" {
+		c.Type = nil
+	}
+	return output.MarshalJSON(c, "")
+}
+
+// Property of the object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties of the object schema, in the declaration order
+type Properties []Property
+
+// MarshalJSON of the properties as an object,
+// keeping the declaration order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := output.MarshalJSON(prop.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		schema, err := output.MarshalJSON(prop.Schema, "")
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(schema)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Property found by the name
+func (p Properties) Property(name string) (*Schema, bool) {
+	for _, prop := range p {
+		if prop.Name == name {
+			return prop.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// DataPointer of the wrapper schema, i.e. the
+// path of the property names leading to it
+func (s *Schema) DataPointer() ([]string, bool) {
+	for _, prop := range s.Properties {
+		if prop.Schema.ptr {
+			return []string{prop.Name}, true
+		}
+		if path, ok := prop.Schema.DataPointer(); ok {
+			return append([]string{prop.Name}, path...), true
+		}
+	}
+	return nil, false
+}
+
+// Wrap the data into the copy of the wrapper schema, the
+// data replaces the data pointer found by the path. Only
+// the schemas on the path are copied.
+func (s *Schema) Wrap(path []string, data *Schema) *Schema {
+	if len(path) == 0 {
+		return data
+	}
+	wrapper := *s
+	wrapper.Properties = make(Properties, len(s.Properties))
+	for i, prop := range s.Properties {
+		if prop.Name == path[0] {
+			prop.Schema = prop.Schema.Wrap(path[1:], data)
+		}
+		wrapper.Properties[i] = prop
+	}
+	return &wrapper
+}
+
+// Schemas of the parameters, the bodies, the responses,
+// the webhooks, and of the components of the document
+func (d *Document) Schemas() []*Schema {
+	schemas := make([]*Schema, 0)
+	items := make([]*PathItem, 0, len(d.Paths)+len(d.Webhooks))
+	for _, item := range d.Paths {
+		items = append(items, item)
+	}
+	for _, item := range d.Webhooks {
+		items = append(items, item)
+	}
+	for _, item := range items {
+		for _, op := range item.Operations() {
+			for _, p := range op.Parameters {
+				schemas = append(schemas, p.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					schemas = append(schemas, mt.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, mt := range resp.Content {
+					schemas = append(schemas, mt.Schema)
+				}
+			}
+		}
+	}
+	if d.Components != nil {
+		for _, s := range d.Components.Schemas {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+// WalkSchemas applies the function on the schemas and on
+// their subschemas, recursively, every schema is visited once
+func WalkSchemas(schemas []*Schema, fn func(s *Schema)) {
+	visited := make(map[*Schema]bool, 0)
+	for _, s := range schemas {
+		walkSchema(s, visited, fn)
+	}
+}
+
+// walkSchema applies the function on the schema and on its subschemas
+func walkSchema(s *Schema, visited map[*Schema]bool, fn func(s *Schema)) {
+	if s == nil || visited[s] {
+		return
+	}
+	visited[s] = true
+	fn(s)
+	walkSchema(s.Items, visited, fn)
+	walkSchema(s.AdditionalProperties, visited, fn)
+	for _, prop := range s.Properties {
+		walkSchema(prop.Schema, visited, fn)
+	}
+	for _, sub := range s.AllOf {
+		walkSchema(sub, visited, fn)
+	}
+	for _, sub := range s.AnyOf {
+		walkSchema(sub, visited, fn)
+	}
+	for _, def := range s.Defs {
+		walkSchema(def, visited, fn)
+	}
+}
+
+// metaValue of the token meta, valid JSON values are
+// kept as they are, e.g. numbers, booleans, or arrays,
+// others are taken as strings
+func metaValue(m string) interface{} {
+	if json.Valid([]byte(m)) {
+		return json.RawMessage(m)
+	}
+	return m
+}
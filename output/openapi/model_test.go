@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spaceavocado/apidoc/output"
+)
+
+func TestProperties(t *testing.T) {
+	s := &Schema{
+		Type: "object",
+		Properties: Properties{
+			{Name: "z", Schema: &Schema{Type: "string", Pattern: "<a>"}},
+			{Name: "a", Schema: &Schema{Type: "integer", Example: metaValue("1")}},
+		},
+	}
+	res, err := output.MarshalJSON(s, "")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := `{"type":"object","properties":{"z":{"type":"string","pattern":"<a>"},"a":{"type":"integer","example":1}}}`
+	if string(res) != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, string(res))
+	}
+
+	// Found by the name
+	if p, ok := s.Properties.Property("a"); ok == false || p.Type != "integer" {
+		t.Errorf("Expected the property \"%s\"", "a")
+	}
+	if _, ok := s.Properties.Property("missing"); ok {
+		t.Errorf("Expected no property")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	wrapper := &Schema{
+		Type: "object",
+		Properties: Properties{
+			{Name: "status", Schema: &Schema{Type: "string"}},
+			{Name: "result", Schema: &Schema{
+				Type: "object",
+				Properties: Properties{
+					{Name: "data", Schema: &Schema{ptr: true}},
+				},
+			}},
+		},
+	}
+
+	// Data pointer
+	path, ok := wrapper.DataPointer()
+	if ok == false || strings.Join(path, ".") != "result.data" {
+		t.Errorf("Expected \"%s\", got \"%v\"", "result.data", path)
+		return
+	}
+	if _, ok := (&Schema{}).DataPointer(); ok {
+		t.Errorf("Expected no data pointer")
+	}
+
+	// Wrapped data, the wrapper is not modified
+	data := &Schema{Ref: "#/components/schemas/Peter"}
+	wrapped := wrapper.Wrap(path, data)
+	if p, _ := wrapped.Properties[1].Schema.Properties.Property("data"); p != data {
+		t.Errorf("Expected the wrapped data")
+	}
+	if p, _ := wrapper.Properties[1].Schema.Properties.Property("data"); p.ptr == false {
+		t.Errorf("Expected the data pointer of the wrapper")
+	}
+	if wrapped.Properties[0].Schema != wrapper.Properties[0].Schema {
+		t.Errorf("Expected the shared schema outside the path")
+	}
+
+	// No path
+	if wrapper.Wrap(nil, data) != data {
+		t.Errorf("Expected the data")
+	}
+}
+
+func TestPathItem(t *testing.T) {
+	p := &PathItem{}
+	for _, m := range methodsOrder {
+		if p.SetOperation(m, &Operation{OperationID: m}) == false {
+			t.Errorf("Expected the method \"%s\" to be supported", m)
+		}
+	}
+	if p.SetOperation("copy", &Operation{}) {
+		t.Errorf("Expected the method \"%s\" not to be supported", "copy")
+	}
+
+	// Canonical order
+	b, _ := json.Marshal(p)
+	res := make([]string, 0)
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.Token()
+	for dec.More() {
+		k, _ := dec.Token()
+		res = append(res, k.(string))
+		var v interface{}
+		dec.Decode(&v)
+	}
+	if strings.Join(res, ",") != strings.Join(methodsOrder, ",") {
+		t.Errorf("Expected \"%v\", got \"%v\"", methodsOrder, res)
+	}
+
+	// Methods
+	p = &PathItem{Post: &Operation{OperationID: "post"}, Get: &Operation{OperationID: "get"}}
+	if methods := strings.Join(p.Methods(), ","); methods != "get,post" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "get,post", methods)
+	}
+	if ops := p.Operations(); len(ops) != 2 || ops[1].OperationID != "post" {
+		t.Errorf("Expected %d operations, got %d", 2, len(ops))
+	}
+	if p.Operation("copy") != nil {
+		t.Errorf("Expected no operation of the method \"%s\"", "copy")
+	}
+}
+
+func TestMetaValue(t *testing.T) {
+	if v, ok := metaValue("[1, 2]").(json.RawMessage); ok == false || string(v) != "[1, 2]" {
+		t.Errorf("Expected the JSON value, got %v", v)
+	}
+	if v, ok := metaValue("lorem").(string); ok == false || v != "lorem" {
+		t.Errorf("Expected the string value, got %v", v)
+	}
+}
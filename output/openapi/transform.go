@@ -1,8 +1,8 @@
 package openapi
 
 import (
-	"fmt"
 	"regexp"
+	"strings"
 )
 
 // transformation performs a transform operation
@@ -81,14 +81,11 @@ func newTrsMediaType() transformation {
 	}
 }
 
-// newTrsQuote wrap the input into quotes
-func newTrsQuote() transformation {
+// newTrsUnquote removes the quotes wrapping the input
+func newTrsUnquote() transformation {
 	return func(input string) string {
-		if input[:1] != "\"" {
-			input = fmt.Sprintf("\"%s", input)
-		}
-		if input[len(input)-1:] != "\"" {
-			input = fmt.Sprintf("%s\"", input)
+		if len(input) > 1 && strings.HasPrefix(input, "\"") && strings.HasSuffix(input, "\"") {
+			return input[1 : len(input)-1]
 		}
 		return input
 	}
@@ -111,33 +108,11 @@ func newTrsArray() transformation {
 	}
 }
 
-// newTrsSafeValue prevents non-quoted yaml string
-// containing reserved characters
-func newTrsSafeValue() transformation {
-	reserved := regexp.MustCompile("[-\\?:,\\[\\]{}#&\\*!\\|>'\"%@`”]")
-	clean := regexp.MustCompile("\"{2,}")
-	escape := regexp.MustCompile("[^\\\\]\"")
-	return func(input string) string {
-		if reserved.MatchString(input) {
-			if input[:1] == "\"" {
-				input = input[1:]
-			}
-			if input[len(input)-1:] == "\"" {
-				input = input[:len(input)-1]
-			}
-			input = clean.ReplaceAllString(input, "\"")
-			return fmt.Sprintf("\"%s\"", escape.ReplaceAllString(input, "\\\""))
-		}
-		return input
-	}
-}
-
 // transformations
 var (
 	trsType         = newTrsType()
 	trsMediaType    = newTrsMediaType()
-	trsQuote        = newTrsQuote()
+	trsUnquote      = newTrsUnquote()
 	trsSpecialChars = newTrsSpecialChars()
 	trsArray        = newTrsArray()
-	trsSafeValue    = newTrsSafeValue()
 )
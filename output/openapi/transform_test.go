@@ -47,15 +47,17 @@ func TestTrsMediaType(t *testing.T) {
 	}
 }
 
-func TestTrsQuote(t *testing.T) {
-	trs := newTrsQuote()
-	res := trs("hello")
-	if res != "\"hello\"" {
-		t.Errorf("Expected \"%s\", got \"%s\"", "\"hello\"", res)
+func TestTrsUnquote(t *testing.T) {
+	tests := map[string]string{
+		"\"lorem ipsum\"": "lorem ipsum",
+		"lorem":           "lorem",
+		"\"":              "\"",
+		"\"lorem":         "\"lorem",
 	}
-	res = trs("\"hello\"")
-	if res != "\"hello\"" {
-		t.Errorf("Expected \"%s\", got \"%s\"", "\"hello\"", res)
+	for input, expected := range tests {
+		if res := trsUnquote(input); res != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+		}
 	}
 }
 
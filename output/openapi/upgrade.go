@@ -0,0 +1,147 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Upgrade the document to OpenAPI 3.1, i.e. the schemas
+// are converted into the JSON Schema 2020-12 constructs
+func (g *generator) Upgrade(doc *Document) {
+	// Inline objects of the components are
+	// moved into the definitions of the component
+	if doc.Components != nil {
+		for name, s := range doc.Components.Schemas {
+			g.HoistDefs(s, s, fmt.Sprintf("#/components/schemas/%s", pointerEscape(name)), "")
+		}
+	}
+
+	WalkSchemas(doc.Schemas(), upgradeSchema)
+}
+
+// HoistDefs moves the inline objects of the schema into the
+// definitions of the component, recursively. The definitions
+// are named by the path of the property names, e.g. address.geo
+func (g *generator) HoistDefs(comp, s *Schema, ref, prefix string) {
+	for i, prop := range s.Properties {
+		name := prop.Name
+		if prefix != "" {
+			name = fmt.Sprintf("%s.%s", prefix, prop.Name)
+		}
+
+		// Inline object, or the array of inline objects
+		obj := prop.Schema
+		if obj.Type == "array" && obj.Items != nil {
+			obj = obj.Items
+		}
+		if obj.InlineObject() == false {
+			continue
+		}
+		g.HoistDefs(comp, obj, ref, name)
+
+		if comp.Defs == nil {
+			comp.Defs = make(map[string]*Schema, 0)
+		}
+		comp.Defs[name] = obj
+		defRef := &Schema{Ref: fmt.Sprintf("%s/$defs/%s", ref, pointerEscape(name))}
+		if obj == prop.Schema {
+			// The description belongs to the property
+			defRef.Description, obj.Description = obj.Description, ""
+			s.Properties[i].Schema = defRef
+		} else {
+			prop.Schema.Items = defRef
+		}
+	}
+}
+
+// upgradeSchema to JSON Schema 2020-12
+func upgradeSchema(s *Schema) {
+	// Siblings of the reference are allowed,
+	// i.e. the reference is not wrapped by allOf
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" && s.Ref == "" && s.Type == nil {
+		s.Ref = s.AllOf[0].Ref
+		s.AllOf = nil
+	}
+
+	// Nullable is the null type
+	nullable, _ := metaBool(s.Nullable)
+	if nullable {
+		if t, ok := s.Type.(string); ok && t != "" {
+			s.Type = []string{t, "null"}
+		} else if s.Ref != "" {
+			s.AnyOf = []*Schema{{Ref: s.Ref}, {Type: "null"}}
+			s.Ref = ""
+		}
+	}
+	s.Nullable = nil
+
+	// Examples
+	if s.Example != nil {
+		s.Examples = []interface{}{s.Example}
+		s.Example = nil
+	}
+
+	// The enum of the nullable value lists the null,
+	// the single enum value is the constant
+	if raw, ok := s.Enum.(json.RawMessage); ok {
+		values := make([]json.RawMessage, 0)
+		if json.Unmarshal(raw, &values) == nil {
+			if nullable && hasNull(values) == false {
+				s.Enum = append(values, json.RawMessage("null"))
+			} else if nullable == false && len(values) == 1 {
+				s.Const = values[0]
+				s.Enum = nil
+			}
+		}
+	}
+
+	// The exclusive bounds are the values
+	s.Minimum, s.ExclusiveMinimum = exclusiveBound(s.Minimum, s.ExclusiveMinimum)
+	s.Maximum, s.ExclusiveMaximum = exclusiveBound(s.Maximum, s.ExclusiveMaximum)
+}
+
+// hasNull checks if the null is in the values
+func hasNull(values []json.RawMessage) bool {
+	for _, v := range values {
+		if string(v) == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusiveBound of 3.1, i.e. the exclusive flag of 3.0
+// is replaced by the value of the bound
+func exclusiveBound(bound, exclusive interface{}) (interface{}, interface{}) {
+	flag, ok := metaBool(exclusive)
+	if ok == false {
+		return bound, exclusive
+	}
+	if flag && bound != nil {
+		return nil, bound
+	}
+	return bound, nil
+}
+
+// metaBool of the meta value, not ok
+// if the value is not a boolean
+func metaBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case json.RawMessage:
+		switch string(b) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// pointerEscape the JSON pointer reference token
+func pointerEscape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+func TestUpgrade(t *testing.T) {
+	g := NewGenerator(false, Version31).(*generator)
+	prop := func(meta map[string]string) token.Token {
+		meta["pkg.type"] = "github.com/pkg.Pet"
+		if _, ok := meta[g.reqMetaKey]; ok == false {
+			meta[g.reqMetaKey] = "false"
+		}
+		return token.Token{Key: "bref", Meta: meta}
+	}
+	doc := g.Document(
+		[]token.Token{
+			{Key: "ver", Meta: map[string]string{"value": "1.0"}},
+		},
+		[][]token.Token{
+			{
+				{Key: "webhook", Meta: map[string]string{"url": "newPet", "method": "post"}},
+				{Key: "accept", Meta: map[string]string{"value": "json"}},
+				{Key: "body", Meta: map[string]string{"value": "github.com/pkg.Pet"}},
+				prop(map[string]string{(g.nameMetaKey): "name", (g.typeMetaKey): "string", "nullable": "true", "example": "\"Rex\""}),
+				prop(map[string]string{(g.nameMetaKey): "kind", (g.typeMetaKey): "string", (g.enumMetaKey): "[\"dog\"]"}),
+				prop(map[string]string{(g.nameMetaKey): "age", (g.typeMetaKey): "integer", "minimum": "0", "exclusiveMinimum": "true"}),
+				prop(map[string]string{(g.nameMetaKey): "owner", (g.typeMetaKey): "github.com/pkg.Pet", "nullable": "true"}),
+				prop(map[string]string{(g.nameMetaKey): "home", (g.typeMetaKey): "object", "desc": "Home of the pet"}),
+				prop(map[string]string{(g.nameMetaKey): "home.city", (g.typeMetaKey): "string"}),
+				prop(map[string]string{(g.nameMetaKey): "home.geo", (g.typeMetaKey): "object"}),
+				prop(map[string]string{(g.nameMetaKey): "home.geo.lat", (g.typeMetaKey): "number"}),
+				prop(map[string]string{(g.nameMetaKey): "toys", (g.typeMetaKey): "array object"}),
+				prop(map[string]string{(g.nameMetaKey): "toys.name", (g.typeMetaKey): "string"}),
+				{Key: "success", Meta: map[string]string{"code": "200", "type": "string", "desc": "OK"}},
+			},
+		},
+	)
+
+	expected := []string{
+		"openapi: 3.1.0\n",
+		"info:\n",
+		"  version: \"1.0\"\n",
+		"paths: {}\n",
+		"webhooks:\n",
+		"  newPet:\n",
+		"    post:\n",
+		"      requestBody:\n",
+		"        content:\n",
+		"          application/json:\n",
+		"            schema:\n",
+		"              $ref: '#/components/schemas/Pet'\n",
+		"      responses:\n",
+		"        \"200\":\n",
+		"          description: OK\n",
+		"          content:\n",
+		"            text/plain:\n",
+		"              schema:\n",
+		"                type: string\n",
+		"components:\n",
+		"  schemas:\n",
+		"    Pet:\n",
+		"      type: object\n",
+		"      properties:\n",
+		"        name:\n",
+		"          type:\n",
+		"            - string\n",
+		"            - \"null\"\n",
+		"          examples:\n",
+		"            - Rex\n",
+		"        kind:\n",
+		"          type: string\n",
+		"          const: dog\n",
+		"        age:\n",
+		"          type: integer\n",
+		"          exclusiveMinimum: 0\n",
+		"        owner:\n",
+		"          anyOf:\n",
+		"            - $ref: '#/components/schemas/Pet'\n",
+		"            - type: \"null\"\n",
+		"        home:\n",
+		"          $ref: '#/components/schemas/Pet/$defs/home'\n",
+		"          description: Home of the pet\n",
+		"        toys:\n",
+		"          type: array\n",
+		"          items:\n",
+		"            $ref: '#/components/schemas/Pet/$defs/toys'\n",
+		"      $defs:\n",
+		"        home:\n",
+		"          type: object\n",
+		"          properties:\n",
+		"            city:\n",
+		"              type: string\n",
+		"            geo:\n",
+		"              $ref: '#/components/schemas/Pet/$defs/home.geo'\n",
+		"        home.geo:\n",
+		"          type: object\n",
+		"          properties:\n",
+		"            lat:\n",
+		"              type: number\n",
+		"        toys:\n",
+		"          type: object\n",
+		"          properties:\n",
+		"            name:\n",
+		"              type: string\n",
+	}
+	if res := yamlOf(t, doc); res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+
+	// Webhooks are skipped in 3.0
+	b := &bytes.Buffer{}
+	log.SetOutput(b)
+	hook := test.NewGlobal()
+	g = NewGenerator(true, Version30).(*generator)
+	doc = g.Document(
+		[]token.Token{},
+		[][]token.Token{
+			{{Key: "webhook", Meta: map[string]string{"url": "newPet", "method": "post"}}},
+		},
+	)
+	if doc.Webhooks != nil {
+		t.Errorf("Expected no webhooks, got %d", len(doc.Webhooks))
+	}
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+	}
+}
+
+func TestUpgradeSchema(t *testing.T) {
+	tests := map[string]struct {
+		schema   *Schema
+		expected string
+	}{
+		"Nullable array": {
+			schema:   &Schema{Type: "array", Items: &Schema{Type: "string"}, Nullable: metaValue("true")},
+			expected: "type:\n  - array\n  - \"null\"\nitems:\n  type: string\n",
+		},
+		"Not nullable": {
+			schema:   &Schema{Type: "string", Nullable: metaValue("false")},
+			expected: "type: string\n",
+		},
+		"Reference with siblings": {
+			schema:   &Schema{Description: "Detail", AllOf: []*Schema{{Ref: "#/components/schemas/Detail"}}, Deprecated: metaValue("true")},
+			expected: "$ref: '#/components/schemas/Detail'\ndescription: Detail\ndeprecated: true\n",
+		},
+		"Enum": {
+			schema:   &Schema{Type: "integer", Enum: metaValue("[1,2]")},
+			expected: "type: integer\nenum:\n  - 1\n  - 2\n",
+		},
+		"Nullable enum": {
+			schema:   &Schema{Type: "string", Enum: metaValue(`["dog","cat"]`), Nullable: metaValue("true")},
+			expected: "type:\n  - string\n  - \"null\"\nenum:\n  - dog\n  - cat\n  - null\n",
+		},
+		"Nullable single enum": {
+			schema:   &Schema{Type: "string", Enum: metaValue(`["dog"]`), Nullable: metaValue("true")},
+			expected: "type:\n  - string\n  - \"null\"\nenum:\n  - dog\n  - null\n",
+		},
+		"Exclusive maximum": {
+			schema:   &Schema{Type: "number", Minimum: metaValue("1"), Maximum: metaValue("10"), ExclusiveMaximum: metaValue("true")},
+			expected: "type: number\nminimum: 1\nexclusiveMaximum: 10\n",
+		},
+		"Inclusive maximum": {
+			schema:   &Schema{Type: "number", Maximum: metaValue("10"), ExclusiveMaximum: metaValue("false")},
+			expected: "type: number\nmaximum: 10\n",
+		},
+	}
+	for name, test := range tests {
+		upgradeSchema(test.schema)
+		if res := yamlOf(t, test.schema); res != test.expected {
+			t.Errorf("%s: Expected \"%s\", got \"%s\"", name, test.expected, res)
+		}
+	}
+}
+
+func TestPointerEscape(t *testing.T) {
+	expected := "a~1b~0c"
+	if res := pointerEscape("a/b~c"); res != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, res)
+	}
+}
@@ -0,0 +1,215 @@
+// Package swagger generates the documentation into Swagger 2.0 format.
+// Specification: https://swagger.io/specification/v2/
+package swagger
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spaceavocado/apidoc/misc"
+	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+// Version of the Swagger specification
+const Version = "2.0"
+
+type generator struct {
+	verbose bool
+	// Output formats, i.e. yaml, json
+	formats []string
+
+	// Reference prefix of the OpenAPI components,
+	// and of the Swagger definitions respectively
+	componentsRef  string
+	definitionsRef string
+}
+
+// Generate the documentation from the given tokens for the
+// main section and for the given endpoints, into the file.
+// The document is written in each format, i.e. the extension
+// of the file is replaced by the format.
+func (g *generator) Generate(main []token.Token, endpoints [][]token.Token, file string) error {
+	doc := g.Document(openapi.NewDocument(g.verbose, openapi.Version30, main, endpoints))
+	return output.WriteFiles(doc, file, g.formats)
+}
+
+// Document model converted from the OpenAPI document
+func (g *generator) Document(oa *openapi.Document) *Document {
+	doc := &Document{
+		Swagger: Version,
+		Info:    oa.Info,
+		Paths:   make(map[string]*PathItem, len(oa.Paths)),
+	}
+	g.Servers(doc, oa.Servers)
+
+	// Schemas, the references of the components
+	// are the references of the definitions
+	openapi.WalkSchemas(oa.Schemas(), g.Schema)
+	if oa.Components != nil {
+		doc.Definitions = oa.Components.Schemas
+	}
+
+	for url, item := range oa.Paths {
+		doc.Paths[url] = g.PathItem(url, item)
+	}
+	return doc
+}
+
+// Servers of the API, i.e. the host and the base path are taken
+// from the first server, the schemes from the servers of the
+// same host and base path. Other servers are skipped.
+func (g *generator) Servers(doc *Document, servers []*openapi.Server) {
+	found := false
+	for _, s := range servers {
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			if g.verbose {
+				log.WithError(err).Warnf("generator: invalid server url \"%s\"", s.URL)
+			}
+			continue
+		}
+		if found == false {
+			doc.Host = u.Host
+			doc.BasePath = u.Path
+			found = true
+		} else if u.Host != doc.Host || u.Path != doc.BasePath {
+			if g.verbose {
+				log.Warnf("generator: server \"%s\" skipped, only one host is supported", s.URL)
+			}
+			continue
+		}
+		if u.Scheme != "" && misc.StringInSlice(u.Scheme, doc.Schemes) == false {
+			doc.Schemes = append(doc.Schemes, u.Scheme)
+		}
+	}
+}
+
+// PathItem converted from the OpenAPI path item
+func (g *generator) PathItem(url string, item *openapi.PathItem) *PathItem {
+	if item.Trace != nil && g.verbose {
+		log.Warnf("generator: unsupported method \"%s\" of the path \"%s\"", "trace", url)
+	}
+	return &PathItem{
+		Get:     g.Operation(item.Get),
+		Put:     g.Operation(item.Put),
+		Post:    g.Operation(item.Post),
+		Delete:  g.Operation(item.Delete),
+		Options: g.Operation(item.Options),
+		Head:    g.Operation(item.Head),
+		Patch:   g.Operation(item.Patch),
+	}
+}
+
+// Operation converted from the OpenAPI operation, i.e.
+// the request body is the body parameter, and the media
+// types of the contents are the consumes and the produces
+func (g *generator) Operation(op *openapi.Operation) *Operation {
+	if op == nil {
+		return nil
+	}
+	o := &Operation{
+		Summary:     op.Summary,
+		OperationID: op.OperationID,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Responses:   make(map[string]*Response, len(op.Responses)),
+	}
+
+	// Params
+	for _, p := range op.Parameters {
+		if p.In == "cookie" {
+			if g.verbose {
+				log.Warnf("generator: cookie parameter \"%s\" skipped, not supported by Swagger %s", p.Name, Version)
+			}
+			continue
+		}
+		o.Parameters = append(o.Parameters, g.Parameter(p))
+	}
+
+	// Body
+	if op.RequestBody != nil {
+		o.Consumes = mediaTypes(op.RequestBody.Content)
+		if len(o.Consumes) > 0 {
+			o.Parameters = append(o.Parameters, &Parameter{
+				Name:     "body",
+				In:       "body",
+				Required: true,
+				Schema:   op.RequestBody.Content[o.Consumes[0]].Schema,
+			})
+		}
+	}
+
+	// Responses, a single schema per response
+	for code, resp := range op.Responses {
+		r := &Response{Description: resp.Description}
+		mts := mediaTypes(resp.Content)
+		if len(mts) > 0 {
+			r.Schema = resp.Content[mts[0]].Schema
+		}
+		for _, mt := range mts {
+			if misc.StringInSlice(mt, o.Produces) == false {
+				o.Produces = append(o.Produces, mt)
+			}
+		}
+		o.Responses[code] = r
+	}
+	sort.Strings(o.Produces)
+	return o
+}
+
+// Parameter converted from the OpenAPI parameter, i.e.
+// the type is the property of the parameter
+func (g *generator) Parameter(p *openapi.Parameter) *Parameter {
+	param := &Parameter{
+		Name:        p.Name,
+		In:          p.In,
+		Description: p.Description,
+		Required:    p.Required,
+	}
+	if p.Schema != nil {
+		param.Type, _ = p.Schema.Type.(string)
+		param.Format = p.Schema.Format
+		param.Items = p.Schema.Items
+	}
+	return param
+}
+
+// Schema converted to Swagger 2.0, i.e. the references of the
+// components are the references of the definitions, the nullable
+// is the extension, the annotations unknown to 2.0 are removed
+func (g *generator) Schema(s *openapi.Schema) {
+	if strings.HasPrefix(s.Ref, g.componentsRef) {
+		s.Ref = g.definitionsRef + strings.TrimPrefix(s.Ref, g.componentsRef)
+	}
+	s.XNullable, s.Nullable = s.Nullable, nil
+	s.WriteOnly = nil
+	s.Deprecated = nil
+}
+
+// mediaTypes of the content, sorted by the name
+func mediaTypes(content map[string]*openapi.MediaType) []string {
+	mts := make([]string, 0, len(content))
+	for mt := range content {
+		mts = append(mts, mt)
+	}
+	sort.Strings(mts)
+	return mts
+}
+
+// NewGenerator instance.
+// Formats of the output, YAML is used if not set.
+func NewGenerator(verbose bool, formats ...string) output.Generator {
+	if len(formats) == 0 {
+		formats = []string{output.YAML}
+	}
+	return &generator{
+		verbose:        verbose,
+		formats:        formats,
+		componentsRef:  "#/components/schemas/",
+		definitionsRef: "#/definitions/",
+	}
+}
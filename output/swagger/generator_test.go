@@ -0,0 +1,299 @@
+package swagger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spaceavocado/apidoc/output"
+	"github.com/spaceavocado/apidoc/output/openapi"
+	"github.com/spaceavocado/apidoc/token"
+)
+
+// yamlOf the model, i.e. the expected
+// outcome is described by the YAML lines
+func yamlOf(t *testing.T, v interface{}) string {
+	b, err := output.MarshalYAML(v)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	return string(b)
+}
+
+func TestGenerate(t *testing.T) {
+	g := NewGenerator(false, output.YAML, output.JSON).(*generator)
+
+	file := "tmp.yaml"
+	defer os.Remove(file)
+	defer os.Remove("tmp.json")
+
+	// Invalid output folder
+	err := g.Generate([]token.Token{{}}, [][]token.Token{{{}}}, "generator.go/tmp/tmp.yaml")
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	prop := func(key, dataType string) token.Token {
+		return token.Token{
+			Key: "bref",
+			Meta: map[string]string{
+				"pkg.type": "github.com/pkg.Pet",
+				"key":      key,
+				"type":     dataType,
+				"req":      "true",
+			},
+		}
+	}
+	err = g.Generate(
+		[]token.Token{
+			{Key: "title", Meta: map[string]string{"value": "Pets"}},
+			{Key: "ver", Meta: map[string]string{"value": "1.0"}},
+			{Key: "server", Meta: map[string]string{"url": "https://pets.go/v1"}},
+		},
+		[][]token.Token{
+			{
+				{Key: "id", Meta: map[string]string{"value": "create-pet"}},
+				{Key: "accept", Meta: map[string]string{"value": "json"}},
+				{Key: "produce", Meta: map[string]string{"value": "json"}},
+				{Key: "param", Meta: map[string]string{"key": "id", "in": "path", "type": "{int}", "req": "true"}},
+				{Key: "body", Meta: map[string]string{"value": "github.com/pkg.Pet"}},
+				prop("name", "string"),
+				prop("owner", "github.com/pkg.Pet"),
+				{Key: "success", Meta: map[string]string{"code": "200", "type": "{object}", "ref": "github.com/pkg.Pet", "desc": "OK"}},
+				{Key: "router", Meta: map[string]string{"url": "/pets/{id}", "method": "[put]"}},
+			},
+		},
+		file)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	expected := []string{
+		"swagger: \"2.0\"\n",
+		"info:\n",
+		"  title: Pets\n",
+		"  version: \"1.0\"\n",
+		"host: pets.go\n",
+		"basePath: /v1\n",
+		"schemes:\n",
+		"  - https\n",
+		"paths:\n",
+		"  /pets/{id}:\n",
+		"    put:\n",
+		"      operationId: create-pet\n",
+		"      consumes:\n",
+		"        - application/json\n",
+		"      produces:\n",
+		"        - application/json\n",
+		"      parameters:\n",
+		"        - name: id\n",
+		"          in: path\n",
+		"          required: true\n",
+		"          type: integer\n",
+		"        - name: body\n",
+		"          in: body\n",
+		"          required: true\n",
+		"          schema:\n",
+		"            $ref: '#/definitions/Pet'\n",
+		"      responses:\n",
+		"        \"200\":\n",
+		"          description: OK\n",
+		"          schema:\n",
+		"            $ref: '#/definitions/Pet'\n",
+		"definitions:\n",
+		"  Pet:\n",
+		"    type: object\n",
+		"    required:\n",
+		"      - name\n",
+		"      - owner\n",
+		"    properties:\n",
+		"      name:\n",
+		"        type: string\n",
+		"      owner:\n",
+		"        $ref: '#/definitions/Pet'\n",
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if string(b) != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), string(b))
+	}
+	b, err = ioutil.ReadFile("tmp.json")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if strings.HasPrefix(string(b), "{\n  \"swagger\": \"2.0\",\n") == false {
+		t.Errorf("Expected the JSON document, got \"%s\"", string(b))
+	}
+}
+
+func TestServers(t *testing.T) {
+	b := &bytes.Buffer{}
+	log.SetOutput(b)
+	hook := test.NewGlobal()
+	g := NewGenerator(true).(*generator)
+
+	doc := &Document{}
+	g.Servers(doc, []*openapi.Server{
+		{URL: "http://api.go/v1"},
+		{URL: "https://api.go/v1"},
+		{URL: "https://staging.api.go/v1"},
+		{URL: "https://api.go/v1"},
+		{URL: "%"},
+	})
+	if doc.Host != "api.go" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "api.go", doc.Host)
+	}
+	if doc.BasePath != "/v1" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "/v1", doc.BasePath)
+	}
+	if strings.Join(doc.Schemes, ",") != "http,https" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "http,https", strings.Join(doc.Schemes, ","))
+	}
+	if len(hook.Entries) != 2 {
+		t.Errorf("Expected %d log entries, got %d", 2, len(hook.Entries))
+	}
+
+	// Relative url
+	doc = &Document{}
+	g.Servers(doc, []*openapi.Server{{URL: "/api"}})
+	if doc.Host != "" || doc.BasePath != "/api" || len(doc.Schemes) != 0 {
+		t.Errorf("Expected \"%s\", got \"%s%s\"", "/api", doc.Host, doc.BasePath)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	b := &bytes.Buffer{}
+	log.SetOutput(b)
+	hook := test.NewGlobal()
+	g := NewGenerator(true).(*generator)
+
+	if g.Operation(nil) != nil {
+		t.Errorf("Expected nil operation")
+	}
+
+	op := g.Operation(&openapi.Operation{
+		Summary: "Upload",
+		Parameters: []*openapi.Parameter{
+			{Name: "ids", In: "query", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "integer"}}},
+			{Name: "session", In: "cookie", Schema: &openapi.Schema{Type: "string"}},
+		},
+		RequestBody: &openapi.RequestBody{
+			Content: map[string]*openapi.MediaType{
+				"application/xml":  {Schema: &openapi.Schema{Ref: "#/definitions/File"}},
+				"application/json": {Schema: &openapi.Schema{Ref: "#/definitions/File"}},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: map[string]*openapi.MediaType{
+					"application/xml":  {Schema: &openapi.Schema{Ref: "#/definitions/File"}},
+					"application/json": {Schema: &openapi.Schema{Ref: "#/definitions/File"}},
+				},
+			},
+			"500": {
+				Description: "Error",
+				Content: map[string]*openapi.MediaType{
+					"text/plain": {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"204": {
+				Description: "No Content",
+			},
+		},
+	})
+
+	expected := []string{
+		"summary: Upload\n",
+		"consumes:\n",
+		"  - application/json\n",
+		"  - application/xml\n",
+		"produces:\n",
+		"  - application/json\n",
+		"  - application/xml\n",
+		"  - text/plain\n",
+		"parameters:\n",
+		"  - name: ids\n",
+		"    in: query\n",
+		"    type: array\n",
+		"    items:\n",
+		"      type: integer\n",
+		"  - name: body\n",
+		"    in: body\n",
+		"    required: true\n",
+		"    schema:\n",
+		"      $ref: '#/definitions/File'\n",
+		"responses:\n",
+		"  \"200\":\n",
+		"    description: OK\n",
+		"    schema:\n",
+		"      $ref: '#/definitions/File'\n",
+		"  \"204\":\n",
+		"    description: No Content\n",
+		"  \"500\":\n",
+		"    description: Error\n",
+		"    schema:\n",
+		"      type: string\n",
+	}
+	if res := yamlOf(t, op); res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+	}
+}
+
+func TestPathItem(t *testing.T) {
+	b := &bytes.Buffer{}
+	log.SetOutput(b)
+	hook := test.NewGlobal()
+	g := NewGenerator(true).(*generator)
+
+	item := g.PathItem("/test", &openapi.PathItem{
+		Get:   &openapi.Operation{Summary: "Get"},
+		Trace: &openapi.Operation{Summary: "Trace"},
+	})
+	if item.Get == nil || item.Get.Summary != "Get" {
+		t.Errorf("Expected the get operation")
+	}
+	if len(hook.Entries) != 1 {
+		t.Errorf("Expected %d log entries, got %d", 1, len(hook.Entries))
+	}
+}
+
+func TestSchema(t *testing.T) {
+	g := NewGenerator(false).(*generator)
+	s := &openapi.Schema{
+		Type: "object",
+		Properties: openapi.Properties{
+			{
+				Name: "detail",
+				Schema: &openapi.Schema{
+					AllOf:      []*openapi.Schema{{Ref: "#/components/schemas/Detail"}},
+					Nullable:   true,
+					WriteOnly:  true,
+					Deprecated: true,
+				},
+			},
+		},
+	}
+	openapi.WalkSchemas([]*openapi.Schema{s}, g.Schema)
+
+	expected := []string{
+		"type: object\n",
+		"properties:\n",
+		"  detail:\n",
+		"    allOf:\n",
+		"      - $ref: '#/definitions/Detail'\n",
+		"    x-nullable: true\n",
+	}
+	if res := yamlOf(t, s); res != strings.Join(expected, "") {
+		t.Errorf("Expected \"%s\", got \"%s\"", strings.Join(expected, ""), res)
+	}
+}
@@ -0,0 +1,57 @@
+package swagger
+
+import "github.com/spaceavocado/apidoc/output/openapi"
+
+// Document of the Swagger specification
+type Document struct {
+	Swagger     string                     `json:"swagger"`
+	Info        openapi.Info               `json:"info"`
+	Host        string                     `json:"host,omitempty"`
+	BasePath    string                     `json:"basePath,omitempty"`
+	Schemes     []string                   `json:"schemes,omitempty"`
+	Paths       map[string]*PathItem       `json:"paths"`
+	Definitions map[string]*openapi.Schema `json:"definitions,omitempty"`
+}
+
+// PathItem holds the operations of the path,
+// in the canonical order of the methods
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+// Operation of the endpoint
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Consumes    []string             `json:"consumes,omitempty"`
+	Produces    []string             `json:"produces,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter of the operation, the body parameter is described
+// by the schema, the others by the type, and the items
+type Parameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Schema      *openapi.Schema `json:"schema,omitempty"`
+	Type        string          `json:"type,omitempty"`
+	Format      string          `json:"format,omitempty"`
+	Items       *openapi.Schema `json:"items,omitempty"`
+}
+
+// Response of the operation
+type Response struct {
+	Description string          `json:"description"`
+	Schema      *openapi.Schema `json:"schema,omitempty"`
+}
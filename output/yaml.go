@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalJSON the value without escaping the HTML characters,
+// indented by the given indentation if any
+func MarshalJSON(v interface{}, indent string) ([]byte, error) {
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// MarshalYAML the value, i.e. the value is encoded into JSON
+// first, keeping the order of the fields, and the JSON document,
+// being a valid YAML, is read into the YAML nodes, written
+// in the block style by the YAML encoder
+func MarshalYAML(v interface{}) ([]byte, error) {
+	b, err := MarshalJSON(v, "")
+	if err != nil {
+		return nil, err
+	}
+	n := &yaml.Node{}
+	if err = yaml.Unmarshal(b, n); err != nil {
+		return nil, err
+	}
+	blockStyle(n)
+
+	out := &bytes.Buffer{}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err = enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// blockStyle of the nodes read from the JSON document, i.e.
+// the flow style, and the quoting, is left to the encoder.
+// The merge key is quoted, the encoder writes it plain.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && n.Value == "<<" {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
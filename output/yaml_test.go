@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	tests := map[string]string{
+		`{"a":1,"b":{"c":[true,null,"x"]},"d":{},"e":[]}`: "a: 1\nb:\n  c:\n    - true\n    - null\n    - x\nd: {}\ne: []\n",
+		`[{"a":1,"b":[{"c":2}]},[1,[2]],"x"]`:             "- a: 1\n  b:\n    - c: 2\n- - 1\n  - - 2\n- x\n",
+		`{"z":1,"a":2}`:                                   "z: 1\na: 2\n",
+		`"x"`:                                             "x\n",
+	}
+	for input, expected := range tests {
+		res, err := MarshalYAML(json.RawMessage(input))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if string(res) != expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", expected, string(res))
+		}
+	}
+
+	// Invalid value
+	if _, err := MarshalYAML(func() {}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	res, err := MarshalJSON(map[string]string{"a": "<b>"}, "")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if string(res) != `{"a":"<b>"}` {
+		t.Errorf("Expected \"%s\", got \"%s\"", `{"a":"<b>"}`, string(res))
+	}
+}
+
+func TestMarshalYAMLRoundTrip(t *testing.T) {
+	values := []string{
+		"lorem ipsum", "application/json", "User's Profile", "e.g. home, work", "“quoted”", "-x",
+		"", " lorem", "lorem ", "1.0", "200", "0x1F", "1e3", "true", "No", "null", "~", "2020-01-01",
+		"#/components/schemas", "- lorem", "lorem: ipsum", "lorem #ipsum", "lorem:", "[lorem]", "{lorem}",
+		"*lorem", "&lorem", "!lorem", "|lorem", ">lorem", "%lorem", "@lorem", "`lorem`", "\"lorem\"",
+		"'lorem'", "---", "--- lorem", "...", "? lorem", "<<", "=", ".inf", "-.Inf", ".NaN",
+		"lorem\nipsum", "lorem\n", "lorem\tipsum\\", "lorem\u00a0ipsum", "lorem\x01", "lorem\u2028",
+		"\ufefflorem", "<b>lorem</b>",
+	}
+	for _, v := range values {
+		// The value is also the key
+		doc := map[string][]string{v: {v}}
+		res, err := MarshalYAML(doc)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		read := map[string][]string{}
+		if err := yaml.Unmarshal(res, &read); err != nil {
+			t.Errorf("Unexpected error %v of \"%s\"", err, string(res))
+			continue
+		}
+		if len(read[v]) != 1 || read[v][0] != v {
+			t.Errorf("Expected \"%s\", got \"%v\" of \"%s\"", v, read, string(res))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package reference
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Field annotations, by the lowercase annotation name
+var annotationKeys = map[string]string{
+	"example":    "example",
+	"default":    "default",
+	"deprecated": "deprecated",
+	"readonly":   "readOnly",
+	"writeonly":  "writeOnly",
+	"nullable":   "nullable",
+	"format":     "format",
+	"enum":       "enum",
+}
+
+// Boolean annotations, true if declared without a value
+var annotationFlags = map[string]bool{
+	"deprecated": true,
+	"readOnly":   true,
+	"writeOnly":  true,
+	"nullable":   true,
+}
+
+// commentAnnotations splits the field comment into the description
+// and the annotations, i.e. the lines starting by @, e.g. "@example 1".
+// Unknown annotations are kept in the description.
+func commentAnnotations(text string) (string, map[string]string) {
+	annotations := make(map[string]string, 0)
+	desc := make([]string, 0)
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "@") {
+			chunks := strings.SplitN(l[1:], " ", 2)
+			if key, ok := annotationKeys[strings.ToLower(chunks[0])]; ok {
+				value := ""
+				if len(chunks) == 2 {
+					value = strings.TrimSpace(chunks[1])
+				}
+				annotations[key] = value
+				continue
+			}
+		}
+		desc = append(desc, l)
+	}
+	return strings.Join(strings.Fields(strings.Join(desc, " ")), " "), annotations
+}
+
+// tagAnnotations of the struct tag, e.g. example:"1" readOnly:"true"
+func tagAnnotations(tag string) map[string]string {
+	annotations := make(map[string]string, 0)
+	for _, key := range annotationKeys {
+		if value, ok := reflect.StructTag(tag).Lookup(key); ok {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// annotationAttrs sets the annotations into the field attributes.
+// The example and default values, and the enum values separated by
+// comma, are formatted by the field type, i.e. strings are quoted,
+// numbers, booleans and JSON values are kept as they are.
+func annotationAttrs(annotations map[string]string, t string, attrs map[string]string) {
+	kind := validateKind(t)
+	if t == "bool" || t == "boolean" {
+		kind = "boolean"
+	}
+	for key, value := range annotations {
+		switch {
+		case annotationFlags[key]:
+			if value == "" {
+				value = "true"
+			}
+			if b, err := strconv.ParseBool(value); err == nil {
+				attrs[key] = strconv.FormatBool(b)
+			}
+		case key == "enum":
+			values := strings.Split(value, ",")
+			for i, v := range values {
+				values[i] = strings.TrimSpace(v)
+			}
+			if enum := enumValues(kind, values); enum != "" {
+				attrs[key] = enum
+			}
+		case key == "format":
+			if value != "" {
+				attrs[key] = value
+			}
+		default:
+			attrs[key] = annotationValue(kind, value)
+		}
+	}
+}
+
+// annotationValue formatted by the field kind. Strings
+// are quoted, other valid JSON values are kept as they are
+func annotationValue(kind, value string) string {
+	if kind != "string" && json.Valid([]byte(value)) {
+		return value
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
@@ -0,0 +1,90 @@
+package reference
+
+import (
+	"testing"
+)
+
+func TestCommentAnnotations(t *testing.T) {
+	desc, annotations := commentAnnotations("User's email\nused to sign in\n@example john@doe.com\n@Deprecated\n@unknown x\n")
+	if desc != "User's email used to sign in @unknown x" {
+		t.Errorf("Expected \"%s\", got \"%s\"", "User's email used to sign in @unknown x", desc)
+	}
+	expected := map[string]string{
+		"example":    "john@doe.com",
+		"deprecated": "",
+	}
+	if len(annotations) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, annotations)
+		return
+	}
+	for k, v := range expected {
+		if annotations[k] != v {
+			t.Errorf("Expected \"%s\", got \"%s\"", v, annotations[k])
+		}
+	}
+}
+
+func TestTagAnnotations(t *testing.T) {
+	annotations := tagAnnotations(`json:"name" example:"John" readOnly:"true" enum:"a,b"`)
+	expected := map[string]string{
+		"example":  "John",
+		"readOnly": "true",
+		"enum":     "a,b",
+	}
+	if len(annotations) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, annotations)
+		return
+	}
+	for k, v := range expected {
+		if annotations[k] != v {
+			t.Errorf("Expected \"%s\", got \"%s\"", v, annotations[k])
+		}
+	}
+}
+
+func TestAnnotationAttrs(t *testing.T) {
+	table := []struct {
+		annotations map[string]string
+		t           string
+		expected    map[string]string
+	}{
+		{
+			annotations: map[string]string{"example": "John", "default": "none", "deprecated": ""},
+			t:           "string",
+			expected:    map[string]string{"example": "\"John\"", "default": "\"none\"", "deprecated": "true"},
+		},
+		{
+			annotations: map[string]string{"example": "42", "enum": "1, 2,3", "readOnly": "false"},
+			t:           "int64",
+			expected:    map[string]string{"example": "42", "enum": "[1,2,3]", "readOnly": "false"},
+		},
+		{
+			annotations: map[string]string{"example": "[\"a\",\"b\"]", "nullable": "yes"},
+			t:           "[]string",
+			expected:    map[string]string{"example": "[\"a\",\"b\"]"},
+		},
+		{
+			annotations: map[string]string{"enum": "a,b", "format": "email"},
+			t:           "string",
+			expected:    map[string]string{"enum": "[\"a\",\"b\"]", "format": "email"},
+		},
+		{
+			annotations: map[string]string{"enum": "a,b", "example": "true"},
+			t:           "bool",
+			expected:    map[string]string{"example": "true"},
+		},
+	}
+	for _, test := range table {
+		attrs := make(map[string]string, 0)
+		annotationAttrs(test.annotations, test.t, attrs)
+		if len(attrs) != len(test.expected) {
+			t.Errorf("Expected %v, got %v", test.expected, attrs)
+			continue
+		}
+		for k, v := range test.expected {
+			if attrs[k] != v {
+				t.Errorf("Expected \"%s\", got \"%s\"", v, attrs[k])
+			}
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package reference
 
+import "go/ast"
+
 // resolvedFile cache
 type resolvedFile struct {
 	// File location
@@ -8,12 +10,47 @@ type resolvedFile struct {
 	imports map[string]string
 	// Types resolved within the file
 	types map[string]typeRef
+	// Enum values of the types declared
+	// by the typed constants within the file
+	enums map[string][]string
 }
 
 // typeRef cache
 type typeRef struct {
 	// File containing this type
 	file string
-	// Type struct body content
-	content string
+	// Type struct declaration
+	expr *ast.StructType
+	// Underlying type of a named non-struct
+	// type or an alias, nil for struct types
+	underlying ast.Expr
+	// Type is an alias
+	alias bool
+	// Type parameters of a generic type
+	params []string
+}
+
+// typeArg of a generic type instantiation, resolved
+// lazily within the file, and the type arguments,
+// of the instantiating type
+type typeArg struct {
+	// Type argument expression
+	expr ast.Expr
+	// File containing the instantiation
+	file string
+	// Type arguments of the instantiating generic type
+	env map[string]typeArg
+}
+
+// param resolved from a struct field, i.e. the field
+// entry followed by the inner object field entries
+type param struct {
+	// Name of the field
+	name string
+	// Field line items
+	entries []string
+	// Embedding depth of a promoted field
+	embedded int
+	// Name is given by the tag
+	tagged bool
 }
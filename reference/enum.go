@@ -0,0 +1,152 @@
+package reference
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strconv"
+)
+
+// parseEnums collects the typed constants declared in the const
+// declaration, i.e. enum values grouped by the type name.
+// The values are encoded as JSON literals.
+func parseEnums(gd *ast.GenDecl, consts map[string]constant.Value) map[string][]string {
+	enums := make(map[string][]string, 0)
+
+	// Implicit repetition of the last type and values
+	var typ ast.Expr
+	var values []ast.Expr
+	for index, spec := range gd.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if ok == false {
+			continue
+		}
+		if vs.Type != nil || len(vs.Values) > 0 {
+			typ = vs.Type
+			values = vs.Values
+		}
+
+		for i, n := range vs.Names {
+			if i >= len(values) {
+				break
+			}
+			v := evalConst(values[i], index, consts)
+			if v == nil {
+				continue
+			}
+			consts[n.Name] = v
+			if n.Name == "_" {
+				continue
+			}
+
+			// Type of the constant, explicit
+			// or by the conversion, e.g. Status(1)
+			name := ""
+			if id, ok := typ.(*ast.Ident); ok {
+				name = id.Name
+			} else if call, ok := values[i].(*ast.CallExpr); ok && typ == nil {
+				if id, ok := call.Fun.(*ast.Ident); ok {
+					name = id.Name
+				}
+			}
+			if name == "" {
+				continue
+			}
+			if l := constLiteral(v); l != "" {
+				enums[name] = append(enums[name], l)
+			}
+		}
+	}
+
+	return enums
+}
+
+// evalConst expression. It supports literals, iota, previously
+// declared constants, and unary, binary and conversion expressions.
+// It returns nil if the expression cannot be evaluated.
+func evalConst(expr ast.Expr, index int, consts map[string]constant.Value) constant.Value {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			return nil
+		}
+		return v
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(int64(index))
+		case "true":
+			return constant.MakeBool(true)
+		case "false":
+			return constant.MakeBool(false)
+		}
+		if v, ok := consts[e.Name]; ok {
+			return v
+		}
+	case *ast.ParenExpr:
+		return evalConst(e.X, index, consts)
+	case *ast.CallExpr:
+		// Type conversion
+		if len(e.Args) == 1 {
+			return evalConst(e.Args[0], index, consts)
+		}
+	case *ast.UnaryExpr:
+		x := evalConst(e.X, index, consts)
+		if x == nil {
+			return nil
+		}
+		return constant.UnaryOp(e.Op, x, 0)
+	case *ast.BinaryExpr:
+		x := evalConst(e.X, index, consts)
+		y := evalConst(e.Y, index, consts)
+		if x == nil || y == nil {
+			return nil
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(y)
+			if ok == false {
+				return nil
+			}
+			return constant.Shift(x, e.Op, uint(s))
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y))
+		case token.QUO:
+			// Integer division
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				if constant.Sign(y) == 0 {
+					return nil
+				}
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+		case token.REM:
+			if constant.Sign(y) == 0 {
+				return nil
+			}
+		}
+		return constant.BinaryOp(x, e.Op, y)
+	}
+	return nil
+}
+
+// constLiteral encodes the constant value into the JSON literal
+func constLiteral(v constant.Value) string {
+	switch v.Kind() {
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v))
+	case constant.String:
+		b, err := json.Marshal(constant.StringVal(v))
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case constant.Int:
+		return v.ExactString()
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package reference
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	content :=
+		`
+		package tmp
+
+		const (
+			StatusActive Status = iota
+			StatusInactive
+			_
+			StatusDeleted
+		)
+
+		const (
+			RoleAdmin Role = "admin"
+			RoleUser  Role = "user"
+		)
+
+		const (
+			FlagA = Flag(1 << iota)
+			FlagB
+			FlagC = Flag(10 / 3)
+		)
+
+		const (
+			Untyped = 1
+			Typed   Level = Untyped + 1
+			Ratio   Scale = 0.5
+			Enabled Mode  = true
+		)
+	`
+	f, err := parser.ParseFile(token.NewFileSet(), "", content, 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	consts := make(map[string]constant.Value, 0)
+	enums := make(map[string][]string, 0)
+	for _, d := range f.Decls {
+		for k, v := range parseEnums(d.(*ast.GenDecl), consts) {
+			enums[k] = append(enums[k], v...)
+		}
+	}
+
+	expected := map[string][]string{
+		"Status": {"0", "1", "3"},
+		"Role":   {"\"admin\"", "\"user\""},
+		"Flag":   {"1", "2", "3"},
+		"Level":  {"2"},
+		"Scale":  {"0.5"},
+		"Mode":   {"true"},
+	}
+	if len(enums) != len(expected) {
+		t.Errorf("Expected %d enums, got %d: %v", len(expected), len(enums), enums)
+		return
+	}
+	for k, values := range expected {
+		if len(enums[k]) != len(values) {
+			t.Errorf("Expected %v for \"%s\", got %v", values, k, enums[k])
+			continue
+		}
+		for i, v := range values {
+			if enums[k][i] != v {
+				t.Errorf("Expected \"%s\", got \"%s\"", v, enums[k][i])
+			}
+		}
+	}
+}
+
+func TestEvalConst(t *testing.T) {
+	tests := map[string]string{
+		"-1":            "-1",
+		"(2 + 3) * 4":   "20",
+		"7 % 4":         "3",
+		"1 == 1":        "true",
+		"\"a\" + \"b\"": "\"ab\"",
+		"x":             "",
+		"1 / 0":         "",
+	}
+	for expr, expected := range tests {
+		e, err := parser.ParseExpr(expr)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		res := ""
+		if v := evalConst(e, 0, map[string]constant.Value{}); v != nil {
+			res = constLiteral(v)
+		}
+		if res != expected {
+			t.Errorf("Expected \"%s\" for \"%s\", got \"%s\"", expected, expr, res)
+		}
+	}
+}
@@ -5,14 +5,24 @@ package reference
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spaceavocado/apidoc/extract"
+	"github.com/spaceavocado/apidoc/loader"
+	"github.com/spaceavocado/apidoc/misc"
 )
 
 // Resolver of references
@@ -32,40 +42,78 @@ const (
 	typeWrap
 )
 
-// mappingType pair
+// mappingType of the expected prefix
 type mappingType struct {
 	prefix string
 	t      refType
+	// Prefix of the referenced components
+	comp string
+}
+
+// knownType mapped directly into the
+// documentation type and format
+type knownType struct {
+	t      string
+	format string
 }
 
 type resolver struct {
 	verbose bool
-	gopath  string
+	// Pointer fields are nullable
+	nullable bool
+	gopath   string
+	// Go modules cache location
+	modcache string
+	// Resolved go.mod modules by folder,
+	// nil for folders outside of any module
+	modules map[string]*loader.Module
 	// Builtin go primitive types
 	builtinTypes []string
+	// Known types, e.g. time.Time, by the full
+	// package path and the type name
+	knownTypes map[string]knownType
 	// Resolved packages
 	// with all resolved files
 	packages map[string]map[string]resolvedFile
-	// Resolved types
+	// Resolved types, the types are resolved serially
+	// to keep the component naming deterministic
 	types map[string][]string
+	// Types being resolved, i.e. referenced
+	// recursively by its own fields
+	resolving map[string]bool
+	// Type arguments of the generic type being
+	// resolved, by the type parameter names
+	args map[string]typeArg
+	// Resolved generic type instantiations,
+	// i.e. the instantiation by the component name
+	instances map[string]string
 	// Collection of expected prefixes with
 	// mapping to produces reference prefixes
 	prefixMapping map[string]mappingType
 	// Struct meta fields mapping
-	metaMapping    map[string]string
-	structRx       *regexp.Regexp
-	importSingleRx *regexp.Regexp
-	importMultiRx  *regexp.Regexp
-	fieldRx        *regexp.Regexp
-	metaRx         *regexp.Regexp
-	respRx         *regexp.Regexp
-	boolRx         *regexp.Regexp
-	typeCleanRx    *regexp.Regexp
+	metaMapping map[string]string
+	// Struct tags with the validation rules
+	validateTags []string
+	// Loader of the parsed files, shared with the extractor
+	loader loader.Loader
+	// Workers of the packages prefetching
+	workers int
+	// Guards the packages, and the modules, which
+	// are cached concurrently by the prefetching
+	mu          sync.RWMutex
+	metaRx      *regexp.Regexp
+	respRx      *regexp.Regexp
+	compRx      *regexp.Regexp
+	boolRx      *regexp.Regexp
+	typeCleanRx *regexp.Regexp
 }
 
 // Resolve endpoints references.
-// The resolved references are injected into the lines
+// The resolved references are injected into the lines.
+// The packages are prefetched in parallel, the references
+// are resolved serially, in the order of the endpoints.
 func (r *resolver) Resolve(endpoints []extract.Block) error {
+	r.Prefetch(endpoints)
 	for i, b := range endpoints {
 		resolved := make([]string, 0)
 		for _, l := range b.Lines {
@@ -73,18 +121,7 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 
 			// Body/Response reference
 			if mapping.t == typeBody || mapping.t == typeResp {
-				ref := ""
-				// Body reference
-				if mapping.t == typeBody {
-					if chunks := strings.Split(l, " "); len(chunks) > 1 {
-						ref = chunks[1]
-					}
-					// Response reference
-				} else {
-					if c := r.respRx.FindStringSubmatch(l); len(c) == 2 {
-						ref = c[1]
-					}
-				}
+				ref := r.LineRef(l, mapping)
 
 				// Model detected
 				// Resolve the reference
@@ -99,8 +136,11 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 					if len(entries) > 0 {
 						newModel := strings.Split(entries[0], " ")[0]
 						l = strings.Replace(l, ref, newModel, 1)
+						comps := r.Components(entries)
 						r.AddPrefix(fmt.Sprintf("%s ", mapping.prefix), entries)
+						r.AddPrefix(fmt.Sprintf("%s ", mapping.comp), comps)
 						resolved = append(resolved, entries...)
+						resolved = append(resolved, comps...)
 						resolved = append(resolved, l)
 					}
 					// No valid reference detected
@@ -110,7 +150,13 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 
 				// Wrapper reference
 			} else if mapping.t == typeWrap {
-				tokens := strings.Split(l, " ")
+				// The reference might be a generic type
+				// instantiation containing spaces
+				tokens := strings.SplitN(l, " ", 2)
+				if len(tokens) == 2 {
+					ref := r.RefExpr(tokens[1])
+					tokens = append([]string{tokens[0], ref}, strings.Fields(tokens[1][len(ref):])...)
+				}
 
 				// Must have all 3 mandatory sections
 				// Expected format: @token reference field-pointer
@@ -126,10 +172,10 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 						chunks := strings.Split(e, " ")
 						// Mark the pointer prop inside the wrapper entry
 						// tokens[2] is the pointer field
-						entries[i] = r.boolRx.ReplaceAllStringFunc(e, func(m string) string {
+						if loc := r.boolRx.FindStringIndex(e); loc != nil {
 							resolved = true
-							return fmt.Sprintf("%s %v", m, tokens[2] == chunks[1])
-						})
+							entries[i] = fmt.Sprintf("%s %v%s", e[:loc[1]], tokens[2] == chunks[1], e[loc[1]:])
+						}
 						// It the entry is not resolved as an object local prop,
 						// there is a chance that the pointer is the root prop object is self.
 						// If so, mark it as the pointer
@@ -140,10 +186,12 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 
 					if len(entries) > 0 {
 						ref := strings.Split(entries[0], " ")[0]
-						chunks := strings.Split(l, " ")
+						comps := r.Components(entries)
 						r.AddPrefix(fmt.Sprintf("%s ", mapping.prefix), entries)
+						r.AddPrefix(fmt.Sprintf("%s ", mapping.comp), comps)
 						resolved = append(resolved, entries...)
-						resolved = append(resolved, fmt.Sprintf("%s %s %s", chunks[0], ref, chunks[2]))
+						resolved = append(resolved, comps...)
+						resolved = append(resolved, fmt.Sprintf("%s %s %s", tokens[0], ref, tokens[2]))
 					}
 				}
 
@@ -159,6 +207,97 @@ func (r *resolver) Resolve(endpoints []extract.Block) error {
 	return nil
 }
 
+// LineRef of the body, the response, or the wrapper line,
+// empty if the line does not contain any reference
+func (r *resolver) LineRef(line string, mapping mappingType) string {
+	if mapping.t == typeResp {
+		if c := r.respRx.FindStringSubmatch(line); len(c) == 2 {
+			return r.RefExpr(c[1])
+		}
+		return ""
+	}
+	if chunks := strings.SplitN(line, " ", 2); len(chunks) > 1 {
+		return r.RefExpr(chunks[1])
+	}
+	return ""
+}
+
+// Prefetch the packages of the endpoint files, and the packages
+// imported by the references of the endpoints, on the pool
+// of workers. The failures are ignored, i.e. the failed
+// packages are not stored, they are reported by the resolving.
+func (r *resolver) Prefetch(endpoints []extract.Block) {
+	files := make([]string, 0)
+	prefixes := make(map[string]map[string]bool, 0)
+	for _, b := range endpoints {
+		if _, ok := prefixes[b.File]; ok == false {
+			files = append(files, b.File)
+			prefixes[b.File] = make(map[string]bool, 0)
+		}
+		for _, l := range b.Lines {
+			mapping := r.HasExpectedPrefix(l)
+			if mapping.t == 0 {
+				continue
+			}
+			for _, p := range r.RefPrefixes(r.LineRef(l, mapping)) {
+				prefixes[b.File][p] = true
+			}
+		}
+	}
+
+	misc.Parallel(len(files), r.workers, func(i int) error {
+		file := files[i]
+		pkg := r.PkgName(file)
+		if err := r.ParsePackage(pkg, pkg); err != nil {
+			return nil
+		}
+		fc, _ := r.ResolvedFile(r.NormalizePkgName(pkg), file)
+		for p := range prefixes[file] {
+			if path, ok := fc.imports[p]; ok {
+				dir := r.PkgDir(path, file)
+				r.ParsePackage(dir, dir)
+			}
+		}
+		return nil
+	})
+}
+
+// RefPrefixes of the packages used by the reference,
+// including the type arguments, e.g. "request" and
+// "model" of request.Page[model.Person]
+func (r *resolver) RefPrefixes(ref string) []string {
+	prefixes := make([]string, 0)
+	expr, err := parser.ParseExpr(ref)
+	if err != nil {
+		return prefixes
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				prefixes = append(prefixes, x.Name)
+			}
+		}
+		return true
+	})
+	return prefixes
+}
+
+// Package of the resolved files, by the normalized name
+func (r *resolver) Package(name string) (map[string]resolvedFile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.packages[name]
+	return p, ok
+}
+
+// ResolvedFile of the package
+func (r *resolver) ResolvedFile(pkg, file string) (resolvedFile, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fc, ok := r.packages[pkg][file]
+	return fc, ok
+}
+
 // HasExpectedPrefix returns the detected
 // expected prefix it the form of its output mapping
 func (r *resolver) HasExpectedPrefix(line string) mappingType {
@@ -170,6 +309,26 @@ func (r *resolver) HasExpectedPrefix(line string) mappingType {
 	return mappingType{}
 }
 
+// RefExpr returns the leading type expression of the text,
+// i.e. the text up to the first space outside of the square
+// brackets, e.g. "Pair[string, int] desc" -> "Pair[string, int]"
+func (r *resolver) RefExpr(text string) string {
+	depth := 0
+	for i, c := range text {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ' ', '\t':
+			if depth <= 0 {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
+
 // AddPrefix into entries
 func (r *resolver) AddPrefix(prefix string, items []string) {
 	for i := 0; i < len(items); i++ {
@@ -205,132 +364,257 @@ func (r *resolver) PkgLoc(prefix string, fc resolvedFile) (string, error) {
 	return "", errors.New("not found")
 }
 
+// PkgDir resolves the location of the imported package.
+// Packages are resolved through the go.mod of the module
+// containing the file, with the GOPATH as fallback.
+func (r *resolver) PkgDir(pkg, file string) string {
+	r.mu.Lock()
+	m, err := loader.FindModule(filepath.Dir(file), r.modules)
+	r.mu.Unlock()
+	if err == nil && m != nil {
+		if dir, ok := m.PkgDir(pkg, r.modcache); ok {
+			return dir
+		}
+	}
+	return filepath.Join(r.gopath, filepath.FromSlash(pkg))
+}
+
 // ResolveReference recursively from the local files
 // and from the imported packages. It returns the resolved
 // documentation lines describing the references type.
 func (r *resolver) ResolveReference(ref, file string, depth int) ([]string, error) {
+	// Generic type instantiation, resolved as the component
+	if r.IsInstance(ref) {
+		comp, err := r.Component(ref, file)
+		if err != nil {
+			return []string{}, err
+		}
+		return r.CachedParams(comp, depth), nil
+	}
+	pkg, ref, err := r.ReferencePackage(ref, file)
+	if err != nil {
+		return []string{}, err
+	}
+	return r.ReferenceDetails(file, pkg, ref, depth)
+}
+
+// ReferencePackage parses the package of the reference,
+// local or imported one. It returns the package name
+// and the reference cleared from the package prefix.
+func (r *resolver) ReferencePackage(ref, file string) (string, string, error) {
 	pkg := r.PkgName(file)
 
 	// Parse package
 	err := r.ParsePackage(pkg, pkg)
 	if err != nil {
-		return []string{}, err
+		return "", "", err
 	}
 
-	var prefix string
-
 	// External reference, i.e imported from an other package
 	if strings.Contains(ref, ".") == true {
 		chunks := strings.Split(ref, ".")
-		prefix = chunks[0]
+		prefix := chunks[0]
 		ref = chunks[1]
 
 		pkg = r.NormalizePkgName(pkg)
 
 		// Resolve package
-		external, err := r.PkgLoc(prefix, r.packages[pkg][file])
+		fc, _ := r.ResolvedFile(pkg, file)
+		external, err := r.PkgLoc(prefix, fc)
 		if err != nil {
-			return []string{}, err
+			return "", "", err
 		}
 
-		// Parse package
-		err = r.ParsePackage(filepath.Join(r.gopath, external), external)
+		// Parse package, named by the folder the same way
+		// as the local references within the package are
+		dir := r.PkgDir(external, file)
+		err = r.ParsePackage(dir, dir)
 		if err != nil {
-			return []string{}, err
+			return "", "", err
 		}
 
-		prefix = external
-
-		// Local reference
-	} else {
-		prefix = pkg
+		return r.NormalizePkgName(dir), ref, nil
 	}
 
-	return r.ReferenceDetails(file, prefix, ref, depth)
+	// Local reference
+	return pkg, ref, nil
 }
 
 // ReferenceDetails resolved from the cached type struct content
 func (r *resolver) ReferenceDetails(file, pkg, ref string, depth int) ([]string, error) {
 	pkg = r.NormalizePkgName(pkg)
-	p, ok := r.packages[pkg]
+	tr, fc, err := r.FindType(file, pkg, ref)
+	if err != nil {
+		return nil, err
+	}
+	// Named type, defined by an other type
+	if tr.underlying != nil {
+		return r.ResolveReference(r.TypeName(tr.underlying), tr.file, depth)
+	}
+	return r.TypeToParams(tr.file, fmt.Sprintf("%s.%s", pkg, ref), tr.expr, fc.imports, depth), nil
+}
+
+// FindType by the reference in the cached package. It returns
+// the type and the file containing the type.
+func (r *resolver) FindType(file, pkg, ref string) (typeRef, resolvedFile, error) {
+	p, ok := r.Package(pkg)
 	if ok == false {
 		if r.verbose {
 			log.Warnf("reference resolving: unknown package \"%s\" in the file \"%s\"", pkg, file)
 		}
-		return nil, fmt.Errorf("unknown package \"%s\"", pkg)
+		return typeRef{}, resolvedFile{}, fmt.Errorf("unknown package \"%s\"", pkg)
 	}
 
 	// Try to find the type matching the reference
 	for _, fc := range p {
 		for t, tr := range fc.types {
 			if t == ref {
-				return r.TypeToParams(tr.file, fmt.Sprintf("%s.%s", pkg, ref), tr.content, fc.imports, depth), nil
+				return tr, fc, nil
 			}
 		}
 	}
 	if r.verbose {
 		log.Warnf("reference resolving: unknown type \"%s\" in the file \"%s\"", ref, file)
 	}
-	return nil, fmt.Errorf("unknown ref \"%s\"", ref)
+	return typeRef{}, resolvedFile{}, fmt.Errorf("unknown ref \"%s\"", ref)
 }
 
 // TypeToParams deconstructs the struct type into field line items
 // If the type has been already resolved it retruns the cached result
-func (r *resolver) TypeToParams(file, pkgname, content string, imports map[string]string, depth int) []string {
-	if t, ok := r.types[pkgname]; ok {
-		clone := make([]string, len(t))
-		prefix := ""
-		if strings.HasPrefix(t[0], pkgname) == false && depth == 0 {
-			prefix = fmt.Sprintf("%s ", pkgname)
-		}
-		for i, l := range t {
-			clone[i] = fmt.Sprintf("%s%s", prefix, l)
-		}
-		return clone
+func (r *resolver) TypeToParams(file, pkgname string, expr *ast.StructType, imports map[string]string, depth int) []string {
+	// The cached entries are stored without the
+	// package prefix, i.e. resolved as an inner object
+	if _, ok := r.types[pkgname]; ok == false {
+		r.types[pkgname] = make([]string, 0)
+		r.resolving[pkgname] = true
+		r.types[pkgname] = r.StructToParams(file, pkgname, expr, 1)
+		delete(r.resolving, pkgname)
 	}
-	r.types[pkgname] = make([]string, 0)
+	return r.CachedParams(pkgname, depth)
+}
 
-	lines := strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n")
+// CachedParams of the resolved type, prefixed
+// by the package.type name on the root depth
+func (r *resolver) CachedParams(pkgname string, depth int) []string {
+	t := r.types[pkgname]
+	clone := make([]string, len(t))
+	prefix := ""
+	if depth == 0 {
+		prefix = fmt.Sprintf("%s ", pkgname)
+	}
+	for i, l := range t {
+		clone[i] = fmt.Sprintf("%s%s", prefix, l)
+	}
+	return clone
+}
+
+// StructToParams deconstructs the struct fields into field line items.
+// Inline struct fields are resolved recursively as inner objects,
+// embedded struct fields are promoted into the struct.
+func (r *resolver) StructToParams(file, pkgname string, expr *ast.StructType, depth int) []string {
 	entries := make([]string, 0)
-	var desc string
+	for _, p := range r.DominantParams(r.StructFields(file, pkgname, expr, depth, 0, []string{})) {
+		entries = append(entries, p.entries...)
+	}
+	return entries
+}
+
+// StructFields resolves the struct fields into params.
+// Embedded is the embedding depth of the promoted fields,
+// path is the chain of embedded types to prevent cycling.
+func (r *resolver) StructFields(file, pkgname string, expr *ast.StructType, depth, embedded int, path []string) []param {
+	params := make([]param, 0)
+	if expr == nil || expr.Fields == nil {
+		return params
+	}
+
+	// Process all fields, a field might
+	// declare more names of the same type
+	for _, f := range expr.Fields.List {
+		desc := ""
+		c, annotations := r.FieldComment(f)
+		if c != "" {
+			desc = fmt.Sprintf("\"%s\"", c)
+		}
+		meta := make(map[string]string, 0)
+		tag := ""
+		if f.Tag != nil {
+			if unquoted, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = unquoted
+				meta = r.ParseFieldMeta(tag)
+				for k, v := range tagAnnotations(tag) {
+					annotations[k] = v
+				}
+			}
+		}
 
-	// Process all entries, i.e. all lines within
-	// the struct body
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
+		// Name tag options, e.g. json:"name,omitempty"
+		nameTag, _ := reflect.StructTag(tag).Lookup(r.metaMapping["name"])
+		opts := r.FieldOptions(nameTag)
 
-		if l != "" {
-			name := ""
-			t := ""
+		// Unexported fields are skipped
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			if n.IsExported() {
+				names = append(names, n.Name)
+			}
+		}
+
+		// Embedded field, without a name given by the tag, or
+		// an inline field, its fields are promoted into this struct
+		if len(f.Names) == 0 || opts["inline"] {
+			t := r.TypeName(f.Type)
+			if m := meta[r.metaMapping["name"]]; m == "" || opts["inline"] {
+				if promoted, ok := r.EmbeddedFields(t, file, pkgname, depth, embedded, path); ok {
+					params = append(params, promoted...)
+					continue
+				}
+			}
+		}
+		if len(f.Names) == 0 {
+			t := r.TypeName(f.Type)
+			if i := strings.Index(t, "["); i > 0 {
+				t = t[:i]
+			}
+			chunks := strings.Split(t, ".")
+			if name := chunks[len(chunks)-1]; ast.IsExported(name) {
+				names = append(names, name)
+			}
+		}
+
+		for _, name := range names {
 			req := "false"
-			meta := make(map[string]string, 0)
-			c := r.fieldRx.FindStringSubmatch(strings.TrimSpace(l))
+			tagged := false
+			attrs := make(map[string]string, 0)
 
-			// Desc, i.e. comment above
-			if c[0] != "" && c[1] != "" {
-				desc = fmt.Sprintf("\"%s\"", c[1])
-				continue
+			// Named type, resolved to the underlying type
+			t, tfile, found := r.FieldType(f.Type, file, attrs)
+
+			// Validation rules, e.g. validate:"required,min=1"
+			for _, key := range r.validateTags {
+				if rules, ok := reflect.StructTag(tag).Lookup(key); ok && validateAttrs(rules, t, attrs) {
+					req = "true"
+				}
+			}
+
+			// Numbers and booleans encoded as strings
+			if opts["string"] && (t == "bool" || validateKind(t) == "number") {
+				t = "string"
+				stringAttrs(attrs)
 			}
 
-			// Case 1: name type `meta`
-			if c[0] != "" && c[2] != "" && c[3] != "" && c[4] != "" {
-				name = c[2]
-				t = c[3]
-				meta = r.ParseFieldMeta(c[4])
-				// Case 2: name type
-			} else if c[0] != "" && c[5] != "" && c[6] != "" {
-				name = c[5]
-				t = c[6]
+			// Pointer field, i.e. the field might be null
+			if _, ok := f.Type.(*ast.StarExpr); ok && r.nullable {
+				attrs["nullable"] = "true"
 			}
-			// TODO: Resolve embedded object
 
-			// Remove pointer, decorators
-			t = strings.Replace(t, "*", "", -1)
-			t = strings.Replace(t, "&", "", -1)
+			// Annotations, e.g. example:"1" or @example 1
+			annotationAttrs(annotations, t, attrs)
 
 			// Meta overrides
-			if m, ok := meta[r.metaMapping["name"]]; ok {
+			if m := meta[r.metaMapping["name"]]; m != "" {
 				name = m
+				tagged = true
 			}
 			if m, ok := meta[r.metaMapping["type"]]; ok {
 				t = m
@@ -338,47 +622,640 @@ func (r *resolver) TypeToParams(file, pkgname, content string, imports map[strin
 			if m, ok := meta[r.metaMapping["req"]]; ok {
 				req = m
 			}
+			// Omitted empty field is never required
+			if opts["omitempty"] {
+				req = "false"
+			}
 
 			// Continue only of the name is valid.
-			// I.e not empty, not marked as skipped in json
-			if name != "-" && name != "" {
-				// Base type
-				if r.IsBasicType(t) {
-					if depth == 0 {
-						entries = append(entries, fmt.Sprintf("%s %s {%s} %s %s", pkgname, name, t, req, desc))
-					} else {
-						entries = append(entries, fmt.Sprintf("%s {%s} %s %s", name, t, req, desc))
+			// I.e not empty, not marked as skipped in json,
+			// the "-," tag names the field by the dash
+			if name == "" || (name == "-" && strings.HasPrefix(nameTag, "-,") == false) {
+				continue
+			}
+
+			p := param{
+				name:     name,
+				embedded: embedded,
+				tagged:   tagged,
+			}
+
+			// Base type
+			if r.IsBasicType(t) || r.IsMapType(t) {
+				entry := fmt.Sprintf("%s {%s} %s %s%s", name, t, req, r.FieldAttrs(attrs), desc)
+				if depth == 0 {
+					entry = fmt.Sprintf("%s %s", pkgname, entry)
+				}
+				p.entries = []string{entry}
+				params = append(params, p)
+				continue
+			}
+			if found == false {
+				continue
+			}
+
+			prefix := ""
+			if strings.HasPrefix(t, "[]") {
+				t = strings.TrimPrefix(t, "[]")
+				prefix = "[]"
+			}
+
+			// Struct reference, resolved as a component
+			st, ok := r.ElemType(f.Type).(*ast.StructType)
+			if ok == false {
+				comp := t
+				if r.IsComponent(t) == false {
+					var err error
+					if comp, err = r.Component(t, tfile); err != nil {
+						continue
 					}
+				}
+				// Empty struct, the free-form object
+				if len(r.types[comp]) == 0 && r.resolving[comp] == false {
+					comp = "object"
+				}
+				entry := fmt.Sprintf("%s {%s%s} %s %s%s", name, prefix, comp, req, r.FieldAttrs(attrs), desc)
+				if depth == 0 {
+					entry = fmt.Sprintf("%s %s", pkgname, entry)
+				}
+				p.entries = []string{entry}
+				params = append(params, p)
+				continue
+			}
 
-					// Recursive reference
+			// Inline struct, resolved as an inner object
+			rootType := prefix + "object"
+			childEntries := r.StructToParams(file, pkgname, st, depth+1)
+			if len(childEntries) > 0 {
+				if depth == 0 {
+					p.entries = append(p.entries, fmt.Sprintf("%s %s {%s}", pkgname, name, rootType))
+					r.AddPrefix(fmt.Sprintf("%s %s.", pkgname, name), childEntries)
 				} else {
-					rootType := "object"
-					if strings.HasPrefix(t, "[]") {
-						t = strings.TrimPrefix(t, "[]")
-						rootType = "[]object"
-					}
+					p.entries = append(p.entries, fmt.Sprintf("%s {%s}", name, rootType))
+					r.AddPrefix(fmt.Sprintf("%s.", name), childEntries)
+				}
+				p.entries = append(p.entries, childEntries...)
+				params = append(params, p)
+			}
+		}
+	}
 
-					if childEntries, _ := r.ResolveReference(t, file, depth+1); len(childEntries) > 0 {
-						if depth == 0 {
-							entries = append(entries, fmt.Sprintf("%s %s {%s}", pkgname, name, rootType))
-							r.AddPrefix(fmt.Sprintf("%s %s.", pkgname, name), childEntries)
-							entries = append(entries, childEntries...)
-						} else {
-							entries = append(entries, fmt.Sprintf("%s {%s}", name, rootType))
-							r.AddPrefix(fmt.Sprintf("%s.", name), childEntries)
-							entries = append(entries, childEntries...)
-						}
-					}
+	return params
+}
+
+// FieldType resolves the type of the field type expression into
+// a basic type, known type or the underlying type of the named type.
+// It returns the type, the file declaring it and false if the type
+// is unknown. The format and enum attributes are set by the type.
+func (r *resolver) FieldType(expr ast.Expr, file string, attrs map[string]string) (string, string, bool) {
+	t := r.TypeName(expr)
+
+	// Type parameter, resolved by the type argument
+	if id, ok := r.ElemType(expr).(*ast.Ident); ok {
+		if arg, ok := r.args[id.Name]; ok {
+			elem := r.ResolveTypeArg(arg, attrs)
+			if strings.HasPrefix(t, "[]") && strings.HasPrefix(elem, "[]") == false {
+				elem = "[]" + elem
+			}
+			return elem, file, true
+		}
+	}
+
+	if kt, ok := r.KnownType(t, file); ok {
+		if kt.format != "" {
+			attrs["format"] = kt.format
+		}
+		return kt.t, file, true
+	}
+	if _, ok := r.ElemType(expr).(*ast.StructType); ok {
+		return t, file, true
+	}
+
+	isArray := strings.HasPrefix(t, "[]")
+	elem := strings.TrimPrefix(t, "[]")
+	tfile := file
+	found := true
+	if r.IsBasicType(elem) == false {
+		var enum []string
+		elem, tfile, enum, found = r.Underlying(elem, file)
+		if len(enum) > 0 {
+			attrs["enum"] = fmt.Sprintf("[%s]", strings.Join(enum, ","))
+		}
+		if kt, ok := r.KnownType(elem, tfile); ok {
+			elem = kt.t
+			if kt.format != "" {
+				attrs["format"] = kt.format
+			}
+		}
+	}
+	if isArray && strings.HasPrefix(elem, "[]") == false {
+		elem = "[]" + elem
+	}
+
+	// Map, the value type is described by the additional properties
+	if r.IsMapType(elem) {
+		elem = r.MapType(elem, tfile, attrs)
+	}
+	return elem, tfile, found
+}
+
+// ResolveTypeArg of the generic type instantiation, within the
+// instantiating file and type arguments. It returns a basic type,
+// a map type, the free-form object or the resolved component name.
+func (r *resolver) ResolveTypeArg(arg typeArg, attrs map[string]string) string {
+	args := r.args
+	r.args = arg.env
+	defer func() { r.args = args }()
+
+	t, tfile, found := r.FieldType(arg.expr, arg.file, attrs)
+	if found == false {
+		return "object"
+	}
+	prefix := ""
+	if strings.HasPrefix(t, "[]") {
+		prefix = "[]"
+		t = strings.TrimPrefix(t, "[]")
+	}
+	if r.IsBasicType(t) || r.IsMapType(t) || r.IsComponent(t) {
+		return prefix + t
+	}
+	// Inline struct
+	if _, ok := r.ElemType(arg.expr).(*ast.StructType); ok {
+		return prefix + "object"
+	}
+	comp, err := r.Component(t, tfile)
+	if err != nil {
+		return prefix + "object"
+	}
+	return prefix + comp
+}
+
+// TypeArgName of the resolved type argument, used to name the
+// generic type instantiation, e.g. []pkg.Person -> PersonList
+func (r *resolver) TypeArgName(t string) string {
+	if strings.HasPrefix(t, "[]") {
+		return r.TypeArgName(strings.TrimPrefix(t, "[]")) + "List"
+	}
+	if r.IsMapType(t) {
+		return "Map" + r.TypeArgName(t[strings.Index(t, "]")+1:])
+	}
+	t = t[strings.LastIndex(t, ".")+1:]
+	if t == "" {
+		return t
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// MapType normalized into "map[string]value" form, where the value
+// is a basic type, a resolved struct component or the free-form object.
+// Arrays of maps are prefixed by "[]".
+func (r *resolver) MapType(t, file string, attrs map[string]string) string {
+	prefix := ""
+	if strings.HasPrefix(t, "[]") {
+		prefix = "[]"
+		t = strings.TrimPrefix(t, "[]")
+	}
+	value := "object"
+	if expr, err := parser.ParseExpr(t); err == nil {
+		if mt, ok := expr.(*ast.MapType); ok {
+			value = r.MapValue(mt.Value, file, attrs)
+		}
+	}
+	return fmt.Sprintf("%smap[string]%s", prefix, value)
+}
+
+// MapValue resolves the map value type. Struct types are resolved
+// as components referenced by the package.type name. Unknown types,
+// inline structs and nested maps are treated as the free-form object.
+func (r *resolver) MapValue(expr ast.Expr, file string, attrs map[string]string) string {
+	if _, ok := r.ElemType(expr).(*ast.StructType); ok {
+		return "object"
+	}
+	t, tfile, found := r.FieldType(expr, file, attrs)
+	if found == false {
+		return "object"
+	}
+	prefix := ""
+	if strings.HasPrefix(t, "[]") {
+		prefix = "[]"
+		t = strings.TrimPrefix(t, "[]")
+	}
+	if r.IsBasicType(t) {
+		return prefix + t
+	}
+	if r.IsMapType(t) {
+		return prefix + "object"
+	}
+
+	if r.IsComponent(t) {
+		return prefix + t
+	}
+
+	// Struct component
+	comp, err := r.Component(t, tfile)
+	if err != nil {
+		return prefix + "object"
+	}
+	return prefix + comp
+}
+
+// Component resolves the referenced struct type into the types cache.
+// It returns the package.type name of the struct, i.e. the component
+// name. Types being resolved are referenced by the name as well,
+// therefore recursive types are supported.
+func (r *resolver) Component(ref, file string) (string, error) {
+	// Generic type instantiation
+	if r.IsInstance(ref) {
+		tr, fc, args, name, err := r.Instance(ref, file)
+		if err != nil {
+			return "", err
+		}
+		// The following is soft-locked on 10 nested instantiations,
+		// i.e. generic types expanding its own type arguments
+		nested := 0
+		for n := range r.resolving {
+			if _, ok := r.instances[n]; ok {
+				nested++
+			}
+		}
+		if nested >= 10 && r.IsComponent(name) == false {
+			return "", fmt.Errorf("too deeply nested generic type \"%s\"", ref)
+		}
+		r.ResolveType(tr.file, name, tr.expr, fc.imports, args)
+		return name, nil
+	}
+
+	pkg, ref, err := r.ReferencePackage(ref, file)
+	if err != nil {
+		return "", err
+	}
+	pkg = r.NormalizePkgName(pkg)
+	tr, fc, err := r.FindType(file, pkg, ref)
+	if err != nil {
+		return "", err
+	}
+	// Named type, defined by an other type
+	if tr.underlying != nil {
+		return r.Component(r.TypeName(tr.underlying), tr.file)
+	}
+	name := fmt.Sprintf("%s.%s", pkg, ref)
+	r.ResolveType(tr.file, name, tr.expr, fc.imports, nil)
+	return name, nil
+}
+
+// ResolveType into the types cache, with the given type arguments
+// of the generic type, nil for the non generic types
+func (r *resolver) ResolveType(file, name string, expr *ast.StructType, imports map[string]string, args map[string]typeArg) {
+	prev := r.args
+	r.args = args
+	r.TypeToParams(file, name, expr, imports, 1)
+	r.args = prev
+}
+
+// Instance resolves the generic type instantiation, e.g. Page[Person].
+// It returns the generic type, the file containing it, the type arguments
+// by the type parameter names and the component name of the instantiation,
+// composed of the type name and the type argument names, e.g. PagePerson.
+func (r *resolver) Instance(ref, file string) (typeRef, resolvedFile, map[string]typeArg, string, error) {
+	expr, err := parser.ParseExpr(ref)
+	if err != nil {
+		return typeRef{}, resolvedFile{}, nil, "", err
+	}
+	var base ast.Expr
+	var indices []ast.Expr
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		base, indices = e.X, []ast.Expr{e.Index}
+	case *ast.IndexListExpr:
+		base, indices = e.X, e.Indices
+	default:
+		return typeRef{}, resolvedFile{}, nil, "", fmt.Errorf("invalid generic type \"%s\"", ref)
+	}
+
+	pkg, name, err := r.ReferencePackage(types.ExprString(base), file)
+	if err != nil {
+		return typeRef{}, resolvedFile{}, nil, "", err
+	}
+	pkg = r.NormalizePkgName(pkg)
+	tr, fc, err := r.FindType(file, pkg, name)
+	if err != nil {
+		return typeRef{}, resolvedFile{}, nil, "", err
+	}
+	if tr.expr == nil || len(tr.params) != len(indices) {
+		if r.verbose {
+			log.Warnf("reference resolving: unsupported generic type \"%s\" in the file \"%s\"", ref, file)
+		}
+		return typeRef{}, resolvedFile{}, nil, "", fmt.Errorf("unsupported generic type \"%s\"", ref)
+	}
+
+	args := make(map[string]typeArg, len(indices))
+	resolved := make([]string, len(indices))
+	names := name
+	for i, p := range tr.params {
+		arg := typeArg{expr: indices[i], file: file, env: r.args}
+		// Type parameter passed through
+		if id, ok := indices[i].(*ast.Ident); ok {
+			if a, ok := r.args[id.Name]; ok {
+				arg = a
+			}
+		}
+		args[p] = arg
+		resolved[i] = r.ResolveTypeArg(arg, make(map[string]string, 0))
+		names += r.TypeArgName(resolved[i])
+	}
+
+	// Distinct instantiations of the same name are numbered
+	instance := fmt.Sprintf("%s.%s[%s]", pkg, name, strings.Join(resolved, ","))
+	comp := fmt.Sprintf("%s.%s", pkg, names)
+	for i := 2; ; i++ {
+		if k, ok := r.instances[comp]; ok == false || k == instance {
+			break
+		}
+		comp = fmt.Sprintf("%s.%s%d", pkg, names, i)
+	}
+	r.instances[comp] = instance
+	return tr, fc, args, comp, nil
+}
+
+// Components referenced by the entries, recursively, i.e.
+// the entries of the components referenced by the components
+// are included as well. Empty components are omitted.
+func (r *resolver) Components(entries []string) []string {
+	found := make([]string, 0)
+	visited := make(map[string]bool, 0)
+	if len(entries) > 0 {
+		visited[strings.Split(entries[0], " ")[0]] = true
+	}
+	queue := entries
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		c := r.compRx.FindStringSubmatch(e)
+		if len(c) != 2 || visited[c[1]] {
+			continue
+		}
+		visited[c[1]] = true
+		if t, ok := r.types[c[1]]; ok {
+			comp := make([]string, len(t))
+			for i, l := range t {
+				comp[i] = fmt.Sprintf("%s %s", c[1], l)
+			}
+			found = append(found, comp...)
+			queue = append(queue, comp...)
+		}
+	}
+	return found
+}
+
+// Underlying follows the named non-struct types, and aliases, to the
+// underlying type. It returns the underlying type, the file declaring
+// it, the enum values of the named type and false if the type is unknown.
+// The struct and basic types are returned as they are.
+func (r *resolver) Underlying(ref, file string) (string, string, []string, bool) {
+	enum := make([]string, 0)
+	// Enum values are shared only through aliases
+	owner := true
+
+	// The following is soft-locked on 10 inner jumps
+	for i := 0; i < 10; i++ {
+		if r.IsBasicType(ref) || strings.HasPrefix(ref, "[]") || r.IsMapType(ref) || r.IsInstance(ref) {
+			return ref, file, enum, true
+		}
+		if _, ok := r.KnownType(ref, file); ok {
+			return ref, file, enum, true
+		}
+		pkg, name, err := r.ReferencePackage(ref, file)
+		if err != nil {
+			return ref, file, enum, false
+		}
+		pkg = r.NormalizePkgName(pkg)
+		tr, _, err := r.FindType(file, pkg, name)
+		if err != nil {
+			return ref, file, enum, false
+		}
+		// Struct type
+		if tr.underlying == nil {
+			return ref, file, enum, true
+		}
+		if owner && len(enum) == 0 {
+			enum = r.Enum(pkg, name)
+		}
+		owner = owner && tr.alias
+		ref = r.TypeName(tr.underlying)
+		file = tr.file
+	}
+	return ref, file, enum, false
+}
+
+// KnownType lookup of the type referenced in the file.
+// The package prefix of the type is resolved by the
+// file imports, e.g. time.Time -> time.Time,
+// uuid.UUID -> github.com/google/uuid.UUID
+func (r *resolver) KnownType(ref, file string) (knownType, bool) {
+	chunks := strings.Split(ref, ".")
+	if len(chunks) == 1 {
+		kt, ok := r.knownTypes[ref]
+		return kt, ok
+	}
+	if len(chunks) != 2 {
+		return knownType{}, false
+	}
+	pkg := r.NormalizePkgName(r.PkgName(file))
+	fc, _ := r.ResolvedFile(pkg, file)
+	path, ok := fc.imports[chunks[0]]
+	if ok == false {
+		return knownType{}, false
+	}
+	kt, ok := r.knownTypes[fmt.Sprintf("%s.%s", path, chunks[1])]
+	return kt, ok
+}
+
+// Enum values of the type declared
+// in the package by typed constants
+func (r *resolver) Enum(pkg, name string) []string {
+	p, _ := r.Package(pkg)
+	files := make([]string, 0, len(p))
+	for f := range p {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	enum := make([]string, 0)
+	for _, f := range files {
+		enum = append(enum, p[f].enums[name]...)
+	}
+	return enum
+}
+
+// FieldAttrs formats the additional field attributes
+// into the struct tag like section, e.g. `enum:"[1,2]"`,
+// followed by a space. Empty for no attributes.
+func (r *resolver) FieldAttrs(attrs map[string]string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s:%s", k, strconv.Quote(attrs[k]))
+	}
+	return fmt.Sprintf("`%s` ", strings.Join(pairs, " "))
+}
+
+// EmbeddedFields resolves the fields of the embedded struct type,
+// as params promoted into the embedding struct. It returns false
+// if the embedded type is not a resolvable struct.
+func (r *resolver) EmbeddedFields(t, file, pkgname string, depth, embedded int, path []string) ([]param, bool) {
+	var tr typeRef
+	var args map[string]typeArg
+	var name string
+	var err error
+
+	// Generic type instantiation
+	if r.IsInstance(t) {
+		tr, _, args, name, err = r.Instance(t, file)
+		if err != nil {
+			return nil, false
+		}
+	} else {
+		pkg, ref, err := r.ReferencePackage(t, file)
+		if err != nil {
+			return nil, false
+		}
+		pkg = r.NormalizePkgName(pkg)
+		tr, _, err = r.FindType(file, pkg, ref)
+		if err != nil {
+			return nil, false
+		}
+		name = fmt.Sprintf("%s.%s", pkg, ref)
+	}
+
+	// Cycling embedded types
+	for _, p := range path {
+		if p == name {
+			return []param{}, true
+		}
+	}
+
+	path = append(path[:len(path):len(path)], name)
+	prev := r.args
+	r.args = args
+	params := r.StructFields(tr.file, pkgname, tr.expr, depth, embedded+1, path)
+	r.args = prev
+	return params, true
+}
+
+// DominantParams reduces the params by the Go visibility rules
+// applied by the encoding/json. A param with the shallowest
+// embedding depth wins, if there are more of them on the
+// same depth, the only tagged one wins, otherwise all of
+// them are dropped.
+func (r *resolver) DominantParams(params []param) []param {
+	candidates := make(map[string][]int, 0)
+	for i, p := range params {
+		candidates[p.name] = append(candidates[p.name], i)
+	}
+
+	dominant := make([]param, 0, len(params))
+	for i, p := range params {
+		winner := -1
+		shallowest := -1
+		for _, c := range candidates[p.name] {
+			if shallowest == -1 || params[c].embedded < params[shallowest].embedded {
+				shallowest = c
+			}
+		}
+		level := make([]int, 0)
+		tagged := make([]int, 0)
+		for _, c := range candidates[p.name] {
+			if params[c].embedded == params[shallowest].embedded {
+				level = append(level, c)
+				if params[c].tagged {
+					tagged = append(tagged, c)
 				}
 			}
+		}
+		if len(level) == 1 {
+			winner = level[0]
+		} else if len(tagged) == 1 {
+			winner = tagged[0]
+		}
+		if winner == i {
+			dominant = append(dominant, p)
+		}
+	}
+	return dominant
+}
+
+// FieldComment returns the field documentation,
+// i.e. the comment above the field, or the line
+// comment if there is no documentation, and the
+// annotations declared within the comment
+func (r *resolver) FieldComment(f *ast.Field) (string, map[string]string) {
+	c := f.Doc
+	if c == nil {
+		c = f.Comment
+	}
+	if c == nil {
+		return "", make(map[string]string, 0)
+	}
+	return commentAnnotations(c.Text())
+}
 
-			desc = ""
+// TypeName of the field type expression, cleared from
+// pointers. Arrays and slices are normalized into "[]type",
+// interfaces into the pseudo "object" type
+func (r *resolver) TypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return r.TypeName(t.X)
+	case *ast.ParenExpr:
+		return r.TypeName(t.X)
+	case *ast.ArrayType:
+		return "[]" + r.TypeName(t.Elt)
+	case *ast.InterfaceType:
+		return "object"
+	case *ast.StructType:
+		return "struct"
+	case *ast.Ident:
+		if t.Name == "any" {
+			return "object"
 		}
+		return t.Name
 	}
+	return types.ExprString(expr)
+}
 
-	r.types[pkgname] = make([]string, len(entries))
-	copy(r.types[pkgname], entries)
-	return entries
+// ElemType of the field type expression, i.e. the type
+// cleared from pointers, arrays and slices
+func (r *resolver) ElemType(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return r.ElemType(t.X)
+	case *ast.ParenExpr:
+		return r.ElemType(t.X)
+	case *ast.ArrayType:
+		return r.ElemType(t.Elt)
+	}
+	return expr
+}
+
+// FieldOptions of the name tag value, i.e. the comma separated
+// options following the name, e.g. "name,omitempty,string"
+func (r *resolver) FieldOptions(tag string) map[string]bool {
+	opts := make(map[string]bool, 0)
+	chunks := strings.Split(tag, ",")
+	for _, o := range chunks[1:] {
+		if o = strings.TrimSpace(o); o != "" {
+			opts[o] = true
+		}
+	}
+	return opts
 }
 
 // ParseFieldMeta associated with a struct property
@@ -404,139 +1281,246 @@ func (r *resolver) IsBasicType(tested string) bool {
 	return false
 }
 
+// IsMapType determines if the type is a map,
+// or an array of maps
+func (r *resolver) IsMapType(t string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(t, "[]"), "map[")
+}
+
+// IsInstance determines if the type is
+// a generic type instantiation, e.g. Page[Person]
+func (r *resolver) IsInstance(t string) bool {
+	t = strings.TrimPrefix(t, "[]")
+	return r.IsMapType(t) == false && strings.Contains(t, "[")
+}
+
+// IsComponent determines if the type
+// is the name of a resolved component
+func (r *resolver) IsComponent(t string) bool {
+	_, ok := r.types[t]
+	return ok
+}
+
 // ParsePackage files into the cache.
 // If the package is already parsed, the processing is skipped.
+// Safe for concurrent use, the package is stored once parsed,
+// the failed package is not stored, i.e. the error is
+// returned on every call.
 func (r *resolver) ParsePackage(root, name string) error {
 	name = r.NormalizePkgName(name)
-	_, ok := r.packages[name]
-	if ok {
+	if _, ok := r.Package(name); ok {
 		return nil
 	}
-	r.packages[name] = make(map[string]resolvedFile, 0)
 
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			if path == root {
-				return nil
-			}
-			return filepath.SkipDir
+	parsed := make(map[string]resolvedFile, 0)
+	files, err := r.loader.Package(root)
+	for _, f := range files {
+		var fc resolvedFile
+		if fc, err = r.parseFile(f.Path); err != nil {
+			break
 		}
+		parsed[f.Path] = fc
+	}
+	if err != nil {
+		return err
+	}
 
-		if strings.HasSuffix(path, ".go") {
-			return r.ParseFile(name, path)
-		}
-		return nil
-	})
+	r.mu.Lock()
+	if _, ok := r.packages[name]; ok == false {
+		r.packages[name] = parsed
+	}
+	r.mu.Unlock()
+	return nil
 }
 
 // ParseFile and store into the cache.
 // It searches for imports and struct entities.
 // Already parsed file is skipped.
 func (r *resolver) ParseFile(pkg, file string) error {
-	_, ok := r.packages[pkg][file]
-	if ok {
+	if _, ok := r.ResolvedFile(pkg, file); ok {
 		return nil
 	}
-
-	fp, err := os.Open(file)
+	fc, err := r.parseFile(file)
 	if err != nil {
 		return err
 	}
-	defer fp.Close()
-	b, err := ioutil.ReadAll(fp)
-	content := string(b)
 
-	// Convert interface{} to a pseudo object, to improve RX speed and
-	// complexity, and it will have the same meaning in the produces
-	// documentation structure.
-	content = strings.Replace(content, "interface{}", "object", -1)
+	// Store the cache, the package is copied
+	// as it might be read concurrently
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := make(map[string]resolvedFile, len(r.packages[pkg])+1)
+	for k, v := range r.packages[pkg] {
+		p[k] = v
+	}
+	p[file] = fc
+	r.packages[pkg] = p
+	return nil
+}
 
-	imports := make(map[string]string, 0)
+// parseFile of the loader into the resolved file
+func (r *resolver) parseFile(file string) (resolvedFile, error) {
+	lf, err := r.loader.File(file)
+	if err != nil {
+		return resolvedFile{}, err
+	}
+	f := lf.AST
+	// Partially parsed file is still usable
+	if lf.Err != nil && r.verbose {
+		log.Warnf("reference resolving: the file \"%s\" contains errors: %v", file, lf.Err)
+	}
 
-	// Single import
-	ic := r.importSingleRx.FindStringSubmatch(content)
-	if len(ic) > 0 {
-		sections := strings.Split(ic[1], "/")
-		// Ignore system packages
-		if len(sections) > 1 {
-			imports[sections[len(sections)-1]] = ic[1]
+	imports := make(map[string]string, 0)
+	for _, i := range f.Imports {
+		path, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
 		}
-
-		// Multi import
-	} else {
-		ic = r.importMultiRx.FindStringSubmatch(content)
-		if len(ic) > 0 {
-			lines := strings.Split(strings.Replace(ic[1], "\r\n", "\n", -1), "\n")
-			for _, l := range lines {
-				l = strings.TrimSpace(l)
-				l = strings.Replace(l, "_ \"", "\"", -1)
-				if l != "" {
-					chunks := strings.Split(l, " ")
-					if len(chunks) == 2 {
-						imports[chunks[0]] = strings.Replace(chunks[1], "\"", "", -1)
-					} else {
-						l = strings.Replace(l, "\"", "", -1)
-						sections := strings.Split(l, "/")
-						// Ingore system packages
-						if len(sections) > 1 {
-							imports[sections[len(sections)-1]] = l
-						}
-					}
-				}
+		// Blank imports are used to expose packages
+		// referenced only in the API documentation
+		if i.Name != nil && i.Name.Name != "_" {
+			if i.Name.Name != "." {
+				imports[i.Name.Name] = path
 			}
+			continue
 		}
+		imports[loader.ImportName(path)] = path
 	}
 
-	// Struct. i.e. types
+	// Types, and enums, i.e. typed constants
 	types := make(map[string]typeRef, 0)
-	sc := r.structRx.FindAllStringSubmatch(content, -1)
-	for _, c := range sc {
-		types[c[1]] = typeRef{
-			file:    file,
-			content: c[2],
+	enums := make(map[string][]string, 0)
+	consts := make(map[string]constant.Value, 0)
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if ok == false {
+			continue
+		}
+		if gd.Tok == token.CONST {
+			for t, values := range parseEnums(gd, consts) {
+				enums[t] = append(enums[t], values...)
+			}
+			continue
+		}
+		if gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if ok == false {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				types[ts.Name.Name] = typeRef{
+					file:   file,
+					expr:   st,
+					params: typeParams(ts),
+				}
+			} else {
+				types[ts.Name.Name] = typeRef{
+					file:       file,
+					underlying: ts.Type,
+					alias:      ts.Assign.IsValid(),
+				}
+			}
 		}
 	}
 
-	// Store the cache
-	r.packages[pkg][file] = resolvedFile{
+	return resolvedFile{
 		imports: imports,
 		types:   types,
+		enums:   enums,
 		file:    file,
+	}, nil
+}
+
+// typeParams names of the generic type
+func typeParams(ts *ast.TypeSpec) []string {
+	params := make([]string, 0)
+	if ts.TypeParams == nil {
+		return params
 	}
+	for _, f := range ts.TypeParams.List {
+		for _, n := range f.Names {
+			params = append(params, n.Name)
+		}
+	}
+	return params
+}
 
-	return nil
+// parseKnownTypes from the type to "type[:format]" mapping
+func parseKnownTypes(mapping map[string]string) map[string]knownType {
+	types := make(map[string]knownType, len(mapping))
+	for k, v := range mapping {
+		chunks := strings.SplitN(v, ":", 2)
+		kt := knownType{t: strings.TrimSpace(chunks[0])}
+		if len(chunks) == 2 {
+			kt.format = strings.TrimSpace(chunks[1])
+		}
+		types[strings.TrimSpace(k)] = kt
+	}
+	return types
 }
 
-// NewResolver instance
-func NewResolver(verbose bool) Resolver {
+// NewResolver instance.
+// Known types extend, or override, the builtin known types
+// mapping, the expected format is: pkg/path.Type -> type[:format].
+// Nullable marks the pointer fields as nullable. The loader
+// defaults to a new loader if not provided. Workers bound the
+// packages prefetching, the number of CPUs is used if not set.
+func NewResolver(verbose bool, l loader.Loader, knownTypes map[string]string, nullable bool, workers int) Resolver {
+	if l == nil {
+		l = loader.NewLoader(verbose, nil, workers)
+	}
+	mapping := map[string]string{
+		"[]byte":                         "string:byte",
+		"[]uint8":                        "string:byte",
+		"time.Time":                      "string:date-time",
+		"time.Duration":                  "integer:int64",
+		"encoding/json.RawMessage":       "object",
+		"net.IP":                         "string:ipv4",
+		"net/url.URL":                    "string:uri",
+		"math/big.Int":                   "integer",
+		"math/big.Float":                 "string",
+		"github.com/google/uuid.UUID":    "string:uuid",
+		"github.com/gofrs/uuid.UUID":     "string:uuid",
+		"github.com/satori/go.uuid.UUID": "string:uuid",
+	}
+	for k, v := range knownTypes {
+		mapping[k] = v
+	}
+
 	return &resolver{
 		verbose:      verbose,
+		nullable:     nullable,
+		knownTypes:   parseKnownTypes(mapping),
 		gopath:       filepath.Join(os.Getenv("GOPATH"), "src"),
-		builtinTypes: []string{"bool", "string", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "float32", "float64", "complex64", "complex128", "object"},
+		modcache:     loader.ModCacheDir(),
+		modules:      make(map[string]*loader.Module, 0),
+		builtinTypes: []string{"bool", "string", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "float32", "float64", "complex64", "complex128", "object", "integer", "number", "boolean"},
 		packages:     make(map[string]map[string]resolvedFile, 0),
 		types:        make(map[string][]string, 0),
+		resolving:    make(map[string]bool, 0),
+		instances:    make(map[string]string, 0),
 		prefixMapping: map[string]mappingType{
-			"body":    {"bref", typeBody},
-			"success": {"sref", typeResp},
-			"failure": {"fref", typeResp},
-			"fwrap":   {"fwrapref", typeWrap},
-			"swrap":   {"swrapref", typeWrap},
+			"body":    {"bref", typeBody, "bref"},
+			"success": {"sref", typeResp, "sref"},
+			"failure": {"fref", typeResp, "fref"},
+			"fwrap":   {"fwrapref", typeWrap, "fref"},
+			"swrap":   {"swrapref", typeWrap, "sref"},
 		},
 		metaMapping: map[string]string{
 			"name": "json",
 			"type": "apitype",
 			"req":  "required",
 		},
-		structRx:       regexp.MustCompile("type\\s(.*)\\sstruct\\s?{([^}]+)}"),
-		importSingleRx: regexp.MustCompile("import \"(.*)\""),
-		importMultiRx:  regexp.MustCompile("import \\(([^)]+)\\)"),
-		fieldRx:        regexp.MustCompile("^\\/\\/\\s?(.*)|([^\\s]+)\\s+([^\\s]+)\\s+`(.*)`|([^\\s]+)\\s+([^\\s]+)|.*"),
-		metaRx:         regexp.MustCompile("([a-z]+)+:\"([^\"]+)\""),
-		respRx:         regexp.MustCompile("(?:success|failure).*{object}\\s+([^\\s]+)"),
-		boolRx:         regexp.MustCompile("false|true"),
-		typeCleanRx:    regexp.MustCompile(".*\\."),
+		validateTags: []string{"validate", "binding"},
+		loader:       l,
+		workers:      workers,
+		metaRx:       regexp.MustCompile("([a-z]+)+:\"([^\"]+)\""),
+		respRx:       regexp.MustCompile("(?:success|failure).*{object}\\s+(.+)"),
+		boolRx:       regexp.MustCompile("}\\s(?:false|true)"),
+		compRx:       regexp.MustCompile("^\\S+ \\S+ {(?:\\[\\]|map\\[[^\\]]*\\])*([^\\s{}\\[\\]]*\\.[^\\s{}\\[\\]]*)}"),
+		typeCleanRx:  regexp.MustCompile(".*\\."),
 	}
 }
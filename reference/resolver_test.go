@@ -2,19 +2,23 @@ package reference
 
 import (
 	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spaceavocado/apidoc/extract"
+	"github.com/spaceavocado/apidoc/loader"
 )
 
 func TestResolve(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Nothing to resolve
 	err := r.Resolve([]extract.Block{
@@ -33,8 +37,7 @@ func TestResolve(t *testing.T) {
 			"github.com/pkg/response/tmp.go": {
 				types: map[string]typeRef{
 					"person": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -191,8 +194,7 @@ func TestResolve(t *testing.T) {
 			"github.com/pkg/response/tmp.go": {
 				types: map[string]typeRef{
 					"person": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -224,10 +226,54 @@ func TestResolve(t *testing.T) {
 	if blocks[0].Lines[1] != "swrapref github.com/pkg/response.person Data {object} true true" {
 		t.Errorf("Expected \"%s\", got \"%s\"", "swrapref github.com/pkg/response.person Data {object} true true", blocks[0].Lines[1])
 	}
+
+	// Referenced components
+	r.types = map[string][]string{
+		"github.com/pkg/response.person": {
+			"Name {string} true Description",
+			"Address {github.com/pkg/response.address} false ",
+		},
+		"github.com/pkg/response.address": {
+			"City {string} false ",
+		},
+	}
+	blocks = []extract.Block{
+		{
+			File: "github.com/pkg/response/tmp.go",
+			Lines: []string{
+				"body person",
+				"fwrap person Name",
+			},
+		},
+	}
+	err = r.Resolve(blocks)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"bref github.com/pkg/response.person Name {string} true Description",
+		"bref github.com/pkg/response.person Address {github.com/pkg/response.address} false ",
+		"bref github.com/pkg/response.address City {string} false ",
+		"body github.com/pkg/response.person",
+		"fwrapref github.com/pkg/response.person Name {string} true true Description",
+		"fwrapref github.com/pkg/response.person Address {github.com/pkg/response.address} false false ",
+		"fref github.com/pkg/response.address City {string} false ",
+		"fwrap github.com/pkg/response.person Name",
+	}
+	if len(blocks[0].Lines) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(blocks[0].Lines), blocks[0].Lines)
+		return
+	}
+	for i, e := range expected {
+		if blocks[0].Lines[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, blocks[0].Lines[i])
+		}
+	}
 }
 
 func TestHasExpectedPrefix(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 	m := r.HasExpectedPrefix("body response.Something")
 	if m == (mappingType{}) {
 		t.Errorf("Expecting mapping, got nothing")
@@ -245,7 +291,7 @@ func TestHasExpectedPrefix(t *testing.T) {
 }
 
 func TestAddPrefix(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 	items := []string{"a", "b"}
 	r.AddPrefix("prefix_", items)
 	for _, e := range items {
@@ -257,7 +303,7 @@ func TestAddPrefix(t *testing.T) {
 }
 
 func TestPkgName(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 	res := r.PkgName("github.com/pkg/name")
 	if res != "github.com/pkg" {
 		t.Errorf("Expected \"%s\", got \"%s\"", "github.com/pkg", res)
@@ -271,7 +317,7 @@ func TestPkgName(t *testing.T) {
 }
 
 func TestNormalizePkgName(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	tests := []string{
 		r.gopath + "/github.com/pkg",
@@ -289,8 +335,31 @@ func TestNormalizePkgName(t *testing.T) {
 	}
 }
 
+func TestPkgDir(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	dir, _ := filepath.Abs("service")
+	r.modules = map[string]*loader.Module{
+		dir: {
+			Root: dir,
+			Path: "github.com/org/service",
+		},
+	}
+
+	// Module package
+	res := r.PkgDir("github.com/org/service/model", "service/main.go")
+	if res != filepath.Join(dir, "model") {
+		t.Errorf("Expected \"%s\", got \"%s\"", filepath.Join(dir, "model"), res)
+	}
+
+	// GOPATH fallback
+	res = r.PkgDir("github.com/org/other", "service/main.go")
+	if res != filepath.Join(r.gopath, "github.com", "org", "other") {
+		t.Errorf("Expected \"%s\", got \"%s\"", filepath.Join(r.gopath, "github.com", "org", "other"), res)
+	}
+}
+
 func TestResolveReference(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Invalid file
 	_, err := r.ResolveReference("", "not-existing/response.go", 0)
@@ -305,8 +374,7 @@ func TestResolveReference(t *testing.T) {
 			"github.com/pkg/response/tmp.go": {
 				types: map[string]typeRef{
 					"person": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -337,8 +405,7 @@ func TestResolveReference(t *testing.T) {
 			"github.com/pkg/response/tmp.go": {
 				types: map[string]typeRef{
 					"person": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -368,8 +435,7 @@ func TestResolveReference(t *testing.T) {
 				},
 				types: map[string]typeRef{
 					"person": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -399,8 +465,7 @@ func TestResolveReference(t *testing.T) {
 				},
 				types: map[string]typeRef{
 					"Object": {
-						file:    "github.com/pkg/response/tmp.go",
-						content: "",
+						file: "github.com/pkg/response/tmp.go",
 					},
 				},
 			},
@@ -427,7 +492,7 @@ func TestResolveReference(t *testing.T) {
 }
 
 func TestPkgLoc(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Existing
 	loc, err := r.PkgLoc("response", resolvedFile{
@@ -459,7 +524,7 @@ func TestPkgLoc(t *testing.T) {
 	b := &bytes.Buffer{}
 	log.SetOutput(b)
 	hook := test.NewGlobal()
-	r = NewResolver(true).(*resolver)
+	r = NewResolver(true, nil, nil, false, 0).(*resolver)
 
 	r.PkgLoc("other", resolvedFile{
 		imports: map[string]string{
@@ -480,7 +545,7 @@ func TestPkgLoc(t *testing.T) {
 }
 
 func TestReferenceDetails(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Missing package
 	_, err := r.ReferenceDetails("", "x", "", 0)
@@ -492,7 +557,7 @@ func TestReferenceDetails(t *testing.T) {
 	b := &bytes.Buffer{}
 	log.SetOutput(b)
 	hook := test.NewGlobal()
-	r = NewResolver(true).(*resolver)
+	r = NewResolver(true, nil, nil, false, 0).(*resolver)
 	r.packages = map[string]map[string]resolvedFile{
 		"github.com/pkg/response": {
 			"github.com/pkg/response/tmp.go": {
@@ -520,7 +585,7 @@ func TestReferenceDetails(t *testing.T) {
 
 	// Verbose missing package
 	hook.Reset()
-	r = NewResolver(true).(*resolver)
+	r = NewResolver(true, nil, nil, false, 0).(*resolver)
 	_, err = r.ReferenceDetails("", "x", "", 0)
 	if err == nil {
 		t.Errorf("Expected error, got nil")
@@ -539,7 +604,7 @@ func TestReferenceDetails(t *testing.T) {
 }
 
 func TestTypeToParams(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Cache, no changes, i.e. depth over 0
 	r.types = map[string][]string{
@@ -550,7 +615,7 @@ func TestTypeToParams(t *testing.T) {
 	res := r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		"",
+		nil,
 		make(map[string]string, 0),
 		1,
 	)
@@ -570,7 +635,7 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		"",
+		nil,
 		make(map[string]string, 0),
 		0,
 	)
@@ -594,7 +659,7 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		content,
+		parseStruct(t, content),
 		make(map[string]string, 0),
 		0,
 	)
@@ -622,7 +687,7 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		content,
+		parseStruct(t, content),
 		make(map[string]string, 0),
 		1,
 	)
@@ -670,24 +735,19 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		content,
+		parseStruct(t, content),
 		make(map[string]string, 0),
 		0,
 	)
-	if len(res) != 6 {
-		t.Errorf("Expected %d lines, got %d", 6, len(res))
-	}
-	if res[0] != "github.com/pkg/response Name {object}" {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Name {object}", res[0])
-	}
-	if res[1] != "github.com/pkg/response Name.firstname {string} false " {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Name.firstname {string} false ", res[1])
+	if len(res) != 2 {
+		t.Errorf("Expected %d lines, got %d", 2, len(res))
+		return
 	}
-	if res[2] != "github.com/pkg/response Name.lastname {string} false " {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Name.lastname {string} false ", res[2])
+	if res[0] != "github.com/pkg/response Name {github.com/pkg/person.Name} false " {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Name {github.com/pkg/person.Name} false ", res[0])
 	}
-	if res[3] != "github.com/pkg/response Details {object}" {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Details {object}", res[3])
+	if res[1] != "github.com/pkg/response Details {github.com/pkg/person.Details} false " {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "github.com/pkg/response Details {github.com/pkg/person.Details} false ", res[1])
 	}
 
 	// Valid, reference, non base depth
@@ -722,15 +782,16 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		content,
+		parseStruct(t, content),
 		make(map[string]string, 0),
 		1,
 	)
-	if len(res) != 6 {
-		t.Errorf("Expected %d lines, got %d", 6, len(res))
+	if len(res) != 2 {
+		t.Errorf("Expected %d lines, got %d", 2, len(res))
+		return
 	}
-	if res[0] != "Name {object}" {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "Name {object}", res[0])
+	if res[0] != "Name {github.com/pkg/person.Name} false " {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "Name {github.com/pkg/person.Name} false ", res[0])
 	}
 
 	// Valid, reference, array
@@ -770,20 +831,261 @@ func TestTypeToParams(t *testing.T) {
 	res = r.TypeToParams(
 		"github.com/pkg/response/tmp.go",
 		"github.com/pkg/response",
-		content,
+		parseStruct(t, content),
 		make(map[string]string, 0),
 		1,
 	)
-	if len(res) != 6 {
-		t.Errorf("Expected %d lines, got %d", 6, len(res))
+	if len(res) != 2 {
+		t.Errorf("Expected %d lines, got %d", 2, len(res))
+		return
+	}
+	if res[0] != "Name {[]github.com/pkg/person.Name} false " {
+		t.Errorf("Expected \"%s\" error, got \"%s\"", "Name {[]github.com/pkg/person.Name} false ", res[0])
+	}
+}
+
+func TestTypeToParamsStructs(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	// Multi-field declarations, inline structs
+	content :=
+		`
+		// Coordinates {x, y}
+		X, Y int
+		Meta struct {
+			Created string ` + "`json:\"created\"`" + `
+			Tags []string
+		}
+		Items []struct {
+			ID int
+		}
+		Data interface{}
+	`
+	res := r.TypeToParams(
+		"github.com/pkg/response/tmp.go",
+		"github.com/pkg/response",
+		parseStruct(t, content),
+		make(map[string]string, 0),
+		0,
+	)
+	expected := []string{
+		"github.com/pkg/response X {int} false \"Coordinates {x, y}\"",
+		"github.com/pkg/response Y {int} false \"Coordinates {x, y}\"",
+		"github.com/pkg/response Meta {object}",
+		"github.com/pkg/response Meta.created {string} false ",
+		"github.com/pkg/response Meta.Tags {[]string} false ",
+		"github.com/pkg/response Items {[]object}",
+		"github.com/pkg/response Items.ID {int} false ",
+		"github.com/pkg/response Data {object} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d", len(expected), len(res))
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestTypeToParamsEmbedded(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpembed", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpembed")
+	content :=
+		`
+		package tmpembed
+
+		type Audit struct {
+			Created string ` + "`json:\"created\"`" + `
+			ID      int    ` + "`json:\"id\"`" + `
+		}
+
+		type Base struct {
+			Audit
+			ID   string ` + "`json:\"id\"`" + `
+			Name string
+		}
+
+		type Named struct {
+			Label string ` + "`json:\"label\"`" + `
+		}
+
+		type Other struct {
+			Label string ` + "`json:\"label\"`" + `
+		}
+
+		type Cycle struct {
+			*Cycle
+			Value int
+		}
+
+		type Person struct {
+			*Base
+			Named
+			Other
+			Audit ` + "`json:\"audit\"`" + `
+			Cycle
+			Email string
+		}
+	`
+	err = ioutil.WriteFile("tmpembed/models.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("Person", "tmpembed/models.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpembed.Person created {string} false ",
+		"tmpembed.Person id {string} false ",
+		"tmpembed.Person Name {string} false ",
+		"tmpembed.Person audit {tmpembed.Audit} false ",
+		"tmpembed.Person Value {int} false ",
+		"tmpembed.Person Email {string} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestTypeToParamsNamed(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpnamed", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpnamed")
+	files := map[string]string{
+		"tmpnamed/types.go": `
+		package tmpnamed
+
+		type Status int
+
+		type Level Status
+
+		type State = Status
+
+		type Role = string
+
+		type Tags []string
+
+		type Admin Profile
+
+		type Profile struct {
+			Name string
+		}
+
+		type Person struct {
+			Status   Status
+			Level    Level
+			State    State
+			Role     Role
+			Tags     Tags
+			Statuses []Status
+			Admin    *Admin ` + "`json:\"admin\"`" + `
+			Func     func()
+		}
+		`,
+		"tmpnamed/enum.go": `
+		package tmpnamed
+
+		const (
+			StatusActive Status = iota
+			StatusInactive
+		)
+		`,
+	}
+	for f, content := range files {
+		err = ioutil.WriteFile(f, []byte(content), 0644)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+	}
+
+	res, err := r.ResolveReference("Person", "tmpnamed/types.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpnamed.Person Status {int} false `enum:\"[0,1]\"` ",
+		"tmpnamed.Person Level {int} false ",
+		"tmpnamed.Person State {int} false `enum:\"[0,1]\"` ",
+		"tmpnamed.Person Role {string} false ",
+		"tmpnamed.Person Tags {[]string} false ",
+		"tmpnamed.Person Statuses {[]int} false `enum:\"[0,1]\"` ",
+		"tmpnamed.Person admin {tmpnamed.Profile} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+
+	// Named type as the reference
+	res, err = r.ResolveReference("Admin", "tmpnamed/types.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
 	}
-	if res[0] != "Name {[]object}" {
-		t.Errorf("Expected \"%s\" error, got \"%s\"", "Name {[]object}", res[0])
+	if len(res) != 1 || res[0] != "tmpnamed.Profile Name {string} false " {
+		t.Errorf("Expected \"%s\", got %v", "tmpnamed.Profile Name {string} false ", res)
+	}
+}
+
+func TestDominantParams(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	res := r.DominantParams([]param{
+		{name: "a", embedded: 1},
+		{name: "a", embedded: 0},
+		{name: "b", embedded: 1},
+		{name: "b", embedded: 1, tagged: true},
+		{name: "c", embedded: 1, tagged: true},
+		{name: "c", embedded: 1, tagged: true},
+		{name: "d", embedded: 2},
+	})
+	expected := []param{
+		{name: "a", embedded: 0},
+		{name: "b", embedded: 1, tagged: true},
+		{name: "d", embedded: 2},
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d params, got %d", len(expected), len(res))
+		return
+	}
+	for i, e := range expected {
+		if res[i].name != e.name || res[i].embedded != e.embedded || res[i].tagged != e.tagged {
+			t.Errorf("Expected %+v, got %+v", e, res[i])
+		}
 	}
 }
 
 func TestParseFieldMeta(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 	// invalid
 	res := r.ParseFieldMeta("")
 	if len(res) != 0 {
@@ -814,9 +1116,9 @@ func TestParseFieldMeta(t *testing.T) {
 }
 
 func TestIsBasicType(t *testing.T) {
-	valid := []string{"bool", "string", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "float32", "float64", "complex64", "complex128", "object"}
+	valid := []string{"bool", "string", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "float32", "float64", "complex64", "complex128", "object", "integer", "number", "boolean"}
 	invalid := []string{"custom"}
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	for _, c := range valid {
 		if r.IsBasicType(c) == false {
@@ -833,7 +1135,7 @@ func TestIsBasicType(t *testing.T) {
 }
 
 func TestParsePackage(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Cached
 	r.packages = map[string]map[string]resolvedFile{
@@ -853,6 +1155,15 @@ func TestParsePackage(t *testing.T) {
 		t.Errorf("Expected error, got nil")
 		return
 	}
+	// The failed package is not cached
+	if _, ok := r.Package("not-existing"); ok {
+		t.Errorf("Expected the failed package not to be cached")
+	}
+	err = r.ParsePackage("not-existing", "not-existing")
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+		return
+	}
 
 	// Valid
 	err = os.MkdirAll("tmp", os.ModePerm)
@@ -895,8 +1206,56 @@ func TestParsePackage(t *testing.T) {
 	}
 }
 
+func TestPrefetch(t *testing.T) {
+	files := map[string]string{
+		"tmp/handler/a.go": `package handler
+		import m "github.com/spaceavocado/apidoc/reference/tmp/model"
+		`,
+		"tmp/handler/b.go": "package handler",
+		"tmp/other/c.go":   "package other",
+		"tmp/model/m.go":   "package model\n\ntype Person struct{}",
+	}
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			return
+		}
+	}
+	defer os.RemoveAll("tmp")
+
+	r := NewResolver(false, nil, nil, false, 4).(*resolver)
+	r.Prefetch([]extract.Block{
+		{File: "tmp/handler/a.go", Lines: []string{"body m.Person", "success 200 {object} Page[m.Person] OK"}},
+		{File: "tmp/handler/b.go", Lines: []string{"summary B"}},
+		{File: "tmp/other/c.go", Lines: []string{"body unknown.Type"}},
+		{File: "tmp/missing/d.go", Lines: []string{"body Type"}},
+	})
+
+	expected := []string{"tmp/handler", "tmp/other", r.NormalizePkgName(r.PkgDir("github.com/spaceavocado/apidoc/reference/tmp/model", "tmp/handler/a.go"))}
+	for _, pkg := range expected {
+		if _, ok := r.Package(pkg); ok == false {
+			t.Errorf("Expected the prefetched package \"%s\"", pkg)
+		}
+	}
+	if p, _ := r.Package("tmp/handler"); len(p) != 2 {
+		t.Errorf("Expected %d files, got %d", 2, len(p))
+	}
+
+	// Prefixes of the reference
+	prefixes := r.RefPrefixes("request.Page[model.Person, string]")
+	if strings.Join(prefixes, ",") != "request,model" {
+		t.Errorf("Expected \"%s\", got \"%v\"", "request,model", prefixes)
+	}
+}
+
 func TestParseFile(t *testing.T) {
-	r := NewResolver(false).(*resolver)
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
 
 	// Cached
 	r.packages = map[string]map[string]resolvedFile{
@@ -941,6 +1300,7 @@ func TestParseFile(t *testing.T) {
 	}
 
 	// Single import
+	r.loader = loader.NewLoader(false, nil, 0)
 	r.packages = map[string]map[string]resolvedFile{
 		"github.com/pkg/response": {
 			"other": {},
@@ -972,6 +1332,7 @@ func TestParseFile(t *testing.T) {
 	}
 
 	// Multi import
+	r.loader = loader.NewLoader(false, nil, 0)
 	r.packages = map[string]map[string]resolvedFile{
 		"github.com/pkg/response": {
 			"other": {},
@@ -983,6 +1344,7 @@ func TestParseFile(t *testing.T) {
 		import (
 			"github.com/pkg/request"
 			shortcut "github.com/pkg/tools"
+			_ "github.com/pkg/common"
 		)
 
 	`
@@ -1011,8 +1373,16 @@ func TestParseFile(t *testing.T) {
 	} else {
 		t.Errorf("Multi import not resolved")
 	}
+	if i, ok := r.packages["github.com/pkg/response"]["tmp"].imports["common"]; ok {
+		if i != "github.com/pkg/common" {
+			t.Errorf("Expected \"%s\", got \"%s\"", "github.com/pkg/common", i)
+		}
+	} else {
+		t.Errorf("Blank import not resolved")
+	}
 
 	// Types import
+	r.loader = loader.NewLoader(false, nil, 0)
 	r.packages = map[string]map[string]resolvedFile{
 		"github.com/pkg/response": {
 			"other": {},
@@ -1048,15 +1418,822 @@ func TestParseFile(t *testing.T) {
 		t.Errorf("Expected %d captured types, got %d", 2, len(r.packages["github.com/pkg/response"]["tmp"].types))
 	}
 	if tr, ok := r.packages["github.com/pkg/response"]["tmp"].types["Person"]; ok {
-		if trimAllSpaces(tr.content) != "NamestringAgeint" {
-			t.Errorf("Expected \"%s\", got \"%s\"", "NamestringAgeint", trimAllSpaces(tr.content))
+		if len(tr.expr.Fields.List) != 2 {
+			t.Errorf("Expected %d captured fields, got %d", 2, len(tr.expr.Fields.List))
+		}
+	} else {
+		t.Errorf("Type capture failed")
+	}
+
+	// Grouped and generic types
+	r.loader = loader.NewLoader(false, nil, 0)
+	r.packages = map[string]map[string]resolvedFile{
+		"github.com/pkg/response": {
+			"other": {},
+		},
+	}
+	content =
+		`
+		package tmp
+
+		type (
+			// Page of items {}
+			Page[T any] struct {
+				Items []T
+				Next  string
+			}
+			Status int
+		)
+	`
+	err = ioutil.WriteFile(file, []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	err = r.ParseFile("github.com/pkg/response", "tmp")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	if len(r.packages["github.com/pkg/response"]["tmp"].types) != 2 {
+		t.Errorf("Expected %d captured types, got %d", 2, len(r.packages["github.com/pkg/response"]["tmp"].types))
+	}
+	if _, ok := r.packages["github.com/pkg/response"]["tmp"].types["Page"]; ok == false {
+		t.Errorf("Type capture failed")
+	}
+	if tr, ok := r.packages["github.com/pkg/response"]["tmp"].types["Status"]; ok {
+		if tr.underlying == nil || r.TypeName(tr.underlying) != "int" {
+			t.Errorf("Expected \"%s\" underlying type", "int")
 		}
 	} else {
 		t.Errorf("Type capture failed")
 	}
+
+}
+
+// parseStruct body content into the struct type
+func parseStruct(t *testing.T, content string) *ast.StructType {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package tmp\ntype tmp struct {"+content+"}", parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	return f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+}
+
+func TestTypeToParamsKnown(t *testing.T) {
+	r := NewResolver(false, nil, map[string]string{
+		"github.com/shopspring/decimal.Decimal": "string:decimal",
+		"time.Month":                            "integer",
+	}, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpknown", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpknown")
+	content := `
+	package tmpknown
+
+	import (
+		"encoding/json"
+		"time"
+
+		"github.com/google/uuid"
+		"github.com/shopspring/decimal"
+	)
+
+	type Timestamp time.Time
+
+	type Event struct {
+		ID       uuid.UUID
+		Related  []uuid.UUID
+		Created  time.Time
+		Updated  *Timestamp
+		Timeout  time.Duration
+		Payload  json.RawMessage
+		Raw      []byte
+		Price    decimal.Decimal
+		Month    time.Month
+	}
+	`
+	err = ioutil.WriteFile("tmpknown/event.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("Event", "tmpknown/event.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpknown.Event ID {string} false `format:\"uuid\"` ",
+		"tmpknown.Event Related {[]string} false `format:\"uuid\"` ",
+		"tmpknown.Event Created {string} false `format:\"date-time\"` ",
+		"tmpknown.Event Updated {string} false `format:\"date-time\"` ",
+		"tmpknown.Event Timeout {integer} false `format:\"int64\"` ",
+		"tmpknown.Event Payload {object} false ",
+		"tmpknown.Event Raw {string} false `format:\"byte\"` ",
+		"tmpknown.Event Price {string} false `format:\"decimal\"` ",
+		"tmpknown.Event Month {integer} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestKnownType(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	pkg := r.NormalizePkgName(r.PkgName("known.go"))
+	r.packages[pkg] = map[string]resolvedFile{
+		"known.go": {
+			imports: map[string]string{
+				"t":    "time",
+				"uuid": "github.com/gofrs/uuid",
+			},
+		},
+	}
+
+	tests := []struct {
+		ref    string
+		t      string
+		format string
+		ok     bool
+	}{
+		{"t.Time", "string", "date-time", true},
+		{"uuid.UUID", "string", "uuid", true},
+		{"[]byte", "string", "byte", true},
+		{"time.Time", "", "", false},
+		{"Time", "", "", false},
+	}
+	for _, c := range tests {
+		kt, ok := r.KnownType(c.ref, "known.go")
+		if ok != c.ok {
+			t.Errorf("Expected %v for \"%s\", got %v", c.ok, c.ref, ok)
+			continue
+		}
+		if kt.t != c.t || kt.format != c.format {
+			t.Errorf("Expected \"%s:%s\", got \"%s:%s\"", c.t, c.format, kt.t, kt.format)
+		}
+	}
+}
+
+func TestParseKnownTypes(t *testing.T) {
+	res := parseKnownTypes(map[string]string{
+		"time.Time":      "string:date-time",
+		"math/big.Float": " number ",
+	})
+	if res["time.Time"] != (knownType{"string", "date-time"}) {
+		t.Errorf("Expected %+v, got %+v", knownType{"string", "date-time"}, res["time.Time"])
+	}
+	if res["math/big.Float"] != (knownType{"number", ""}) {
+		t.Errorf("Expected %+v, got %+v", knownType{"number", ""}, res["math/big.Float"])
+	}
 }
 
-func trimAllSpaces(s string) string {
-	r := regexp.MustCompile("\\s")
-	return r.ReplaceAllString(s, "")
+func TestTypeToParamsMaps(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpmaps", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpmaps")
+	content := `
+	package tmpmaps
+
+	import "time"
+
+	type Status int
+
+	const (
+		StatusActive Status = iota
+		StatusInactive
+	)
+
+	type Labels map[string]string
+
+	type Item struct {
+		Name  string
+		Attrs map[string]Attr
+	}
+
+	type Attr struct {
+		Value string
+	}
+
+	type Order struct {
+		Labels   map[string]string
+		Named    Labels
+		Counts   map[string][]int
+		Items    map[string]*Item
+		Lists    map[string][]Item
+		Statuses map[Status]Status
+		Dates    map[string]time.Time
+		Nested   map[string]map[string]int
+		Any      map[string]interface{}
+		Inline   map[string]struct{ X int }
+		Many     []map[string]string
+	}
+	`
+	err = ioutil.WriteFile("tmpmaps/order.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("Order", "tmpmaps/order.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpmaps.Order Labels {map[string]string} false ",
+		"tmpmaps.Order Named {map[string]string} false ",
+		"tmpmaps.Order Counts {map[string][]int} false ",
+		"tmpmaps.Order Items {map[string]tmpmaps.Item} false ",
+		"tmpmaps.Order Lists {map[string][]tmpmaps.Item} false ",
+		"tmpmaps.Order Statuses {map[string]int} false `enum:\"[0,1]\"` ",
+		"tmpmaps.Order Dates {map[string]string} false `format:\"date-time\"` ",
+		"tmpmaps.Order Nested {map[string]object} false ",
+		"tmpmaps.Order Any {map[string]object} false ",
+		"tmpmaps.Order Inline {map[string]object} false ",
+		"tmpmaps.Order Many {[]map[string]string} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+
+	// Referenced components, recursively
+	comps := r.Components(res)
+	expected = []string{
+		"tmpmaps.Item Name {string} false ",
+		"tmpmaps.Item Attrs {map[string]tmpmaps.Attr} false ",
+		"tmpmaps.Attr Value {string} false ",
+	}
+	if len(comps) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(comps), comps)
+		return
+	}
+	for i, e := range expected {
+		if comps[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, comps[i])
+		}
+	}
+}
+
+func TestTypeToParamsCached(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpcached", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpcached")
+	content := `
+	package tmpcached
+
+	type Inner struct {
+		X string
+	}
+
+	type Outer struct {
+		Y Inner
+	}
+	`
+	err = ioutil.WriteFile("tmpcached/types.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	// The cached type is reused as a component
+	r.ResolveReference("Inner", "tmpcached/types.go", 0)
+	res, _ := r.ResolveReference("Outer", "tmpcached/types.go", 0)
+	res = append(res, r.Components(res)...)
+	expected := []string{
+		"tmpcached.Outer Y {tmpcached.Inner} false ",
+		"tmpcached.Inner X {string} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestTypeToParamsRecursive(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmprecursive", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmprecursive")
+	content := `
+	package tmprecursive
+
+	type Node struct {
+		Name     string
+		Parent   *Node
+		Children []Node
+		Index    map[string]*Node
+		Edge     Edge
+		Empty    Empty
+	}
+
+	type Edge struct {
+		To   *Node
+		Back *Edge
+	}
+
+	type Empty struct{}
+	`
+	err = ioutil.WriteFile("tmprecursive/node.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("Node", "tmprecursive/node.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	res = append(res, r.Components(res)...)
+	expected := []string{
+		"tmprecursive.Node Name {string} false ",
+		"tmprecursive.Node Parent {tmprecursive.Node} false ",
+		"tmprecursive.Node Children {[]tmprecursive.Node} false ",
+		"tmprecursive.Node Index {map[string]tmprecursive.Node} false ",
+		"tmprecursive.Node Edge {tmprecursive.Edge} false ",
+		"tmprecursive.Node Empty {object} false ",
+		"tmprecursive.Edge To {tmprecursive.Node} false ",
+		"tmprecursive.Edge Back {tmprecursive.Edge} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+
+	// Mutual recursion resolved from the other side
+	res, err = r.ResolveReference("Edge", "tmprecursive/node.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	res = append(res, r.Components(res)...)
+	if len(res) != 8 {
+		t.Errorf("Expected %d lines, got %d: %v", 8, len(res), res)
+		return
+	}
+	if res[2] != "tmprecursive.Node Name {string} false " {
+		t.Errorf("Expected \"%s\", got \"%s\"", "tmprecursive.Node Name {string} false ", res[2])
+	}
+}
+
+func TestTypeToParamsGeneric(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpgeneric", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpgeneric")
+	content := `
+	package tmpgeneric
+
+	type Page[T any] struct {
+		Items []T
+		Total int
+	}
+
+	type Pair[K comparable, V any] struct {
+		Key   K
+		Value *V
+	}
+
+	type Tree[T any] struct {
+		Value    T
+		Children []Tree[T]
+	}
+
+	type Meta[T any] struct {
+		Source T
+	}
+
+	type Envelope[T any] struct {
+		Meta[T]
+		Data  T
+		Pairs Page[Pair[string, T]]
+	}
+
+	type Person struct {
+		Name string
+	}
+
+	type PersonPage = Page[Person]
+	`
+	err = ioutil.WriteFile("tmpgeneric/generic.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	table := []struct {
+		ref      string
+		expected []string
+	}{
+		{
+			ref: "Page[Person]",
+			expected: []string{
+				"tmpgeneric.PagePerson Items {[]tmpgeneric.Person} false ",
+				"tmpgeneric.PagePerson Total {int} false ",
+				"tmpgeneric.Person Name {string} false ",
+			},
+		},
+		{
+			ref: "PersonPage",
+			expected: []string{
+				"tmpgeneric.PagePerson Items {[]tmpgeneric.Person} false ",
+				"tmpgeneric.PagePerson Total {int} false ",
+				"tmpgeneric.Person Name {string} false ",
+			},
+		},
+		{
+			ref: "Page[[]string]",
+			expected: []string{
+				"tmpgeneric.PageStringList Items {[]string} false ",
+				"tmpgeneric.PageStringList Total {int} false ",
+			},
+		},
+		{
+			ref: "Pair[string, Person]",
+			expected: []string{
+				"tmpgeneric.PairStringPerson Key {string} false ",
+				"tmpgeneric.PairStringPerson Value {tmpgeneric.Person} false ",
+				"tmpgeneric.Person Name {string} false ",
+			},
+		},
+		{
+			ref: "Tree[int]",
+			expected: []string{
+				"tmpgeneric.TreeInt Value {int} false ",
+				"tmpgeneric.TreeInt Children {[]tmpgeneric.TreeInt} false ",
+			},
+		},
+		{
+			ref: "Envelope[Person]",
+			expected: []string{
+				"tmpgeneric.EnvelopePerson Source {tmpgeneric.Person} false ",
+				"tmpgeneric.EnvelopePerson Data {tmpgeneric.Person} false ",
+				"tmpgeneric.EnvelopePerson Pairs {tmpgeneric.PagePairStringPerson} false ",
+				"tmpgeneric.Person Name {string} false ",
+				"tmpgeneric.PagePairStringPerson Items {[]tmpgeneric.PairStringPerson} false ",
+				"tmpgeneric.PagePairStringPerson Total {int} false ",
+				"tmpgeneric.PairStringPerson Key {string} false ",
+				"tmpgeneric.PairStringPerson Value {tmpgeneric.Person} false ",
+			},
+		},
+	}
+
+	for _, test := range table {
+		res, err := r.ResolveReference(test.ref, "tmpgeneric/generic.go", 0)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		res = append(res, r.Components(res)...)
+		if len(res) != len(test.expected) {
+			t.Errorf("Expected %d lines, got %d: %v", len(test.expected), len(res), res)
+			continue
+		}
+		for i, e := range test.expected {
+			if res[i] != e {
+				t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+			}
+		}
+	}
+
+	// Type arguments count mismatch
+	if _, err := r.ResolveReference("Page[int, int]", "tmpgeneric/generic.go", 0); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestTypeArgName(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	table := []struct {
+		t        string
+		expected string
+	}{
+		{"string", "String"},
+		{"object", "Object"},
+		{"github.com/pkg/person.Person", "Person"},
+		{"[]int64", "Int64List"},
+		{"map[string]pkg.Item", "MapItem"},
+		{"[]map[string]bool", "MapBoolList"},
+	}
+	for _, test := range table {
+		if res := r.TypeArgName(test.t); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestRefExpr(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	table := []struct {
+		text     string
+		expected string
+	}{
+		{"person.Person", "person.Person"},
+		{"person.Person desc", "person.Person"},
+		{"Page[person.Person] desc", "Page[person.Person]"},
+		{"Pair[string, person.Person] desc", "Pair[string, person.Person]"},
+		{"[]Page[int] data", "[]Page[int]"},
+	}
+	for _, test := range table {
+		if res := r.RefExpr(test.text); res != test.expected {
+			t.Errorf("Expected \"%s\", got \"%s\"", test.expected, res)
+		}
+	}
+}
+
+func TestTypeToParamsValidate(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpvalidate", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpvalidate")
+	content := `
+	package tmpvalidate
+
+	type Role string
+
+	const (
+		RoleAdmin Role = "admin"
+		RoleGuest Role = "guest"
+	)
+
+	type User struct {
+		Email string   ` + "`json:\"email\" validate:\"required,max=64,email\"`" + `
+		Age   int      ` + "`json:\"age\" binding:\"gte=18\"`" + `
+		Tags  []string ` + "`json:\"tags\" validate:\"min=1\" required:\"false\"`" + `
+		Role  Role     ` + "`json:\"role\" validate:\"oneof=admin\"`" + `
+	}
+	`
+	err = ioutil.WriteFile("tmpvalidate/user.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("User", "tmpvalidate/user.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpvalidate.User email {string} true `format:\"email\" maxLength:\"64\"` ",
+		"tmpvalidate.User age {int} false `minimum:\"18\"` ",
+		"tmpvalidate.User tags {[]string} false `minItems:\"1\"` ",
+		"tmpvalidate.User role {string} false `enum:\"[\\\"admin\\\"]\"` ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestTypeToParamsJSONOptions(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpjsonopts", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpjsonopts")
+	content := `
+	package tmpjsonopts
+
+	type Meta struct {
+		Version int ` + "`json:\"version\"`" + `
+	}
+
+	type base struct {
+		ID string ` + "`json:\"id\"`" + `
+	}
+
+	type Order struct {
+		base
+		secret  string
+		Note    string ` + "`json:\",omitempty\"`" + `
+		Total   int64  ` + "`json:\"total,string\" validate:\"min=0,max=100\"`" + `
+		Status  uint8  ` + "`json:\"status,string\" validate:\"oneof=1 2\"`" + `
+		Paid    bool   ` + "`json:\"paid,string,omitempty\" required:\"true\"`" + `
+		Code    string ` + "`json:\"code,string\" validate:\"required\"`" + `
+		Meta    Meta   ` + "`json:\",inline\"`" + `
+		Skipped string ` + "`json:\"-\"`" + `
+		Dash    string ` + "`json:\"-,\"`" + `
+	}
+	`
+	err = ioutil.WriteFile("tmpjsonopts/order.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("Order", "tmpjsonopts/order.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpjsonopts.Order id {string} false ",
+		"tmpjsonopts.Order Note {string} false ",
+		"tmpjsonopts.Order total {string} false ",
+		"tmpjsonopts.Order status {string} false `enum:\"[\\\"1\\\",\\\"2\\\"]\"` ",
+		"tmpjsonopts.Order paid {string} false ",
+		"tmpjsonopts.Order code {string} true ",
+		"tmpjsonopts.Order version {int} false ",
+		"tmpjsonopts.Order - {string} false ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestFieldOptions(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+	opts := r.FieldOptions("name,omitempty, string")
+	if len(opts) != 2 || opts["omitempty"] == false || opts["string"] == false {
+		t.Errorf("Expected omitempty and string options, got %v", opts)
+	}
+	if opts := r.FieldOptions("name"); len(opts) != 0 {
+		t.Errorf("Expected no options, got %v", opts)
+	}
+}
+
+func TestTypeToParamsAnnotations(t *testing.T) {
+	r := NewResolver(false, nil, nil, false, 0).(*resolver)
+
+	err := os.MkdirAll("tmpannotations", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpannotations")
+	content := `
+	package tmpannotations
+
+	type User struct {
+		// Unique identifier
+		// @readOnly
+		// @example 42
+		ID int64
+		Password string ` + "`json:\"password\" writeOnly:\"true\" format:\"password\"`" + `
+		Kind string ` + "`json:\"kind\" enum:\"user,admin\" default:\"user\"`" + `
+	}
+	`
+	err = ioutil.WriteFile("tmpannotations/user.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	res, err := r.ResolveReference("User", "tmpannotations/user.go", 0)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := []string{
+		"tmpannotations.User ID {int64} false `example:\"42\" readOnly:\"true\"` \"Unique identifier\"",
+		"tmpannotations.User password {string} false `format:\"password\" writeOnly:\"true\"` ",
+		"tmpannotations.User kind {string} false `default:\"\\\"user\\\"\" enum:\"[\\\"user\\\",\\\"admin\\\"]\"` ",
+	}
+	if len(res) != len(expected) {
+		t.Errorf("Expected %d lines, got %d: %v", len(expected), len(res), res)
+		return
+	}
+	for i, e := range expected {
+		if res[i] != e {
+			t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+		}
+	}
+}
+
+func TestTypeToParamsNullable(t *testing.T) {
+	err := os.MkdirAll("tmpnullable", os.ModePerm)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	defer os.RemoveAll("tmpnullable")
+	content := `
+	package tmpnullable
+
+	type Patch struct {
+		Name   *string
+		Age    int
+		Detail *Detail
+		Tags   []*string
+		Note   *string ` + "`nullable:\"false\"`" + `
+	}
+
+	type Detail struct {
+		Bio string
+	}
+	`
+	err = ioutil.WriteFile("tmpnullable/patch.go", []byte(content), 0644)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	table := []struct {
+		nullable bool
+		expected []string
+	}{
+		{
+			nullable: false,
+			expected: []string{
+				"tmpnullable.Patch Name {string} false ",
+				"tmpnullable.Patch Age {int} false ",
+				"tmpnullable.Patch Detail {tmpnullable.Detail} false ",
+				"tmpnullable.Patch Tags {[]string} false ",
+				"tmpnullable.Patch Note {string} false `nullable:\"false\"` ",
+			},
+		},
+		{
+			nullable: true,
+			expected: []string{
+				"tmpnullable.Patch Name {string} false `nullable:\"true\"` ",
+				"tmpnullable.Patch Age {int} false ",
+				"tmpnullable.Patch Detail {tmpnullable.Detail} false `nullable:\"true\"` ",
+				"tmpnullable.Patch Tags {[]string} false ",
+				"tmpnullable.Patch Note {string} false `nullable:\"false\"` ",
+			},
+		},
+	}
+
+	for _, test := range table {
+		r := NewResolver(false, nil, nil, test.nullable, 0).(*resolver)
+		res, err := r.ResolveReference("Patch", "tmpnullable/patch.go", 0)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if len(res) != len(test.expected) {
+			t.Errorf("Expected %d lines, got %d: %v", len(test.expected), len(res), res)
+			continue
+		}
+		for i, e := range test.expected {
+			if res[i] != e {
+				t.Errorf("Expected \"%s\", got \"%s\"", e, res[i])
+			}
+		}
+	}
 }
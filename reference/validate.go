@@ -0,0 +1,222 @@
+package reference
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Values of the oneof rule, optionally single quoted
+var oneofRx = regexp.MustCompile("'[^']*'|\\S+")
+
+// Formats of the validation rules
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"http_url": "uri",
+	"uuid":     "uuid",
+	"uuid3":    "uuid",
+	"uuid4":    "uuid",
+	"uuid5":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"fqdn":     "hostname",
+	"base64":   "byte",
+}
+
+// Patterns of the validation rules
+var validatePatterns = map[string]string{
+	"alpha":       "^[a-zA-Z]+$",
+	"alphanum":    "^[a-zA-Z0-9]+$",
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":      "^[0-9]+$",
+	"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+	"lowercase":   "^[^A-Z]*$",
+	"uppercase":   "^[^a-z]*$",
+	"e164":        "^\\+[1-9]?[0-9]{7,14}$",
+}
+
+// validateAttrs translates the validation rules of the go-playground
+// validator, i.e. the rules of the validate and binding tags, into the
+// field attributes by the field type. E.g. min=1 of a string is the
+// minLength, of an array the minItems, of a number the minimum.
+// It returns true if the field is required.
+func validateAttrs(rules, t string, attrs map[string]string) bool {
+	required := false
+	kind := validateKind(t)
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		// Rules of the array items, or map keys, are not supported
+		if rule == "dive" || rule == "keys" {
+			break
+		}
+		// Alternative rules are not supported
+		if strings.Contains(rule, "|") {
+			continue
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i != -1 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "min", "gte":
+			validateBound(kind, "min", param, false, attrs)
+		case "max", "lte":
+			validateBound(kind, "max", param, false, attrs)
+		case "gt":
+			validateBound(kind, "min", param, true, attrs)
+		case "lt":
+			validateBound(kind, "max", param, true, attrs)
+		case "len":
+			validateBound(kind, "min", param, false, attrs)
+			validateBound(kind, "max", param, false, attrs)
+		case "oneof":
+			if values := validateEnum(kind, param); values != "" {
+				attrs["enum"] = values
+			}
+		case "datetime":
+			attrs["format"] = "date-time"
+			if param == "2006-01-02" {
+				attrs["format"] = "date"
+			}
+		case "startswith":
+			attrs["pattern"] = "^" + regexp.QuoteMeta(param)
+		case "endswith":
+			attrs["pattern"] = regexp.QuoteMeta(param) + "$"
+		case "contains":
+			attrs["pattern"] = regexp.QuoteMeta(param)
+		default:
+			if f, ok := validateFormats[name]; ok {
+				attrs["format"] = f
+			} else if p, ok := validatePatterns[name]; ok {
+				attrs["pattern"] = p
+			}
+		}
+	}
+	return required
+}
+
+// validateKind of the field type, i.e. array,
+// map, string, number or an empty string for
+// the types without the validation keywords
+func validateKind(t string) string {
+	switch {
+	case strings.HasPrefix(t, "[]"):
+		return "array"
+	case strings.HasPrefix(t, "map["):
+		return "map"
+	case t == "string":
+		return "string"
+	case t == "integer" || t == "number" || t == "byte" || t == "rune",
+		strings.HasPrefix(t, "int"), strings.HasPrefix(t, "uint"), strings.HasPrefix(t, "float"):
+		return "number"
+	}
+	return ""
+}
+
+// validateBound sets the min/max bound attribute of the
+// field kind. Exclusive bounds of the countable kinds
+// are converted into the inclusive ones.
+func validateBound(kind, bound, param string, exclusive bool, attrs map[string]string) {
+	if kind == "number" {
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return
+		}
+		key := bound + "imum"
+		attrs[key] = param
+		if exclusive {
+			attrs["exclusive"+strings.ToUpper(key[:1])+key[1:]] = "true"
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	if exclusive && bound == "min" {
+		n++
+	} else if exclusive {
+		n--
+	}
+	suffix := map[string]string{
+		"string": "Length",
+		"array":  "Items",
+		"map":    "Properties",
+	}[kind]
+	if suffix == "" {
+		return
+	}
+	attrs[bound+suffix] = strconv.Itoa(n)
+}
+
+// validateEnum formats the space separated oneof values,
+// optionally single quoted, into the enum values list
+func validateEnum(kind, param string) string {
+	values := oneofRx.FindAllString(param, -1)
+	for i, v := range values {
+		values[i] = strings.Trim(v, "'")
+	}
+	return enumValues(kind, values)
+}
+
+// enumValues formatted by the field kind into the enum values
+// list, i.e. strings are quoted. Empty if a value is invalid.
+func enumValues(kind string, values []string) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		switch kind {
+		case "number":
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return ""
+			}
+		case "boolean":
+			if _, err := strconv.ParseBool(v); err != nil {
+				return ""
+			}
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return ""
+			}
+			v = string(b)
+		}
+		formatted = append(formatted, v)
+	}
+	if len(formatted) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(formatted, ",") + "]"
+}
+
+// stringAttrs of the number, or of the boolean, encoded as
+// the string, i.e. the format and the numeric constraints
+// are dropped, the enum values are quoted
+func stringAttrs(attrs map[string]string) {
+	for _, key := range []string{"format", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf"} {
+		delete(attrs, key)
+	}
+	enum, ok := attrs["enum"]
+	if ok == false {
+		return
+	}
+	delete(attrs, "enum")
+	raw := make([]json.RawMessage, 0)
+	if err := json.Unmarshal([]byte(enum), &raw); err != nil {
+		return
+	}
+	values := make([]string, 0, len(raw))
+	for _, v := range raw {
+		values = append(values, string(v))
+	}
+	if enum = enumValues("string", values); enum != "" {
+		attrs["enum"] = enum
+	}
+}
@@ -0,0 +1,89 @@
+package reference
+
+import (
+	"testing"
+)
+
+func TestValidateAttrs(t *testing.T) {
+	table := []struct {
+		rules    string
+		t        string
+		required bool
+		expected map[string]string
+	}{
+		{
+			rules:    "required,min=1,max=64,email",
+			t:        "string",
+			required: true,
+			expected: map[string]string{"minLength": "1", "maxLength": "64", "format": "email"},
+		},
+		{
+			rules:    "omitempty,gte=18,lt=150",
+			t:        "int",
+			expected: map[string]string{"minimum": "18", "maximum": "150", "exclusiveMaximum": "true"},
+		},
+		{
+			rules:    "gt=0,lt=10",
+			t:        "string",
+			expected: map[string]string{"minLength": "1", "maxLength": "9"},
+		},
+		{
+			rules:    "len=3",
+			t:        "[]string",
+			expected: map[string]string{"minItems": "3", "maxItems": "3"},
+		},
+		{
+			rules:    "min=1,dive,max=5",
+			t:        "map[string]int",
+			expected: map[string]string{"minProperties": "1"},
+		},
+		{
+			rules:    "oneof=admin 'power user' guest",
+			t:        "string",
+			expected: map[string]string{"enum": "[\"admin\",\"power user\",\"guest\"]"},
+		},
+		{
+			rules:    "oneof=1 2 3",
+			t:        "uint8",
+			expected: map[string]string{"enum": "[1,2,3]"},
+		},
+		{
+			rules:    "required,uuid4",
+			t:        "string",
+			required: true,
+			expected: map[string]string{"format": "uuid"},
+		},
+		{
+			rules:    "alphanum,startswith=a.b",
+			t:        "string",
+			expected: map[string]string{"pattern": "^a\\.b"},
+		},
+		{
+			rules:    "datetime=2006-01-02",
+			t:        "string",
+			expected: map[string]string{"format": "date"},
+		},
+		{
+			rules:    "min=1,email|url",
+			t:        "object",
+			expected: map[string]string{},
+		},
+	}
+
+	for _, test := range table {
+		attrs := make(map[string]string, 0)
+		required := validateAttrs(test.rules, test.t, attrs)
+		if required != test.required {
+			t.Errorf("Expected %v, got %v", test.required, required)
+		}
+		if len(attrs) != len(test.expected) {
+			t.Errorf("Expected %v, got %v", test.expected, attrs)
+			continue
+		}
+		for k, v := range test.expected {
+			if attrs[k] != v {
+				t.Errorf("Expected \"%s\", got \"%s\"", v, attrs[k])
+			}
+		}
+	}
+}
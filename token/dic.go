@@ -1,11 +1,23 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Map type signature, i.e. {map[key]value} or {[]map[key]value}
+var mapTypeRx = regexp.MustCompile("^{(\\[\\])?map\\[[^\\]]*\\](.+)}$")
 
 type dic struct {
 	mapping map[int]string
-	before  func([]string) []string
-	after   func(map[string]string) map[string]string
+	// Struct tag like sections, i.e. `key:"value"`,
+	// are mapped into meta by the key, regardless
+	// of the position
+	tags   bool
+	before func([]string) []string
+	after  func(map[string]string) map[string]string
 }
 
 // Map given list of entries into Token meta collection
@@ -17,8 +29,16 @@ func (d *dic) Map(entries []string) map[string]string {
 	}
 	meta := map[string]string{}
 	last := ""
-	for i, e := range entries {
+	i := 0
+	for _, e := range entries {
+		if d.tags && strings.HasPrefix(e, "`") {
+			for k, v := range parseTag(strings.Trim(e, "`")) {
+				meta[k] = v
+			}
+			continue
+		}
 		key, ok := d.mapping[i]
+		i++
 		if ok {
 			meta[key] = e
 			last = key
@@ -32,3 +52,48 @@ func (d *dic) Map(entries []string) map[string]string {
 	}
 	return meta
 }
+
+// parseTag into key/value pairs. The expected format
+// is the same as GO struct tags: key:"value" key2:"value2"
+func parseTag(tag string) map[string]string {
+	pairs := make(map[string]string, 0)
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, ":\"")
+		if i < 1 {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// Find the closing quote
+		j := 1
+		for j < len(tag) && tag[j] != '"' {
+			if tag[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:j+1])
+		if err != nil {
+			break
+		}
+		pairs[key] = value
+		tag = tag[j+1:]
+	}
+	return pairs
+}
+
+// splitMapType of the map type meta, i.e. {map[string]value},
+// into the map type {map} and the value type {value}.
+// Arrays of maps are split into {[]map} and {value}.
+func splitMapType(meta map[string]string) map[string]string {
+	if c := mapTypeRx.FindStringSubmatch(meta["type"]); len(c) == 3 {
+		meta["type"] = fmt.Sprintf("{%smap}", c[1])
+		meta["value"] = fmt.Sprintf("{%s}", c[2])
+	}
+	return meta
+}
@@ -141,6 +141,7 @@ func NewParser(verbose bool) Parser {
 			"success":  ReqResp,
 			"failure":  ReqResp,
 			"router":   Router,
+			"webhook":  Router,
 
 			// Subrouter
 			"routerurl": Value,
@@ -160,6 +161,7 @@ func NewParser(verbose bool) Parser {
 					3: "req",
 					4: "desc",
 				},
+				after: splitMapType,
 			},
 			Server: {
 				mapping: map[int]string{
@@ -189,6 +191,7 @@ func NewParser(verbose bool) Parser {
 				},
 			},
 			Ref: {
+				tags: true,
 				mapping: map[int]string{
 					0: "pkg.type",
 					1: "key",
@@ -196,8 +199,10 @@ func NewParser(verbose bool) Parser {
 					3: "req",
 					4: "desc",
 				},
+				after: splitMapType,
 			},
 			Wrap: {
+				tags: true,
 				mapping: map[int]string{
 					0: "pkg.type",
 					1: "key",
@@ -206,8 +211,9 @@ func NewParser(verbose bool) Parser {
 					4: "ptr",
 					5: "desc",
 				},
+				after: splitMapType,
 			},
 		},
-		tokenSectionsRx: regexp.MustCompile("\"[^\"]*\"|[^\\s]+"),
+		tokenSectionsRx: regexp.MustCompile("`[^`]*`|\"[^\"]*\"|[^\\s]+"),
 	}
 }
@@ -253,4 +253,78 @@ func TestMap(t *testing.T) {
 		t.Errorf("Has \"%s\", expected \"%s\"", m["value"], expected)
 		return
 	}
+
+	// Tags
+	d = dic{
+		tags: true,
+		mapping: map[int]string{
+			0: "key",
+			1: "value",
+		},
+	}
+	m = d.Map([]string{"tag", "`enum:\"[\\\"a\\\",\\\"b c\\\"]\" format:\"int32\"`", "path"})
+	if len(m) != 4 {
+		t.Errorf("Has %d keys, expected %d keys", len(m), 4)
+		return
+	}
+	if m["value"] != "path" {
+		t.Errorf("Has \"%s\", expected \"%s\"", m["value"], "path")
+	}
+	if m["enum"] != "[\"a\",\"b c\"]" {
+		t.Errorf("Has \"%s\", expected \"%s\"", m["enum"], "[\"a\",\"b c\"]")
+	}
+	if m["format"] != "int32" {
+		t.Errorf("Has \"%s\", expected \"%s\"", m["format"], "int32")
+	}
+}
+
+// Ref token with the tag section
+func TestTokenizeTags(t *testing.T) {
+	p := NewParser(false).(*parser)
+	token, err := p.Tokenize("sref pkg.Person status {int} false `enum:\"[0,1,2]\"` \"Person status\"")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+	expected := map[string]string{
+		"pkg.type": "pkg.Person",
+		"key":      "status",
+		"type":     "{int}",
+		"req":      "false",
+		"enum":     "[0,1,2]",
+		"desc":     "\"Person status\"",
+	}
+	for k, v := range expected {
+		if token.Meta[k] != v {
+			t.Errorf("Has \"%s\", expected \"%s\" for \"%s\"", token.Meta[k], v, k)
+		}
+	}
+}
+
+func TestTokenizeMap(t *testing.T) {
+	p := NewParser(false).(*parser)
+	tests := []struct {
+		line  string
+		t     string
+		value string
+	}{
+		{"bref pkg.Order labels {map[string]string} false", "{map}", "{string}"},
+		{"bref pkg.Order items {map[string][]pkg.Item} false", "{map}", "{[]pkg.Item}"},
+		{"bref pkg.Order many {[]map[string]int} false", "{[]map}", "{int}"},
+		{"swrapref pkg.Wrap data {map[string]object} false true", "{map}", "{object}"},
+		{"bref pkg.Order name {string} false", "{string}", ""},
+	}
+	for _, c := range tests {
+		token, err := p.Tokenize(c.line)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if token.Meta["type"] != c.t {
+			t.Errorf("Has \"%s\", expected \"%s\"", token.Meta["type"], c.t)
+		}
+		if token.Meta["value"] != c.value {
+			t.Errorf("Has \"%s\", expected \"%s\"", token.Meta["value"], c.value)
+		}
+	}
 }